@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"github.com/yrn-go/yrn/internal/api"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	EnvApiKeysFile      = "API_KEYS_FILE"
+	EnvJwtJwksFile      = "JWT_JWKS_FILE"
+	EnvJwtIssuer        = "JWT_ISSUER"
+	EnvJwtAudience      = "JWT_AUDIENCE"
+	EnvJwtTenantClaim   = "JWT_TENANT_CLAIM"
+	EnvJwtRolesClaim    = "JWT_ROLES_CLAIM"
+	EnvAuthTrustHeaders = "AUTH_TRUST_HEADERS"
+)
+
+// newAuthenticator chains the authenticators configured in the environment:
+// the API keys of API_KEYS_FILE, the JWTs signed by the keys of JWT_JWKS_FILE
+// or, with AUTH_TRUST_HEADERS=true, only the identity headers set by a
+// gateway. The headers can not be combined with the other authenticators,
+// whose requests would fall back to headers sent by any client. The API does
+// not start without one of them.
+func newAuthenticator() api.Authenticator {
+	var authenticators api.Authenticators
+
+	if path := os.Getenv(EnvApiKeysFile); path != "" {
+		keys, err := api.LoadApiKeys(path)
+		if err != nil {
+			panic(err)
+		}
+
+		authenticator, err := api.NewApiKeyAuthenticator(keys)
+		if err != nil {
+			panic(err)
+		}
+
+		authenticators = append(authenticators, authenticator)
+	}
+
+	if path := os.Getenv(EnvJwtJwksFile); path != "" {
+		jwks, err := api.LoadJWKS(path)
+		if err != nil {
+			panic(err)
+		}
+
+		authenticator, err := api.NewJWTAuthenticator(api.JWTConfig{
+			JWKS:        jwks,
+			Issuer:      os.Getenv(EnvJwtIssuer),
+			Audience:    os.Getenv(EnvJwtAudience),
+			TenantClaim: os.Getenv(EnvJwtTenantClaim),
+			RolesClaim:  os.Getenv(EnvJwtRolesClaim),
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		authenticators = append(authenticators, authenticator)
+	}
+
+	if trust, _ := strconv.ParseBool(os.Getenv(EnvAuthTrustHeaders)); trust {
+		if len(authenticators) > 0 {
+			panic(errors.New(EnvAuthTrustHeaders + " can not be combined with " + EnvApiKeysFile + " or " + EnvJwtJwksFile))
+		}
+
+		slog.Warn("trusting the identity headers, the API must be behind an authenticating gateway")
+
+		return api.NewHeaderAuthenticator()
+	}
+
+	if len(authenticators) == 0 {
+		panic(errors.New("no authentication configured: set " + EnvApiKeysFile + ", " + EnvJwtJwksFile + " or " + EnvAuthTrustHeaders))
+	}
+
+	return authenticators
+}
@@ -1,21 +1,219 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata" // fusos horários dos agendamentos na imagem scratch
+
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/yrn-go/yrn/internal/api"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/module/scheduler"
+	"github.com/yrn-go/yrn/module/secretmanager"
+	"github.com/yrn-go/yrn/module/trigger"
+	"github.com/yrn-go/yrn/module/webhook"
+	"github.com/yrn-go/yrn/pkg/pluginmapper"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"github.com/yrn-go/yrn/pkg/ylog"
+	"github.com/yrn-go/yrn/pkg/yredact"
 	"golang.org/x/exp/slog"
-	"net/http"
+)
+
+const (
+	EnvRedisUrl = "REDIS_URL"
+	EnvPort     = "PORT"
+
+	defaultPort     = "8080"
+	pluginStatusTTL = 24 * time.Hour
+	shutdownTimeout = 30 * time.Second
 )
 
 func main() {
+	slog.SetDefault(slog.New(ylog.NewRedactingHandler(slog.NewTextHandler(os.Stderr, nil), yredact.NewRedactorFromEnv())))
+
 	slog.Info("start api")
 
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// the system context: it reloads the schedules and queues of every tenant.
+	ctx := yctx.NewContext(signalCtx).WithAllTenants()
+	authenticator := newAuthenticator()
+	redisClient := newRedisClient()
+	store := newStores(ctx, redisClient)
+	flowRepository := store.flow
+	pluginManager, err := pluginmapper.NewPluginManagerLocal()
+	if err != nil {
+		panic(err)
+	}
+
+	flowValidator := flowmanager.NewFlowValidator(pluginManager)
+	flowVersioner := flowmanager.NewFlowVersioner(flowRepository, flowRepository, store.flowVersion, flowValidator)
+	flowCreator := flowmanager.NewFlowCreator(flowRepository, flowVersioner, flowValidator)
+	flowDeleter := flowmanager.NewFlowDeleter(flowRepository, flowRepository)
+	flowSearcher := flowmanager.NewFlowSearcher(flowRepository, flowRepository)
+	secretService := newSecretService(store.secret)
+
+	var (
+		secretResolver        flowmanager.SecretResolver
+		webhookSecretResolver webhook.SecretResolver
+	)
+	if secretService != nil {
+		secretResolver = secretService
+		webhookSecretResolver = secretService
+	}
+
+	flowExecutor := flowmanager.NewFlowExecutor(
+		flowRepository,
+		store.flowVersion,
+		pluginManager,
+		store.pluginStatus,
+		secretResolver,
+	)
+	flowScheduler := scheduler.NewScheduler(store.schedule, flowExecutor, newSchedulerLocker(redisClient))
+	webhookService := webhook.NewWebhookService(store.webhook, flowExecutor, webhookSecretResolver)
+	triggerListeners := newTriggerListeners(flowScheduler, webhookService, redisClient, flowExecutor)
+	flowDeployer := flowmanager.NewFlowDeployer(
+		flowRepository,
+		flowRepository,
+		store.deployAudit,
+		trigger.NewDispatcher(triggerListeners),
+		flowVersioner,
+	)
+
+	go flowScheduler.Run(ctx)
+
+	if queueListener, ok := triggerListeners[flowmanager.TriggerTypeQueue].(*trigger.QueueListener); ok {
+		go queueListener.Run(ctx)
+	}
+
 	engine := gin.Default()
+	engine.Use(api.Authorize(authenticator, api.NewPolicy()))
+
+	api.NewHealthHandler(store.health).Register(engine)
+	api.NewFlowHandler(flowCreator, flowValidator, flowExecutor).Register(engine)
+	api.NewScheduleHandler(flowScheduler).Register(engine)
+	api.NewWebhookHandler(webhookService).Register(engine)
+	api.NewFlowVersionHandler(flowVersioner).Register(engine)
+	api.NewFlowDeleteHandler(flowDeleter).Register(engine)
+	api.NewFlowSearchHandler(flowSearcher).Register(engine)
+	api.NewDeployHandler(flowDeployer).Register(engine)
+
+	if secretService != nil {
+		api.NewSecretHandler(secretService).Register(engine)
+	}
+
+	serve(ctx, engine, store)
+}
+
+// serve runs the API until SIGINT or SIGTERM, then drains the open requests
+// and closes the database.
+func serve(ctx *yctx.Context, handler http.Handler, store *stores) {
+	port := os.Getenv(EnvPort)
+	if port == "" {
+		port = defaultPort
+	}
+
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: handler,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			panic(err)
+		}
+	}()
+
+	<-ctx.Context().Done()
+
+	slog.Info("shutdown api")
 
-	engine.GET("/health", func(c *gin.Context) {
-		c.Status(http.StatusOK)
-	})
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
 
-	if err := engine.Run(); err != nil {
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("shutdown server", slog.Any("error", err))
+	}
+
+	if err := store.close(yctx.NewContext(shutdownCtx)); err != nil {
+		slog.Error("close store", slog.Any("error", err))
+	}
+}
+
+// newRedisClient returns nil when REDIS_URL is not set.
+func newRedisClient() *redis.Client {
+	redisUrl := os.Getenv(EnvRedisUrl)
+	if redisUrl == "" {
+		return nil
+	}
+
+	options, err := redis.ParseURL(redisUrl)
+	if err != nil {
 		panic(err)
 	}
+
+	return redis.NewClient(options)
+}
+
+// newPluginStatusRepository uses Redis when it is configured and keeps the
+// statuses in memory otherwise.
+func newPluginStatusRepository(redisClient *redis.Client) flowmanager.PluginStatusRepository {
+	if redisClient == nil {
+		return flowmanager.NewInMemoryPluginStatusRepository()
+	}
+
+	return flowmanager.NewRedisPluginStatusRepository(redisClient, pluginStatusTTL)
+}
+
+// newSchedulerLocker uses Redis when it is configured, so schedules fire once
+// across replicas, and an in memory lock for a single replica otherwise.
+func newSchedulerLocker(redisClient *redis.Client) scheduler.Locker {
+	if redisClient == nil {
+		return scheduler.NewInMemoryLocker()
+	}
+
+	return scheduler.NewRedisLocker(redisClient)
+}
+
+// newTriggerListeners returns the listener of each trigger type. Queue
+// triggers need Redis and are not available without it.
+func newTriggerListeners(
+	flowScheduler *scheduler.Scheduler,
+	webhookService *webhook.WebhookService,
+	redisClient *redis.Client,
+	flowRunner trigger.FlowRunner,
+) map[flowmanager.TriggerType]trigger.Listener {
+	listeners := map[flowmanager.TriggerType]trigger.Listener{
+		flowmanager.TriggerTypeSchedule: trigger.NewScheduleListener(flowScheduler),
+		flowmanager.TriggerTypeWebhook:  trigger.NewWebhookListener(webhookService),
+	}
+
+	if redisClient != nil {
+		listeners[flowmanager.TriggerTypeQueue] = trigger.NewQueueListener(redisClient, flowRunner)
+	}
+
+	return listeners
+}
+
+// newSecretService returns nil when SECRETS_KEY is not set, which disables
+// the secrets API and the {{ secret "name" }} references.
+func newSecretService(secretRepository secretmanager.SecretRepository) *secretmanager.SecretService {
+	cipher, err := secretmanager.NewCipherFromEnv()
+	if errors.Is(err, secretmanager.ErrSecretsKeyMissing) {
+		slog.Warn("secrets are disabled", slog.Any("error", err))
+		return nil
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	return secretmanager.NewSecretService(secretRepository, cipher)
 }
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yrn-go/yrn/internal/api"
+	"github.com/yrn-go/yrn/internal/database/boltdb"
+	"github.com/yrn-go/yrn/internal/database/mongodb"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/module/scheduler"
+	"github.com/yrn-go/yrn/module/secretmanager"
+	"github.com/yrn-go/yrn/module/webhook"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	EnvStore = "STORE"
+
+	StoreMongodb = "mongodb"
+	StoreBolt    = "bolt"
+)
+
+type (
+	flowRepository interface {
+		flowmanager.FlowReaderRepository
+		flowmanager.FlowWriteRepository
+		flowmanager.FlowDeployRepository
+		flowmanager.FlowSearchRepository
+	}
+
+	// stores are the repositories of the API, all kept in the database
+	// selected by STORE.
+	stores struct {
+		flow         flowRepository
+		flowVersion  flowmanager.FlowVersionRepository
+		deployAudit  flowmanager.DeployAuditRepository
+		pluginStatus flowmanager.PluginStatusRepository
+		schedule     scheduler.ScheduleRepository
+		webhook      webhook.WebhookRepository
+		secret       secretmanager.SecretRepository
+
+		// health pings the database for GET /health and close releases it
+		// on shutdown.
+		health api.HealthChecker
+		close  func(ctx *yctx.Context) error
+	}
+)
+
+// newStores uses MongoDB by default. With STORE=bolt every repository is kept
+// in the file of BOLT_PATH, so the API runs without external databases.
+func newStores(ctx *yctx.Context, redisClient *redis.Client) *stores {
+	switch store := os.Getenv(EnvStore); store {
+	case "", StoreMongodb:
+		client, err := mongodb.NewClient(ctx)
+		if err != nil {
+			panic(err)
+		}
+
+		flowRepository := mongodb.NewFlowRepository(client)
+		if err = flowRepository.MigrateLegacyFlows(ctx); err != nil {
+			panic(err)
+		}
+
+		if err = flowRepository.CreateIndexes(ctx); err != nil {
+			panic(err)
+		}
+
+		return &stores{
+			flow:         flowRepository,
+			flowVersion:  mongodb.NewFlowVersionRepository(client),
+			deployAudit:  mongodb.NewDeployAuditRepository(client),
+			pluginStatus: newPluginStatusRepository(redisClient),
+			schedule:     mongodb.NewScheduleRepository(client),
+			webhook:      mongodb.NewWebhookRepository(client),
+			secret:       mongodb.NewSecretRepository(client),
+			health:       client,
+			close:        client.Disconnect,
+		}
+	case StoreBolt:
+		path := os.Getenv(boltdb.EnvBoltPath)
+		if path == "" {
+			path = boltdb.DefaultPath
+		}
+
+		boltStore, err := boltdb.Open(path)
+		if err != nil {
+			panic(err)
+		}
+
+		slog.Info("using bolt store", slog.String("path", path))
+
+		return &stores{
+			flow:         boltdb.NewFlowRepository(boltStore),
+			flowVersion:  boltdb.NewFlowVersionRepository(boltStore),
+			deployAudit:  boltdb.NewDeployAuditRepository(boltStore),
+			pluginStatus: boltdb.NewPluginStatusRepository(boltStore),
+			schedule:     boltdb.NewScheduleRepository(boltStore),
+			webhook:      boltdb.NewWebhookRepository(boltStore),
+			secret:       boltdb.NewSecretRepository(boltStore),
+			health:       boltStore,
+			close: func(*yctx.Context) error {
+				return boltStore.Close()
+			},
+		}
+	default:
+		panic("invalid " + EnvStore + ": " + store)
+	}
+}
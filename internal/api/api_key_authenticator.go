@@ -0,0 +1,102 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+// HeaderApiKey has the static API key of the caller.
+const HeaderApiKey = "X-Api-Key"
+
+type (
+	// ApiKey is a static credential. Only the SHA-256 of the key is kept, so
+	// the file with the keys does not leak them. Every key has a tenant, and
+	// only the admin keys may have yctx.AllTenants.
+	ApiKey struct {
+		Id        string   `json:"id"`
+		Tenant    string   `json:"tenant"`
+		Roles     []string `json:"roles"`
+		KeySHA256 string   `json:"key_sha256"`
+	}
+
+	ApiKeyAuthenticator struct {
+		keys []apiKeyHash
+	}
+
+	apiKeyHash struct {
+		caller yctx.Caller
+		hash   []byte
+	}
+)
+
+// LoadApiKeys reads a JSON array of ApiKey from path.
+func LoadApiKeys(path string) ([]ApiKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []ApiKey
+	if err = json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("invalid api keys file %s: %w", path, err)
+	}
+
+	return keys, nil
+}
+
+func NewApiKeyAuthenticator(keys []ApiKey) (*ApiKeyAuthenticator, error) {
+	authenticator := &ApiKeyAuthenticator{}
+
+	for i, key := range keys {
+		if key.Id == "" {
+			return nil, fmt.Errorf("api key %d: id is required", i)
+		}
+
+		if key.Tenant == "" {
+			return nil, fmt.Errorf("api key %s: tenant is required", key.Id)
+		}
+
+		if key.Tenant == yctx.AllTenants && !slices.Contains(key.Roles, string(RoleAdmin)) {
+			return nil, fmt.Errorf("api key %s: only admin keys have the tenant %q", key.Id, yctx.AllTenants)
+		}
+
+		hash, err := hex.DecodeString(key.KeySHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("api key %s: key_sha256 must be a hex SHA-256", key.Id)
+		}
+
+		authenticator.keys = append(authenticator.keys, apiKeyHash{
+			caller: yctx.Caller{Id: key.Id, Tenant: key.Tenant, Roles: key.Roles},
+			hash:   hash,
+		})
+	}
+
+	return authenticator, nil
+}
+
+// Authenticate returns nil when the request has no X-Api-Key header.
+func (a *ApiKeyAuthenticator) Authenticate(request *http.Request) (*yctx.Caller, error) {
+	key := request.Header.Get(HeaderApiKey)
+	if key == "" {
+		return nil, nil
+	}
+
+	hash := sha256.Sum256([]byte(key))
+
+	for _, apiKey := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], apiKey.hash) == 1 {
+			caller := apiKey.caller
+			return &caller, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: invalid api key", ErrUnauthenticated)
+}
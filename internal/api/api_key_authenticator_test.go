@@ -0,0 +1,79 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestApiKeyAuthenticator(t *testing.T) {
+	suite.Run(t, new(ApiKeyAuthenticatorTestSuite))
+}
+
+type ApiKeyAuthenticatorTestSuite struct {
+	suite.Suite
+	authenticator *ApiKeyAuthenticator
+}
+
+func (s *ApiKeyAuthenticatorTestSuite) SetupTest() {
+	hash := sha256.Sum256([]byte("ci-key"))
+
+	path := filepath.Join(s.T().TempDir(), "api-keys.json")
+	s.Require().NoError(os.WriteFile(path, []byte(`[
+		{"id": "ci", "tenant": "tenant-a", "roles": ["operator"], "key_sha256": "`+hex.EncodeToString(hash[:])+`"}
+	]`), 0o600))
+
+	keys, err := LoadApiKeys(path)
+	s.Require().NoError(err)
+
+	s.authenticator, err = NewApiKeyAuthenticator(keys)
+	s.Require().NoError(err)
+}
+
+func (s *ApiKeyAuthenticatorTestSuite) authenticate(key string) (*yctx.Caller, error) {
+	request := httptest.NewRequest(http.MethodGet, "/flows", nil)
+	if key != "" {
+		request.Header.Set(HeaderApiKey, key)
+	}
+
+	return s.authenticator.Authenticate(request)
+}
+
+func (s *ApiKeyAuthenticatorTestSuite) TestAuthenticate() {
+	caller, err := s.authenticate("ci-key")
+	s.NoError(err)
+	s.Equal(&yctx.Caller{Id: "ci", Tenant: "tenant-a", Roles: []string{"operator"}}, caller)
+
+	caller, err = s.authenticate("")
+	s.NoError(err)
+	s.Nil(caller)
+
+	_, err = s.authenticate("other-key")
+	s.ErrorIs(err, ErrUnauthenticated)
+}
+
+func (s *ApiKeyAuthenticatorTestSuite) TestNewApiKeyAuthenticator_ValidatesKeys() {
+	_, err := NewApiKeyAuthenticator([]ApiKey{{Id: "ci", Tenant: "tenant-a", KeySHA256: "not-hex"}})
+	s.Error(err)
+
+	hash := hex.EncodeToString(make([]byte, sha256.Size))
+
+	_, err = NewApiKeyAuthenticator([]ApiKey{{KeySHA256: hash, Tenant: "tenant-a"}})
+	s.Error(err)
+
+	_, err = NewApiKeyAuthenticator([]ApiKey{{Id: "ci", KeySHA256: hash}})
+	s.Error(err)
+
+	_, err = NewApiKeyAuthenticator([]ApiKey{{Id: "ci", Tenant: yctx.AllTenants, Roles: []string{"operator"}, KeySHA256: hash}})
+	s.Error(err)
+
+	_, err = NewApiKeyAuthenticator([]ApiKey{{Id: "root", Tenant: yctx.AllTenants, Roles: []string{"admin"}, KeySHA256: hash}})
+	s.NoError(err)
+}
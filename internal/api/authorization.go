@@ -0,0 +1,165 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+type (
+	Role string
+
+	Permission string
+
+	// Policy maps each route, as "METHOD /path" with the gin path, to the
+	// permission it requires. Routes registered with Any use the method ANY.
+	Policy map[string]Permission
+)
+
+const (
+	RoleViewer   Role = "viewer"
+	RoleEditor   Role = "editor"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+
+	// PermissionPublic marks the routes that do not need a caller, such as
+	// the health check and the webhooks, which verify their own signature.
+	PermissionPublic      Permission = "public"
+	PermissionFlowRead    Permission = "flow:read"
+	PermissionFlowWrite   Permission = "flow:write"
+	PermissionFlowDeploy  Permission = "flow:deploy"
+	PermissionFlowExecute Permission = "flow:execute"
+	// PermissionFlowTest runs flows that are not deployed.
+	PermissionFlowTest    Permission = "flow:test"
+	PermissionSecretRead  Permission = "secret:read"
+	PermissionSecretWrite Permission = "secret:write"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleViewer:   {PermissionFlowRead},
+	RoleEditor:   {PermissionFlowRead, PermissionFlowWrite, PermissionFlowTest, PermissionSecretRead},
+	RoleOperator: {PermissionFlowRead, PermissionFlowDeploy, PermissionFlowExecute, PermissionSecretRead},
+	RoleAdmin: {
+		PermissionFlowRead, PermissionFlowWrite, PermissionFlowDeploy, PermissionFlowExecute, PermissionFlowTest,
+		PermissionSecretRead, PermissionSecretWrite,
+	},
+}
+
+// NewPolicy returns the permissions of the routes of the handlers of this
+// package.
+func NewPolicy() Policy {
+	return Policy{
+		"GET /health":           PermissionPublic,
+		"ANY /hooks/:webhookId": PermissionPublic,
+
+		"GET /flows":                       PermissionFlowRead,
+		"GET /flows/deleted":               PermissionFlowRead,
+		"GET /flows/:id/versions":          PermissionFlowRead,
+		"GET /flows/:id/versions/:version": PermissionFlowRead,
+		"GET /flows/:id/diff":              PermissionFlowRead,
+		"GET /flows/:id/deployments":       PermissionFlowRead,
+		"GET /flows/:id/schedules":         PermissionFlowRead,
+		"GET /flows/:id/webhooks":          PermissionFlowRead,
+		"GET /schedules/:scheduleId":       PermissionFlowRead,
+
+		"POST /flows":                                PermissionFlowWrite,
+		"POST /flows/validate":                       PermissionFlowWrite,
+		"PUT /flows/:id":                             PermissionFlowWrite,
+		"DELETE /flows/:id":                          PermissionFlowWrite,
+		"POST /flows/:id/restore":                    PermissionFlowWrite,
+		"POST /flows/:id/versions/:version/rollback": PermissionFlowWrite,
+		"POST /flows/:id/schedules":                  PermissionFlowWrite,
+		"DELETE /schedules/:scheduleId":              PermissionFlowWrite,
+		"POST /flows/:id/webhooks":                   PermissionFlowWrite,
+		"DELETE /webhooks/:webhookId":                PermissionFlowWrite,
+
+		"POST /flows/:id/deploy":   PermissionFlowDeploy,
+		"POST /flows/:id/undeploy": PermissionFlowDeploy,
+		"POST /flows/:id/rollback": PermissionFlowDeploy,
+
+		"POST /flows/:id/execute": PermissionFlowExecute,
+		"POST /flows/:id/test":    PermissionFlowTest,
+
+		"GET /secrets":          PermissionSecretRead,
+		"GET /secrets/:name":    PermissionSecretRead,
+		"PUT /secrets/:name":    PermissionSecretWrite,
+		"DELETE /secrets/:name": PermissionSecretWrite,
+	}
+}
+
+// Permission returns the permission of the route, falling back to the
+// routes registered for any method.
+func (p Policy) Permission(method, path string) (permission Permission, ok bool) {
+	if permission, ok = p[method+" "+path]; ok {
+		return
+	}
+
+	permission, ok = p["ANY "+path]
+
+	return
+}
+
+// HasPermission reports whether one of the roles of the caller grants
+// permission. Unknown roles grant nothing.
+func HasPermission(caller *yctx.Caller, permission Permission) bool {
+	if caller == nil {
+		return false
+	}
+
+	for _, role := range caller.Roles {
+		if slices.Contains(rolePermissions[Role(role)], permission) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authorize authenticates the caller of the request, adds it to the
+// context like Authenticate and checks its roles against the permission of
+// the route. Public routes are not authenticated. Requests without caller get
+// 401, and callers without the permission or without tenant get 403. Routes
+// missing from the policy are denied.
+func Authorize(authenticator Authenticator, policy Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// unknown paths are answered by gin with 404.
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		permission, ok := policy.Permission(c.Request.Method, c.FullPath())
+		if ok && permission == PermissionPublic {
+			c.Next()
+			return
+		}
+
+		caller, err := authenticator.Authenticate(c.Request)
+		if err != nil {
+			renderError(c, err)
+			return
+		}
+
+		if caller == nil {
+			renderError(c, ErrUnauthenticated)
+			return
+		}
+
+		if !ok || !HasPermission(caller, permission) {
+			renderError(c, fmt.Errorf("%w: %s %s needs %s", ErrForbidden, c.Request.Method, c.FullPath(), permission))
+			return
+		}
+
+		if err = withCaller(c, caller); err != nil {
+			renderError(c, err)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestAuthorization(t *testing.T) {
+	suite.Run(t, new(AuthorizationTestSuite))
+}
+
+type AuthorizationTestSuite struct {
+	suite.Suite
+	engine *gin.Engine
+	caller *yctx.Caller
+}
+
+func (s *AuthorizationTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	s.caller = nil
+	s.engine = gin.New()
+	s.engine.Use(Authorize(NewHeaderAuthenticator(), NewPolicy()))
+
+	handler := func(c *gin.Context) {
+		s.caller = yctx.NewContext(c.Request.Context()).Caller()
+		c.Status(http.StatusNoContent)
+	}
+
+	s.engine.GET("/health", handler)
+	s.engine.Any("/hooks/:webhookId", handler)
+	s.engine.GET("/flows", handler)
+	s.engine.POST("/flows", handler)
+	s.engine.POST("/flows/:id/deploy", handler)
+	s.engine.POST("/flows/:id/execute", handler)
+	s.engine.POST("/flows/:id/test", handler)
+	s.engine.PUT("/secrets/:name", handler)
+	s.engine.GET("/unlisted", handler)
+}
+
+func (s *AuthorizationTestSuite) request(method, path, roles string) int {
+	return s.requestTenant(method, path, "tenant-a", roles)
+}
+
+func (s *AuthorizationTestSuite) requestTenant(method, path, tenant, roles string) int {
+	request := httptest.NewRequest(method, path, nil)
+	if roles != "" {
+		request.Header.Set(HeaderUserId, "alice")
+		request.Header.Set(HeaderTenantId, tenant)
+		request.Header.Set(HeaderUserRoles, roles)
+	}
+
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, request)
+
+	return recorder.Code
+}
+
+func (s *AuthorizationTestSuite) TestPublicRoutes() {
+	s.Equal(http.StatusNoContent, s.request(http.MethodGet, "/health", ""))
+	s.Equal(http.StatusNoContent, s.request(http.MethodPost, "/hooks/hook-1", ""))
+	s.Nil(s.caller)
+}
+
+func (s *AuthorizationTestSuite) TestWithoutCaller() {
+	s.Equal(http.StatusUnauthorized, s.request(http.MethodGet, "/flows", ""))
+	s.Equal(http.StatusNotFound, s.request(http.MethodGet, "/missing", ""))
+}
+
+func (s *AuthorizationTestSuite) TestRoles() {
+	for _, test := range []struct {
+		role   Role
+		method string
+		path   string
+		status int
+	}{
+		{RoleViewer, http.MethodGet, "/flows", http.StatusNoContent},
+		{RoleViewer, http.MethodPost, "/flows", http.StatusForbidden},
+		{RoleEditor, http.MethodPost, "/flows", http.StatusNoContent},
+		{RoleEditor, http.MethodPost, "/flows/flow-1/deploy", http.StatusForbidden},
+		{RoleEditor, http.MethodPut, "/secrets/token", http.StatusForbidden},
+		{RoleEditor, http.MethodPost, "/flows/flow-1/execute", http.StatusForbidden},
+		{RoleEditor, http.MethodPost, "/flows/flow-1/test", http.StatusNoContent},
+		{RoleViewer, http.MethodPost, "/flows/flow-1/test", http.StatusForbidden},
+		{RoleOperator, http.MethodPost, "/flows/flow-1/test", http.StatusForbidden},
+		{RoleOperator, http.MethodPost, "/flows/flow-1/deploy", http.StatusNoContent},
+		{RoleOperator, http.MethodPost, "/flows/flow-1/execute", http.StatusNoContent},
+		{RoleOperator, http.MethodPost, "/flows", http.StatusForbidden},
+		{RoleAdmin, http.MethodPut, "/secrets/token", http.StatusNoContent},
+		{RoleAdmin, http.MethodGet, "/unlisted", http.StatusForbidden},
+		{"unknown", http.MethodGet, "/flows", http.StatusForbidden},
+	} {
+		s.Equal(test.status, s.request(test.method, test.path, string(test.role)), "%s %s %s", test.role, test.method, test.path)
+	}
+}
+
+func (s *AuthorizationTestSuite) TestAddsCallerToContext() {
+	s.Equal(http.StatusNoContent, s.request(http.MethodPost, "/flows", "viewer, editor"))
+	s.Equal(&yctx.Caller{Id: "alice", Tenant: "tenant-a", Roles: []string{"viewer", "editor"}}, s.caller)
+}
+
+func (s *AuthorizationTestSuite) TestCallerWithoutTenant() {
+	s.Equal(http.StatusForbidden, s.requestTenant(http.MethodGet, "/flows", "", "admin"))
+	s.Nil(s.caller)
+}
+
+func (s *AuthorizationTestSuite) TestAllTenantsIsForAdmins() {
+	s.Equal(http.StatusForbidden, s.requestTenant(http.MethodGet, "/flows", yctx.AllTenants, "viewer"))
+	s.Nil(s.caller)
+
+	s.Equal(http.StatusNoContent, s.requestTenant(http.MethodGet, "/flows", yctx.AllTenants, "admin"))
+	s.Equal(yctx.AllTenants, s.caller.Tenant)
+}
+
+func (s *AuthorizationTestSuite) TestPolicyCoversEveryRoute() {
+	engine := gin.New()
+
+	NewHealthHandler(nil).Register(engine)
+	NewFlowHandler(nil, nil, nil).Register(engine)
+	NewScheduleHandler(nil).Register(engine)
+	NewWebhookHandler(nil).Register(engine)
+	NewFlowVersionHandler(nil).Register(engine)
+	NewFlowDeleteHandler(nil).Register(engine)
+	NewFlowSearchHandler(nil).Register(engine)
+	NewDeployHandler(nil).Register(engine)
+	NewSecretHandler(nil).Register(engine)
+
+	policy := NewPolicy()
+	for _, route := range engine.Routes() {
+		_, ok := policy.Permission(route.Method, route.Path)
+		s.True(ok, "%s %s has no permission", route.Method, route.Path)
+	}
+
+	for key := range policy {
+		method, path, _ := strings.Cut(key, " ")
+		s.True(s.registered(engine, method, path), "%s is not a route", key)
+	}
+}
+
+func (s *AuthorizationTestSuite) registered(engine *gin.Engine, method, path string) bool {
+	for _, route := range engine.Routes() {
+		if route.Path == path && (route.Method == method || method == "ANY") {
+			return true
+		}
+	}
+
+	return false
+}
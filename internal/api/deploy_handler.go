@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+type DeployHandler struct {
+	flowDeployer *flowmanager.FlowDeployer
+}
+
+func NewDeployHandler(flowDeployer *flowmanager.FlowDeployer) *DeployHandler {
+	return &DeployHandler{
+		flowDeployer: flowDeployer,
+	}
+}
+
+func (h *DeployHandler) Register(router gin.IRouter) {
+	router.POST("/flows/:id/deploy", h.action(h.flowDeployer.Deploy))
+	router.POST("/flows/:id/undeploy", h.action(h.flowDeployer.Undeploy))
+	router.POST("/flows/:id/rollback", h.action(h.flowDeployer.Rollback))
+	router.GET("/flows/:id/deployments", h.history)
+}
+
+func (h *DeployHandler) action(run func(ctx *yctx.Context, flowId, actor string) (*flowmanager.FlowDeployment, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := yctx.NewContext(c.Request.Context())
+
+		// the actor of the audit is the authenticated caller, never a header
+		// of its own.
+		actor := callerId(ctx)
+		if actor == "" {
+			renderError(c, fmt.Errorf("%w: deploy actions need an identified caller", ErrUnauthenticated))
+			return
+		}
+
+		deployment, err := run(ctx, c.Param("id"), actor)
+		if err != nil {
+			renderError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, deployment)
+	}
+}
+
+func (h *DeployHandler) history(c *gin.Context) {
+	events, err := h.flowDeployer.History(yctx.NewContext(c.Request.Context()), c.Param("id"))
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/module/trigger"
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestDeployHandler(t *testing.T) {
+	suite.Run(t, new(DeployHandlerTestSuite))
+}
+
+// flowDeployRepositoryStub accepts any deploy transition.
+type flowDeployRepositoryStub struct {
+	flow *flowmanager.Flow
+}
+
+func (r *flowDeployRepositoryStub) SaveDeployment(ctx *yctx.Context, flowId string, expected ybase.DeployStatus, deployment *flowmanager.FlowDeployment) error {
+	r.flow.Deployment = deployment
+	return nil
+}
+
+type DeployHandlerTestSuite struct {
+	suite.Suite
+	engine *gin.Engine
+}
+
+func (s *DeployHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	flow := &flowmanager.Flow{
+		Id:     "flow-1",
+		Tenant: "tenant-a",
+		Triggers: []flowmanager.FlowTrigger{
+			{Id: "orders", Type: flowmanager.TriggerTypeQueue, Queue: &flowmanager.QueueTrigger{Stream: "orders"}},
+		},
+	}
+
+	flowReaderRepositoryMock := new(flowmanager.FlowReaderRepositoryMock)
+	flowReaderRepositoryMock.On("GetById", mock.Anything, "missing").Return((*flowmanager.Flow)(nil))
+	flowReaderRepositoryMock.On("GetById", mock.Anything, "flow-1").Return(flow)
+
+	s.engine = gin.New()
+	s.engine.Use(Authenticate(NewHeaderAuthenticator()))
+
+	NewDeployHandler(
+		flowmanager.NewFlowDeployer(
+			flowReaderRepositoryMock,
+			&flowDeployRepositoryStub{flow: flow},
+			flowmanager.NewInMemoryDeployAuditRepository(),
+			trigger.NewDispatcher(nil),
+			nil,
+		),
+	).Register(s.engine)
+}
+
+func (s *DeployHandlerTestSuite) request(method, path string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, nil)
+	request.Header.Set(HeaderUserId, "alice")
+	request.Header.Set(HeaderTenantId, "tenant-a")
+
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (s *DeployHandlerTestSuite) TestDeploy_FailedTriggerIsAudited() {
+	s.Equal(http.StatusBadRequest, s.request(http.MethodPost, "/flows/flow-1/deploy").Code)
+	s.Equal(http.StatusConflict, s.request(http.MethodPost, "/flows/flow-1/rollback").Code)
+	s.Equal(http.StatusOK, s.request(http.MethodPost, "/flows/flow-1/undeploy").Code)
+
+	recorder := s.request(http.MethodGet, "/flows/flow-1/deployments")
+	s.Equal(http.StatusOK, recorder.Code)
+
+	var events []flowmanager.DeployEvent
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &events))
+	s.Require().Len(events, 2)
+	s.Equal(ybase.DeployStatusFailed, events[0].To)
+	s.Equal("alice", events[0].Actor)
+	s.Equal(ybase.DeployStatusCanceled, events[1].To)
+}
+
+func (s *DeployHandlerTestSuite) TestDeploy_WithoutCallerId() {
+	request := httptest.NewRequest(http.MethodPost, "/flows/flow-1/undeploy", nil)
+	request.Header.Set(HeaderTenantId, "tenant-a")
+	request.Header.Set(HeaderUserRoles, string(RoleOperator))
+
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, request)
+
+	s.Equal(http.StatusUnauthorized, recorder.Code)
+}
+
+func (s *DeployHandlerTestSuite) TestDeploy_FlowNotFound() {
+	s.Equal(http.StatusNotFound, s.request(http.MethodPost, "/flows/missing/deploy").Code)
+	s.Equal(http.StatusNotFound, s.request(http.MethodGet, "/flows/missing/deployments").Code)
+}
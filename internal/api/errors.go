@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/module/scheduler"
+	"github.com/yrn-go/yrn/module/secretmanager"
+	"github.com/yrn-go/yrn/module/trigger"
+	"github.com/yrn-go/yrn/module/webhook"
+	"github.com/yrn-go/yrn/pkg/plugincore"
+	"golang.org/x/exp/slog"
+)
+
+type ErrorResponse struct {
+	Error  string                            `json:"error"`
+	Errors []plugincore.FieldError           `json:"errors,omitempty"`
+	Report *flowmanager.FlowValidationReport `json:"report,omitempty"`
+}
+
+// renderError writes the JSON error response matching the error class.
+func renderError(c *gin.Context, err error) {
+	var (
+		validationErr     *plugincore.ValidationError
+		flowValidationErr *flowmanager.FlowValidationError
+	)
+
+	switch {
+	case errors.As(err, &validationErr):
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:  "validation failed",
+			Errors: validationErr.Errors,
+		})
+	case errors.As(err, &flowValidationErr):
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Error:  flowValidationErr.Error(),
+			Report: flowValidationErr.Report,
+		})
+	case errors.Is(err, flowmanager.ErrFlowNotFound), errors.Is(err, flowmanager.ErrFlowVersionNotFound), errors.Is(err, secretmanager.ErrSecretNotFound), errors.Is(err, scheduler.ErrScheduleNotFound),
+		errors.Is(err, webhook.ErrWebhookNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, secretmanager.ErrInvalidSecretName), errors.Is(err, secretmanager.ErrEmptySecretValue), errors.Is(err, scheduler.ErrInvalidSchedule),
+		errors.Is(err, webhook.ErrInvalidWebhook), errors.Is(err, trigger.ErrListenerMissing), errors.Is(err, flowmanager.ErrInvalidFlowQuery):
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, flowmanager.ErrFlowNotDeployed), errors.Is(err, flowmanager.ErrInvalidDeployTransition),
+		errors.Is(err, flowmanager.ErrDeployConflict), errors.Is(err, flowmanager.ErrFlowVersionExists),
+		errors.Is(err, flowmanager.ErrFlowVersionConflict), errors.Is(err, flowmanager.ErrFlowDeployed):
+		c.AbortWithStatusJSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, webhook.ErrInvalidSignature), errors.Is(err, ErrUnauthenticated):
+		c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, ErrForbidden):
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, webhook.ErrBodyTooLarge):
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, webhook.ErrSignatureUnavailable):
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, ErrorResponse{Error: err.Error()})
+	default:
+		slog.Error("request failed",
+			slog.String("path", c.FullPath()),
+			slog.Any("error", err))
+
+		c.AbortWithStatusJSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
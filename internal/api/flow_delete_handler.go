@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type FlowDeleteHandler struct {
+	flowDeleter *flowmanager.FlowDeleter
+}
+
+func NewFlowDeleteHandler(flowDeleter *flowmanager.FlowDeleter) *FlowDeleteHandler {
+	return &FlowDeleteHandler{
+		flowDeleter: flowDeleter,
+	}
+}
+
+func (h *FlowDeleteHandler) Register(router gin.IRouter) {
+	router.GET("/flows/deleted", h.deleted)
+	router.DELETE("/flows/:id", h.delete)
+	router.POST("/flows/:id/restore", h.restore)
+}
+
+func (h *FlowDeleteHandler) delete(c *gin.Context) {
+	if err := h.flowDeleter.Delete(yctx.NewContext(c.Request.Context()), c.Param("id")); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *FlowDeleteHandler) restore(c *gin.Context) {
+	flow, err := h.flowDeleter.Restore(yctx.NewContext(c.Request.Context()), c.Param("id"))
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flow)
+}
+
+func (h *FlowDeleteHandler) deleted(c *gin.Context) {
+	pagination, ok := paginationParams(c)
+	if !ok {
+		return
+	}
+
+	flows, err := h.flowDeleter.Deleted(yctx.NewContext(c.Request.Context()), pagination)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flows)
+}
+
+// paginationParams reads the page (from 0) and size query parameters and
+// writes a bad request response when they are invalid.
+func paginationParams(c *gin.Context) (*flowmanager.Pagination, bool) {
+	pagination := &flowmanager.Pagination{Size: defaultPageSize}
+
+	var err error
+
+	if value := c.Query("page"); value != "" {
+		if pagination.Page, err = strconv.Atoi(value); err != nil || pagination.Page < 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: "invalid page: " + strconv.Quote(value)})
+			return nil, false
+		}
+	}
+
+	if value := c.Query("size"); value != "" {
+		if pagination.Size, err = strconv.Atoi(value); err != nil || pagination.Size < 1 || pagination.Size > maxPageSize {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: "invalid size: " + strconv.Quote(value)})
+			return nil, false
+		}
+	}
+
+	return pagination, true
+}
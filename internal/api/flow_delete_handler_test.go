@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/ybase"
+)
+
+func TestFlowDeleteHandler(t *testing.T) {
+	suite.Run(t, new(FlowDeleteHandlerTestSuite))
+}
+
+type FlowDeleteHandlerTestSuite struct {
+	suite.Suite
+	flowReaderRepositoryMock *flowmanager.FlowReaderRepositoryMock
+	flowWriteRepositoryMock  *flowmanager.FlowWriteRepositoryMock
+	engine                   *gin.Engine
+}
+
+func (s *FlowDeleteHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	s.flowReaderRepositoryMock = new(flowmanager.FlowReaderRepositoryMock)
+	s.flowWriteRepositoryMock = new(flowmanager.FlowWriteRepositoryMock)
+	s.engine = gin.New()
+
+	NewFlowDeleteHandler(
+		flowmanager.NewFlowDeleter(s.flowReaderRepositoryMock, s.flowWriteRepositoryMock),
+	).Register(s.engine)
+}
+
+func (s *FlowDeleteHandlerTestSuite) request(method, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+
+	return recorder
+}
+
+func (s *FlowDeleteHandlerTestSuite) TestDelete() {
+	s.flowReaderRepositoryMock.On("GetById", mock.Anything, "flow-1").Return(&flowmanager.Flow{Id: "flow-1"})
+	s.flowReaderRepositoryMock.On("GetById", mock.Anything, "missing").Return((*flowmanager.Flow)(nil))
+	s.flowWriteRepositoryMock.On("Delete", mock.Anything, "flow-1").Return(nil)
+
+	s.Equal(http.StatusNoContent, s.request(http.MethodDelete, "/flows/flow-1").Code)
+	s.Equal(http.StatusNotFound, s.request(http.MethodDelete, "/flows/missing").Code)
+	s.flowWriteRepositoryMock.AssertExpectations(s.T())
+}
+
+func (s *FlowDeleteHandlerTestSuite) TestDelete_DeployedFlowReturns409() {
+	s.flowReaderRepositoryMock.On("GetById", mock.Anything, "flow-1").Return(&flowmanager.Flow{
+		Id:         "flow-1",
+		Deployment: &flowmanager.FlowDeployment{Status: ybase.DeployStatusInOperation},
+	})
+
+	s.Equal(http.StatusConflict, s.request(http.MethodDelete, "/flows/flow-1").Code)
+	s.flowWriteRepositoryMock.AssertNotCalled(s.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+func (s *FlowDeleteHandlerTestSuite) TestRestore() {
+	s.flowWriteRepositoryMock.On("Restore", mock.Anything, "flow-1").Return(nil)
+	s.flowWriteRepositoryMock.On("Restore", mock.Anything, "missing").Return(flowmanager.ErrFlowNotFound)
+	s.flowReaderRepositoryMock.On("GetById", mock.Anything, "flow-1").Return(&flowmanager.Flow{Id: "flow-1"})
+
+	s.Equal(http.StatusOK, s.request(http.MethodPost, "/flows/flow-1/restore").Code)
+	s.Equal(http.StatusNotFound, s.request(http.MethodPost, "/flows/missing/restore").Code)
+}
+
+func (s *FlowDeleteHandlerTestSuite) TestDeleted_Pagination() {
+	s.flowReaderRepositoryMock.
+		On("GetDeleted", mock.Anything, &flowmanager.Pagination{Page: 1, Size: 5}).
+		Return([]flowmanager.Flow{{Id: "flow-1"}})
+
+	s.Equal(http.StatusOK, s.request(http.MethodGet, "/flows/deleted?page=1&size=5").Code)
+	s.Equal(http.StatusBadRequest, s.request(http.MethodGet, "/flows/deleted?size=1000").Code)
+	s.Equal(http.StatusBadRequest, s.request(http.MethodGet, "/flows/deleted?page=-1").Code)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+type FlowHandler struct {
+	flowCreator   *flowmanager.FlowCreator
+	flowValidator *flowmanager.FlowValidator
+	flowExecutor  *flowmanager.FlowExecutor
+}
+
+func NewFlowHandler(
+	flowCreator *flowmanager.FlowCreator,
+	flowValidator *flowmanager.FlowValidator,
+	flowExecutor *flowmanager.FlowExecutor,
+) *FlowHandler {
+	return &FlowHandler{
+		flowCreator:   flowCreator,
+		flowValidator: flowValidator,
+		flowExecutor:  flowExecutor,
+	}
+}
+
+func (h *FlowHandler) Register(router gin.IRouter) {
+	router.POST("/flows", h.create)
+	router.POST("/flows/validate", h.validate)
+	router.POST("/flows/:id/execute", h.execute)
+	router.POST("/flows/:id/test", h.test)
+}
+
+func (h *FlowHandler) create(c *gin.Context) {
+	var flow flowmanager.Flow
+
+	if err := c.ShouldBindJSON(&flow); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.flowCreator.CreateFlow(yctx.NewContext(c.Request.Context()), &flow); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, flow)
+}
+
+func (h *FlowHandler) validate(c *gin.Context) {
+	var flow flowmanager.Flow
+
+	if err := c.ShouldBindJSON(&flow); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	report, err := h.flowValidator.Validate(yctx.NewContext(c.Request.Context()), &flow)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// execute runs flows that are IN_OPERATION.
+func (h *FlowHandler) execute(c *gin.Context) {
+	h.run(c, yctx.NewContext(c.Request.Context()))
+}
+
+// test runs the flow even when it is not deployed. It has its own route, so
+// it needs flow:test instead of flow:execute.
+func (h *FlowHandler) test(c *gin.Context) {
+	h.run(c, flowmanager.WithTestMode(yctx.NewContext(c.Request.Context())))
+}
+
+func (h *FlowHandler) run(c *gin.Context, ctx *yctx.Context) {
+	var eventRequestData any
+
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&eventRequestData); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	response, err := h.flowExecutor.Do(ctx, c.Param("id"), eventRequestData)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
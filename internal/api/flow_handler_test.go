@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/plugincore"
+	"github.com/yrn-go/yrn/pkg/pluginhttp"
+	"github.com/yrn-go/yrn/pkg/pluginmapper"
+)
+
+func TestFlowHandler(t *testing.T) {
+	suite.Run(t, new(FlowHandlerTestSuite))
+}
+
+type FlowHandlerTestSuite struct {
+	suite.Suite
+	flowReaderRepositoryMock *flowmanager.FlowReaderRepositoryMock
+	flowWriteRepositoryMock  *flowmanager.FlowWriteRepositoryMock
+	statusRepoMock           *flowmanager.PluginStatusRepositoryMock
+	engine                   *gin.Engine
+}
+
+func (s *FlowHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	s.flowReaderRepositoryMock = new(flowmanager.FlowReaderRepositoryMock)
+	s.flowWriteRepositoryMock = new(flowmanager.FlowWriteRepositoryMock)
+	s.statusRepoMock = new(flowmanager.PluginStatusRepositoryMock)
+	s.engine = gin.New()
+
+	pluginManager, err := pluginmapper.NewPluginManagerLocal()
+	s.Require().NoError(err)
+
+	flowValidator := flowmanager.NewFlowValidator(pluginManager)
+
+	NewFlowHandler(
+		flowmanager.NewFlowCreator(
+			s.flowWriteRepositoryMock,
+			flowmanager.NewFlowVersioner(s.flowReaderRepositoryMock, s.flowWriteRepositoryMock, flowmanager.NewInMemoryFlowVersionRepository(), flowValidator),
+			flowValidator,
+		),
+		flowValidator,
+		flowmanager.NewFlowExecutor(s.flowReaderRepositoryMock, flowmanager.NewInMemoryFlowVersionRepository(), pluginManager, s.statusRepoMock, nil),
+	).Register(s.engine)
+}
+
+func (s *FlowHandlerTestSuite) TestExecute_ValidationErrorReturns422() {
+	const flowId = "flow-invalid-input"
+
+	s.flowReaderRepositoryMock.
+		On("GetById", mock.Anything, flowId).
+		Return(&flowmanager.Flow{
+			Id:               flowId,
+			FirstPluginToRun: "http",
+			Plugins: []flowmanager.FlowPlugin{
+				{
+					Id:          "http",
+					Slug:        pluginhttp.SlugHttp,
+					SchemaInput: `{"request": {"method": "GET"}}`,
+				},
+			},
+		}, nil)
+
+	s.statusRepoMock.
+		On("Save", mock.Anything, mock.Anything).
+		Return(nil)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/flows/"+flowId+"/test", bytes.NewBufferString(`{"input": 1}`))
+	s.engine.ServeHTTP(recorder, request)
+
+	var response ErrorResponse
+	s.Equal(http.StatusUnprocessableEntity, recorder.Code)
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+	s.Equal("validation failed", response.Error)
+	s.Equal([]plugincore.FieldError{
+		{Field: "request.url", Rule: "required", Message: "url is required"},
+	}, response.Errors)
+}
+
+func (s *FlowHandlerTestSuite) TestExecute_NotDeployedFlowReturns409() {
+	s.flowReaderRepositoryMock.
+		On("GetById", mock.Anything, "pending").
+		Return(&flowmanager.Flow{Id: "pending"}, nil)
+
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/flows/pending/execute", nil))
+
+	s.Equal(http.StatusConflict, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/flows/pending/execute?test=true", nil))
+
+	s.Equal(http.StatusConflict, recorder.Code)
+}
+
+func (s *FlowHandlerTestSuite) TestExecute_FlowNotFoundReturns404() {
+	s.flowReaderRepositoryMock.
+		On("GetById", mock.Anything, "missing").
+		Return(nil, nil)
+
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/flows/missing/execute", nil))
+
+	s.Equal(http.StatusNotFound, recorder.Code)
+}
+
+func (s *FlowHandlerTestSuite) TestCreate_InvalidFlowReturnsReport() {
+	body := `{
+		"id": "flow-1",
+		"first_plugin_to_run": "fetch",
+		"plugins": [
+			{
+				"id": "fetch",
+				"slug": "http",
+				"schema_input": "{\"request\": {\"method\": \"FETCH\", \"url\": \"{{ .sharedForAll.notify.url }}\"}}",
+				"next_to_be_executed": ["notify"]
+			},
+			{
+				"id": "notify",
+				"slug": "http",
+				"schema_input": "{\"request\": {\"method\": \"POST\", \"url\": \"{{ .sharedForAll.fetch.url }}\"}}"
+			}
+		]
+	}`
+
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/flows", bytes.NewBufferString(body)))
+
+	var response ErrorResponse
+	s.Equal(http.StatusUnprocessableEntity, recorder.Code)
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+	s.Require().NotNil(response.Report)
+	s.Empty(response.Report.Flow)
+	s.Len(response.Report.Plugins, 1)
+	s.ElementsMatch([]plugincore.FieldError{
+		{Field: ".sharedForAll.notify.url", Rule: flowmanager.ValidationRuleUnknownReference, Message: `plugin "notify" is not executed before this plugin`},
+		{Field: "request.method", Rule: "enum", Message: "request.method must be one of the following: \"GET\", \"POST\", \"PUT\", \"DELETE\", \"PATCH\", \"HEAD\", \"OPTIONS\""},
+	}, response.Report.Plugins["fetch"])
+	s.flowWriteRepositoryMock.AssertNotCalled(s.T(), "Save", mock.Anything, mock.Anything)
+}
+
+func (s *FlowHandlerTestSuite) TestCreate_ValidFlowIsSaved() {
+	body := `{
+		"id": "flow-1",
+		"first_plugin_to_run": "fetch",
+		"plugins": [
+			{
+				"id": "fetch",
+				"slug": "http",
+				"schema_input": "{\"request\": {\"method\": \"GET\", \"url\": \"{{ .data.url }}\"}}"
+			}
+		]
+	}`
+
+	s.flowWriteRepositoryMock.
+		On("Save", mock.Anything, mock.Anything).
+		Return(nil)
+
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/flows", bytes.NewBufferString(body)))
+
+	s.Equal(http.StatusCreated, recorder.Code)
+	s.flowWriteRepositoryMock.AssertExpectations(s.T())
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+type FlowSearchHandler struct {
+	flowSearcher *flowmanager.FlowSearcher
+}
+
+func NewFlowSearchHandler(flowSearcher *flowmanager.FlowSearcher) *FlowSearchHandler {
+	return &FlowSearchHandler{
+		flowSearcher: flowSearcher,
+	}
+}
+
+func (h *FlowSearchHandler) Register(router gin.IRouter) {
+	router.GET("/flows", h.search)
+}
+
+// search filters by tenant, name (prefix), plugin (slug), status (deploy
+// status), tag (repeatable) and q (words found, ignoring case, as substrings
+// of the name, description or tags).
+func (h *FlowSearchHandler) search(c *gin.Context) {
+	pagination, ok := paginationParams(c)
+	if !ok {
+		return
+	}
+
+	query := &flowmanager.FlowQuery{
+		Pagination:   *pagination,
+		Tenant:       c.Query("tenant"),
+		NamePrefix:   c.Query("name"),
+		PluginSlug:   c.Query("plugin"),
+		DeployStatus: ybase.DeployStatus(c.Query("status")),
+		Tags:         c.QueryArray("tag"),
+		Text:         c.Query("q"),
+		Sort:         flowmanager.FlowSort(c.Query("sort")),
+		Cursor:       c.Query("cursor"),
+	}
+
+	page, err := h.flowSearcher.Search(yctx.NewContext(c.Request.Context()), query)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestFlowSearchHandler(t *testing.T) {
+	suite.Run(t, new(FlowSearchHandlerTestSuite))
+}
+
+type FlowSearchHandlerTestSuite struct {
+	suite.Suite
+	flowSearchRepositoryMock *flowmanager.FlowSearchRepositoryMock
+	engine                   *gin.Engine
+}
+
+func (s *FlowSearchHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	s.flowSearchRepositoryMock = new(flowmanager.FlowSearchRepositoryMock)
+	s.engine = gin.New()
+	s.engine.Use(Authenticate(NewHeaderAuthenticator()))
+
+	NewFlowSearchHandler(flowmanager.NewFlowSearcher(nil, s.flowSearchRepositoryMock)).Register(s.engine)
+}
+
+func (s *FlowSearchHandlerTestSuite) request(path string) *httptest.ResponseRecorder {
+	// an admin of every tenant, so the tenant filter of the query is kept.
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	request.Header.Set(HeaderUserId, "alice")
+	request.Header.Set(HeaderTenantId, yctx.AllTenants)
+	request.Header.Set(HeaderUserRoles, string(RoleAdmin))
+
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (s *FlowSearchHandlerTestSuite) TestSearch_ReadsFilters() {
+	s.flowSearchRepositoryMock.
+		On("Search", mock.Anything, mock.MatchedBy(func(query *flowmanager.FlowQuery) bool {
+			return query.Tenant == "tenant-a" &&
+				query.NamePrefix == "Orders" &&
+				query.PluginSlug == "plugin-http" &&
+				query.DeployStatus == ybase.DeployStatusInOperation &&
+				len(query.Tags) == 2 &&
+				query.Text == "erp" &&
+				query.Sort == flowmanager.FlowSortUpdatedDesc &&
+				query.Page == 2 && query.Size == 5
+		})).
+		Return([]flowmanager.Flow{{Id: "flow-1", Name: "Orders sync"}}, 11)
+
+	recorder := s.request("/flows?tenant=tenant-a&name=Orders&plugin=plugin-http&status=IN_OPERATION&tag=erp&tag=orders&q=erp&sort=-updated_at&page=2&size=5")
+	s.Require().Equal(http.StatusOK, recorder.Code)
+
+	var page flowmanager.Page[flowmanager.GetAllResponseFlow]
+	s.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &page))
+	s.Equal(11, page.TotalItems)
+	s.Equal(3, page.TotalPages)
+	s.Require().Len(page.Items, 1)
+	s.Equal(ybase.DeployStatusPending, page.Items[0].DeployStatus)
+}
+
+func (s *FlowSearchHandlerTestSuite) TestSearch_NextCursor() {
+	s.flowSearchRepositoryMock.
+		On("Search", mock.Anything, mock.MatchedBy(func(query *flowmanager.FlowQuery) bool {
+			return query.Size == 3 && query.After == nil
+		})).
+		Return([]flowmanager.Flow{{Id: "flow-1"}, {Id: "flow-2"}, {Id: "flow-3"}}, 3)
+	s.flowSearchRepositoryMock.
+		On("Search", mock.Anything, mock.MatchedBy(func(query *flowmanager.FlowQuery) bool {
+			return query.After != nil && query.After.Id == "flow-2"
+		})).
+		Return([]flowmanager.Flow{{Id: "flow-3"}}, 3)
+
+	var page flowmanager.Page[flowmanager.GetAllResponseFlow]
+	s.Require().NoError(json.Unmarshal(s.request("/flows?size=2").Body.Bytes(), &page))
+	s.Len(page.Items, 2)
+	s.Require().NotEmpty(page.NextCursor)
+
+	recorder := s.request("/flows?size=2&cursor=" + page.NextCursor)
+	s.Require().Equal(http.StatusOK, recorder.Code)
+
+	page = flowmanager.Page[flowmanager.GetAllResponseFlow]{}
+	s.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &page))
+	s.Len(page.Items, 1)
+	s.Empty(page.NextCursor)
+}
+
+func (s *FlowSearchHandlerTestSuite) TestSearch_InvalidQueryReturns400() {
+	s.Equal(http.StatusBadRequest, s.request("/flows?sort=created_at").Code)
+	s.Equal(http.StatusBadRequest, s.request("/flows?cursor=invalid!").Code)
+	s.Equal(http.StatusBadRequest, s.request("/flows?size=0").Code)
+	s.flowSearchRepositoryMock.AssertNotCalled(s.T(), "Search", mock.Anything, mock.Anything)
+}
+
+func (s *FlowSearchHandlerTestSuite) TestSearch_ScopedToCallerTenant() {
+	s.flowSearchRepositoryMock.
+		On("Search", mock.Anything, mock.MatchedBy(func(query *flowmanager.FlowQuery) bool {
+			return query.Tenant == "tenant-b"
+		})).
+		Return([]flowmanager.Flow{{Id: "flow-b", Tenant: "tenant-b"}}, 1)
+
+	request := func(path string) *httptest.ResponseRecorder {
+		httpRequest := httptest.NewRequest(http.MethodGet, path, nil)
+		httpRequest.Header.Set(HeaderTenantId, "tenant-b")
+
+		recorder := httptest.NewRecorder()
+		s.engine.ServeHTTP(recorder, httpRequest)
+
+		return recorder
+	}
+
+	var page flowmanager.Page[flowmanager.GetAllResponseFlow]
+	s.Require().NoError(json.Unmarshal(request("/flows").Body.Bytes(), &page))
+	s.Require().Len(page.Items, 1)
+	s.Equal("flow-b", page.Items[0].Id)
+
+	page = flowmanager.Page[flowmanager.GetAllResponseFlow]{}
+	s.Require().NoError(json.Unmarshal(request("/flows?tenant=tenant-a").Body.Bytes(), &page))
+	s.Empty(page.Items)
+	s.flowSearchRepositoryMock.AssertNumberOfCalls(s.T(), "Search", 1)
+}
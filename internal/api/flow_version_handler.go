@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+type FlowVersionHandler struct {
+	flowVersioner *flowmanager.FlowVersioner
+}
+
+func NewFlowVersionHandler(flowVersioner *flowmanager.FlowVersioner) *FlowVersionHandler {
+	return &FlowVersionHandler{
+		flowVersioner: flowVersioner,
+	}
+}
+
+func (h *FlowVersionHandler) Register(router gin.IRouter) {
+	router.PUT("/flows/:id", h.update)
+	router.GET("/flows/:id/versions", h.list)
+	router.GET("/flows/:id/versions/:version", h.get)
+	router.GET("/flows/:id/diff", h.diff)
+	router.POST("/flows/:id/versions/:version/rollback", h.rollback)
+}
+
+func (h *FlowVersionHandler) update(c *gin.Context) {
+	var flow flowmanager.Flow
+
+	if err := c.ShouldBindJSON(&flow); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	flow.Id = c.Param("id")
+
+	if err := h.flowVersioner.Update(yctx.NewContext(c.Request.Context()), &flow); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flow)
+}
+
+func (h *FlowVersionHandler) list(c *gin.Context) {
+	versions, err := h.flowVersioner.Versions(yctx.NewContext(c.Request.Context()), c.Param("id"))
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+func (h *FlowVersionHandler) get(c *gin.Context) {
+	version, ok := versionParam(c, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	flowVersion, err := h.flowVersioner.GetVersion(yctx.NewContext(c.Request.Context()), c.Param("id"), version)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flowVersion)
+}
+
+func (h *FlowVersionHandler) diff(c *gin.Context) {
+	from, ok := versionParam(c, c.Query("from"))
+	if !ok {
+		return
+	}
+
+	to, ok := versionParam(c, c.Query("to"))
+	if !ok {
+		return
+	}
+
+	changes, err := h.flowVersioner.Diff(yctx.NewContext(c.Request.Context()), c.Param("id"), from, to)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+func (h *FlowVersionHandler) rollback(c *gin.Context) {
+	version, ok := versionParam(c, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	flow, err := h.flowVersioner.Rollback(yctx.NewContext(c.Request.Context()), c.Param("id"), version)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flow)
+}
+
+// versionParam writes a bad request response when value is not a version.
+func versionParam(c *gin.Context, value string) (int, bool) {
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: "invalid flow version: " + strconv.Quote(value)})
+		return 0, false
+	}
+
+	return version, true
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/pluginmapper"
+)
+
+func TestFlowVersionHandler(t *testing.T) {
+	suite.Run(t, new(FlowVersionHandlerTestSuite))
+}
+
+type FlowVersionHandlerTestSuite struct {
+	suite.Suite
+	flowWriteRepositoryMock *flowmanager.FlowWriteRepositoryMock
+	engine                  *gin.Engine
+}
+
+func (s *FlowVersionHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	flow := flowmanager.Flow{
+		Id:               "flow-1",
+		Version:          1,
+		Revision:         3,
+		FirstPluginToRun: "fetch",
+		Plugins: []flowmanager.FlowPlugin{
+			{Id: "fetch", Slug: "http", SchemaInput: `{"request": {"method": "GET", "url": "https://example.com"}}`},
+		},
+	}
+
+	flowVersionRepository := flowmanager.NewInMemoryFlowVersionRepository()
+	s.Require().NoError(flowVersionRepository.Save(nil, &flowmanager.FlowVersion{
+		Id:      "flow-1@1",
+		FlowId:  "flow-1",
+		Version: 1,
+		Flow:    flow,
+	}))
+
+	flowReaderRepositoryMock := new(flowmanager.FlowReaderRepositoryMock)
+	flowReaderRepositoryMock.On("GetById", mock.Anything, "flow-1").Return(&flow)
+	flowReaderRepositoryMock.On("GetById", mock.Anything, "missing").Return((*flowmanager.Flow)(nil))
+
+	s.flowWriteRepositoryMock = new(flowmanager.FlowWriteRepositoryMock)
+	s.flowWriteRepositoryMock.On("Update", mock.Anything, mock.Anything, 3).Return(nil)
+
+	pluginManager, err := pluginmapper.NewPluginManagerLocal()
+	s.Require().NoError(err)
+
+	s.engine = gin.New()
+
+	NewFlowVersionHandler(
+		flowmanager.NewFlowVersioner(
+			flowReaderRepositoryMock,
+			s.flowWriteRepositoryMock,
+			flowVersionRepository,
+			flowmanager.NewFlowValidator(pluginManager),
+		),
+	).Register(s.engine)
+}
+
+func (s *FlowVersionHandlerTestSuite) request(method, path, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+
+	return recorder
+}
+
+func (s *FlowVersionHandlerTestSuite) TestUpdate_CreatesVersionAndDiff() {
+	body := `{
+		"revision": 3,
+		"first_plugin_to_run": "fetch",
+		"plugins": [
+			{
+				"id": "fetch",
+				"slug": "http",
+				"schema_input": "{\"request\": {\"method\": \"POST\", \"url\": \"https://example.com\"}}"
+			}
+		]
+	}`
+
+	recorder := s.request(http.MethodPut, "/flows/flow-1", body)
+	s.Require().Equal(http.StatusOK, recorder.Code)
+
+	var flow flowmanager.Flow
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &flow))
+	s.Equal("flow-1", flow.Id)
+	s.Equal(2, flow.Version)
+	s.Equal(4, flow.Revision)
+
+	recorder = s.request(http.MethodGet, "/flows/flow-1/versions", "")
+	s.Equal(http.StatusOK, recorder.Code)
+
+	var versions []flowmanager.FlowVersion
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &versions))
+	s.Len(versions, 2)
+
+	recorder = s.request(http.MethodGet, "/flows/flow-1/diff?from=1&to=2", "")
+	s.Equal(http.StatusOK, recorder.Code)
+
+	var changes []flowmanager.FlowChange
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &changes))
+	s.Require().Len(changes, 1)
+	s.Equal("plugins[fetch].schema_input", changes[0].Path)
+}
+
+func (s *FlowVersionHandlerTestSuite) TestUpdate_InvalidFlowReturns422() {
+	recorder := s.request(http.MethodPut, "/flows/flow-1", `{"first_plugin_to_run": "missing", "revision": 3}`)
+
+	s.Equal(http.StatusUnprocessableEntity, recorder.Code)
+	s.flowWriteRepositoryMock.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *FlowVersionHandlerTestSuite) TestUpdate_StaleRevisionReturns409() {
+	recorder := s.request(http.MethodPut, "/flows/flow-1", `{"first_plugin_to_run": "fetch", "version": 1, "revision": 2}`)
+
+	s.Equal(http.StatusConflict, recorder.Code)
+	s.flowWriteRepositoryMock.AssertNotCalled(s.T(), "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *FlowVersionHandlerTestSuite) TestRollback() {
+	s.Equal(http.StatusOK, s.request(http.MethodPost, "/flows/flow-1/versions/1/rollback", "").Code)
+	s.Equal(http.StatusNotFound, s.request(http.MethodPost, "/flows/flow-1/versions/7/rollback", "").Code)
+	s.Equal(http.StatusNotFound, s.request(http.MethodPost, "/flows/missing/versions/1/rollback", "").Code)
+}
+
+func (s *FlowVersionHandlerTestSuite) TestVersionParams() {
+	s.Equal(http.StatusOK, s.request(http.MethodGet, "/flows/flow-1/versions/1", "").Code)
+	s.Equal(http.StatusNotFound, s.request(http.MethodGet, "/flows/flow-1/versions/2", "").Code)
+	s.Equal(http.StatusBadRequest, s.request(http.MethodGet, "/flows/flow-1/versions/latest", "").Code)
+	s.Equal(http.StatusBadRequest, s.request(http.MethodGet, "/flows/flow-1/diff?from=1", "").Code)
+}
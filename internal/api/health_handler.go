@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+const healthTimeout = 2 * time.Second
+
+// HealthChecker is implemented by the database clients, such as
+// mongodb.Client and boltdb.Store.
+type HealthChecker interface {
+	Ping(ctx *yctx.Context) error
+}
+
+type HealthResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type HealthHandler struct {
+	healthChecker HealthChecker
+}
+
+func NewHealthHandler(healthChecker HealthChecker) *HealthHandler {
+	return &HealthHandler{
+		healthChecker: healthChecker,
+	}
+}
+
+func (h *HealthHandler) Register(router gin.IRouter) {
+	router.GET("/health", h.health)
+}
+
+// health answers 503 when the database does not answer in healthTimeout.
+func (h *HealthHandler) health(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthTimeout)
+	defer cancel()
+
+	if err := h.healthChecker.Ping(yctx.NewContext(ctx)); err != nil {
+		c.JSON(http.StatusServiceUnavailable, HealthResponse{Status: "unavailable", Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestHealthHandler(t *testing.T) {
+	suite.Run(t, new(HealthHandlerTestSuite))
+}
+
+type healthCheckerStub struct {
+	err error
+}
+
+func (h *healthCheckerStub) Ping(ctx *yctx.Context) error {
+	return h.err
+}
+
+type HealthHandlerTestSuite struct {
+	suite.Suite
+	healthChecker *healthCheckerStub
+	engine        *gin.Engine
+}
+
+func (s *HealthHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	s.healthChecker = &healthCheckerStub{}
+	s.engine = gin.New()
+
+	NewHealthHandler(s.healthChecker).Register(s.engine)
+}
+
+func (s *HealthHandlerTestSuite) request() (int, HealthResponse) {
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	var response HealthResponse
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+
+	return recorder.Code, response
+}
+
+func (s *HealthHandlerTestSuite) TestHealth() {
+	code, response := s.request()
+	s.Equal(http.StatusOK, code)
+	s.Equal("ok", response.Status)
+
+	s.healthChecker.err = errors.New("server selection timeout")
+
+	code, response = s.request()
+	s.Equal(http.StatusServiceUnavailable, code)
+	s.Equal("unavailable", response.Status)
+	s.Equal("server selection timeout", response.Error)
+}
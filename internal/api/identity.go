@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+const (
+	// HeaderTenantId selects the tenant of the request.
+	HeaderTenantId = "X-Tenant-Id"
+	// HeaderUserId identifies who makes the request, for the audit.
+	HeaderUserId = "X-User-Id"
+	// HeaderUserRoles has the comma separated roles of the caller.
+	HeaderUserRoles = "X-User-Roles"
+)
+
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+type (
+	// Authenticator identifies the caller of a request. It returns nil when
+	// the request has no credentials and an error wrapping
+	// ErrUnauthenticated when they are invalid.
+	Authenticator interface {
+		Authenticate(request *http.Request) (*yctx.Caller, error)
+	}
+
+	// HeaderAuthenticator trusts the X-Tenant-Id, X-User-Id and
+	// X-User-Roles headers. It is meant for deployments behind a gateway that
+	// authenticates the requests and sets the headers.
+	HeaderAuthenticator struct{}
+
+	// Authenticators tries each authenticator in order and returns the first
+	// caller found. An error stops the chain.
+	Authenticators []Authenticator
+)
+
+func NewHeaderAuthenticator() *HeaderAuthenticator {
+	return &HeaderAuthenticator{}
+}
+
+func (a *HeaderAuthenticator) Authenticate(request *http.Request) (*yctx.Caller, error) {
+	caller := &yctx.Caller{
+		Id:     request.Header.Get(HeaderUserId),
+		Tenant: request.Header.Get(HeaderTenantId),
+	}
+
+	for _, role := range strings.Split(request.Header.Get(HeaderUserRoles), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			caller.Roles = append(caller.Roles, role)
+		}
+	}
+
+	if caller.Id == "" && caller.Tenant == "" && len(caller.Roles) == 0 {
+		return nil, nil
+	}
+
+	return caller, nil
+}
+
+func (a Authenticators) Authenticate(request *http.Request) (*yctx.Caller, error) {
+	for _, authenticator := range a {
+		caller, err := authenticator.Authenticate(request)
+		if err != nil || caller != nil {
+			return caller, err
+		}
+	}
+
+	return nil, nil
+}
+
+// Authenticate adds the caller of the request to its context, so every
+// yctx.Context of the request is scoped to the tenant of the caller.
+func Authenticate(authenticator Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller, err := authenticator.Authenticate(c.Request)
+		if err != nil {
+			renderError(c, err)
+			return
+		}
+
+		if caller != nil {
+			if err = withCaller(c, caller); err != nil {
+				renderError(c, err)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// withCaller scopes the request to the tenant of the caller. Callers without
+// tenant are forbidden, so a missing tenant never widens the access, and only
+// admins may have the tenant yctx.AllTenants.
+func withCaller(c *gin.Context, caller *yctx.Caller) error {
+	switch caller.Tenant {
+	case "":
+		return fmt.Errorf("%w: the caller has no tenant", ErrForbidden)
+	case yctx.AllTenants:
+		if !slices.Contains(caller.Roles, string(RoleAdmin)) {
+			return fmt.Errorf("%w: only admins access every tenant", ErrForbidden)
+		}
+	}
+
+	ctx := yctx.NewContext(c.Request.Context()).WithCaller(caller)
+	c.Request = c.Request.WithContext(ctx.Context())
+
+	return nil
+}
+
+// callerId is empty when the request was not authenticated.
+func callerId(ctx *yctx.Context) string {
+	if caller := ctx.Caller(); caller != nil {
+		return caller.Id
+	}
+
+	return ""
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestIdentity(t *testing.T) {
+	suite.Run(t, new(IdentityTestSuite))
+}
+
+type authenticatorStub struct {
+	err error
+}
+
+func (a *authenticatorStub) Authenticate(request *http.Request) (*yctx.Caller, error) {
+	return nil, a.err
+}
+
+type IdentityTestSuite struct {
+	suite.Suite
+	caller *yctx.Caller
+	tenant string
+}
+
+func (s *IdentityTestSuite) serve(authenticator Authenticator, headers map[string]string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(Authenticate(authenticator))
+	engine.GET("/me", func(c *gin.Context) {
+		ctx := yctx.NewContext(c.Request.Context())
+		s.caller, s.tenant = ctx.Caller(), ctx.Tenant()
+		c.Status(http.StatusNoContent)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/me", nil)
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (s *IdentityTestSuite) TestHeaderAuthenticator_SetsCallerAndTenant() {
+	recorder := s.serve(NewHeaderAuthenticator(), map[string]string{
+		HeaderTenantId: "tenant-a",
+		HeaderUserId:   "alice",
+	})
+	s.Equal(http.StatusNoContent, recorder.Code)
+	s.Equal(&yctx.Caller{Id: "alice", Tenant: "tenant-a"}, s.caller)
+	s.Equal("tenant-a", s.tenant)
+}
+
+func (s *IdentityTestSuite) TestHeaderAuthenticator_WithoutHeaders() {
+	recorder := s.serve(NewHeaderAuthenticator(), nil)
+	s.Equal(http.StatusNoContent, recorder.Code)
+	s.Nil(s.caller)
+	s.Empty(s.tenant)
+}
+
+func (s *IdentityTestSuite) TestAuthenticate_RejectsInvalidCredentials() {
+	recorder := s.serve(&authenticatorStub{err: fmt.Errorf("%w: expired token", ErrUnauthenticated)}, nil)
+	s.Equal(http.StatusUnauthorized, recorder.Code)
+	s.Nil(s.caller)
+
+	recorder = s.serve(&authenticatorStub{err: errors.New("unavailable")}, nil)
+	s.Equal(http.StatusInternalServerError, recorder.Code)
+}
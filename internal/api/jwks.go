@@ -0,0 +1,165 @@
+package api
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+type (
+	// JWKS is a JSON Web Key Set with the public keys that sign the tokens.
+	// Only RSA and EC signing keys are kept.
+	JWKS struct {
+		keys []jwk
+	}
+
+	jwk struct {
+		kid string
+		alg string
+		key crypto.PublicKey
+	}
+
+	jwkJSON struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+)
+
+var jwkCurves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// LoadJWKS reads the key set from a local file, in the format served by the
+// jwks_uri of OIDC providers.
+func LoadJWKS(path string) (*JWKS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	jwks, err := ParseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwks file %s: %w", path, err)
+	}
+
+	return jwks, nil
+}
+
+func ParseJWKS(data []byte) (*JWKS, error) {
+	var document struct {
+		Keys []jwkJSON `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	jwks := &JWKS{}
+
+	for _, key := range document.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		publicKey, err := key.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key.Kid, err)
+		}
+
+		if publicKey != nil {
+			jwks.keys = append(jwks.keys, jwk{kid: key.Kid, alg: key.Alg, key: publicKey})
+		}
+	}
+
+	if len(jwks.keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+
+	return jwks, nil
+}
+
+// find returns the key with kid that can verify alg. Tokens without kid are
+// accepted only when a single key can verify them.
+func (j *JWKS) find(kid, alg string) (crypto.PublicKey, bool) {
+	var found []crypto.PublicKey
+
+	for _, key := range j.keys {
+		if (kid != "" && key.kid != kid) || (key.alg != "" && key.alg != alg) || !jwtKeyMatches(alg, key.key) {
+			continue
+		}
+
+		found = append(found, key.key)
+	}
+
+	if len(found) != 1 {
+		return nil, false
+	}
+
+	return found[0], true
+}
+
+// publicKey returns nil for the key types that are not supported.
+func (k *jwkJSON) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() || e.Int64() < 3 || n.BitLen() < 2048 {
+			return nil, errors.New("weak rsa key")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, ok := jwkCurves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unknown curve %q", k.Crv)
+		}
+
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeJWKInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha512" // hashes of RS384, RS512, ES384 and ES512
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+const (
+	defaultTenantClaim = "tenant"
+	defaultRolesClaim  = "roles"
+
+	// jwtLeeway tolerates the clock skew between the API and the issuer.
+	jwtLeeway = time.Minute
+)
+
+var jwtAlgorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+var jwtCurveBits = map[string]int{
+	"ES256": 256,
+	"ES384": 384,
+	"ES512": 521,
+}
+
+type (
+	JWTConfig struct {
+		JWKS *JWKS
+		// Issuer and Audience, when set, must match the iss and aud claims.
+		Issuer   string
+		Audience string
+		// TenantClaim and RolesClaim name the claims with the tenant and the
+		// roles of the caller, "tenant" and "roles" by default. Nested claims
+		// use dots, as "realm_access.roles". Tokens without the tenant claim
+		// are rejected.
+		TenantClaim string
+		RolesClaim  string
+	}
+
+	// JWTAuthenticator verifies the bearer tokens signed by the keys of a
+	// JWKS, as the ID and access tokens of OIDC providers. The caller is the
+	// sub claim.
+	JWTAuthenticator struct {
+		config JWTConfig
+		now    func() time.Time
+	}
+
+	jwtHeader struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+
+	jwtClaims map[string]any
+)
+
+func NewJWTAuthenticator(config JWTConfig) (*JWTAuthenticator, error) {
+	if config.JWKS == nil {
+		return nil, errors.New("jwt authenticator needs a jwks")
+	}
+
+	if config.TenantClaim == "" {
+		config.TenantClaim = defaultTenantClaim
+	}
+
+	if config.RolesClaim == "" {
+		config.RolesClaim = defaultRolesClaim
+	}
+
+	return &JWTAuthenticator{
+		config: config,
+		now:    time.Now,
+	}, nil
+}
+
+// Authenticate returns nil when the request has no bearer token.
+func (a *JWTAuthenticator) Authenticate(request *http.Request) (*yctx.Caller, error) {
+	token, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil, nil
+	}
+
+	claims, err := a.verify(strings.TrimSpace(token))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: token without sub", ErrUnauthenticated)
+	}
+
+	tenant, _ := claims.lookup(a.config.TenantClaim).(string)
+	if tenant == "" {
+		return nil, fmt.Errorf("%w: token without %s", ErrUnauthenticated, a.config.TenantClaim)
+	}
+
+	return &yctx.Caller{
+		Id:     subject,
+		Tenant: tenant,
+		Roles:  claimStrings(claims.lookup(a.config.RolesClaim)),
+	}, nil
+}
+
+// verify checks the signature and the registered claims of the token and
+// returns its claims.
+func (a *JWTAuthenticator) verify(token string) (jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, errors.New("malformed token header")
+	}
+
+	hash, ok := jwtAlgorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+
+	key, ok := a.config.JWKS.find(header.Kid, header.Alg)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", header.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+
+	digest := hash.New()
+	digest.Write([]byte(parts[0] + "." + parts[1]))
+
+	if !verifyJWTSignature(key, hash, digest.Sum(nil), signature) {
+		return nil, errors.New("invalid token signature")
+	}
+
+	var claims jwtClaims
+	if err = decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, errors.New("malformed token claims")
+	}
+
+	return claims, a.validate(claims)
+}
+
+func (a *JWTAuthenticator) validate(claims jwtClaims) error {
+	now := a.now()
+
+	expiresAt, ok := claims.time("exp")
+	if !ok {
+		return errors.New("token without exp")
+	}
+
+	if now.After(expiresAt.Add(jwtLeeway)) {
+		return errors.New("token expired")
+	}
+
+	if notBefore, ok := claims.time("nbf"); ok && now.Add(jwtLeeway).Before(notBefore) {
+		return errors.New("token not valid yet")
+	}
+
+	if a.config.Issuer != "" && claims["iss"] != a.config.Issuer {
+		return errors.New("invalid token issuer")
+	}
+
+	if a.config.Audience != "" && !slices.Contains(claimStrings(claims["aud"]), a.config.Audience) {
+		return errors.New("invalid token audience")
+	}
+
+	return nil
+}
+
+// lookup follows the dots of name into the nested claims.
+func (c jwtClaims) lookup(name string) any {
+	var value any = map[string]any(c)
+
+	for _, key := range strings.Split(name, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		value = object[key]
+	}
+
+	return value
+}
+
+// time reads a NumericDate claim.
+func (c jwtClaims) time(name string) (time.Time, bool) {
+	number, ok := c[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+// claimStrings reads a claim with a string array or a space separated string,
+// as scope.
+func claimStrings(value any) []string {
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			if item, ok := item.(string); ok {
+				items = append(items, item)
+			}
+		}
+
+		return items
+	default:
+		return nil
+	}
+}
+
+func decodeJWTPart(part string, value any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(value)
+}
+
+// jwtKeyMatches reports whether key can verify the signatures of alg.
+func jwtKeyMatches(alg string, key crypto.PublicKey) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS")
+	case *ecdsa.PublicKey:
+		return jwtCurveBits[alg] == key.Curve.Params().BitSize
+	default:
+		return false
+	}
+}
+
+func verifyJWTSignature(key crypto.PublicKey, hash crypto.Hash, digest, signature []byte) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		// ES signatures are r and s with the size of the curve, not ASN.1.
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+
+		return ecdsa.Verify(key, digest, r, s)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestJWTAuthenticator(t *testing.T) {
+	suite.Run(t, new(JWTAuthenticatorTestSuite))
+}
+
+type JWTAuthenticatorTestSuite struct {
+	suite.Suite
+	rsaKey        *rsa.PrivateKey
+	ecKey         *ecdsa.PrivateKey
+	now           time.Time
+	authenticator *JWTAuthenticator
+}
+
+func (s *JWTAuthenticatorTestSuite) SetupSuite() {
+	var err error
+
+	s.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().NoError(err)
+
+	s.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+}
+
+func (s *JWTAuthenticatorTestSuite) SetupTest() {
+	encode := func(value *big.Int, size int) string {
+		return base64.RawURLEncoding.EncodeToString(value.FillBytes(make([]byte, size)))
+	}
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{
+			"kty": "RSA", "kid": "rsa-1", "use": "sig", "alg": "RS256",
+			"n": base64.RawURLEncoding.EncodeToString(s.rsaKey.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.rsaKey.E)).Bytes()),
+		},
+		{
+			"kty": "EC", "kid": "ec-1", "crv": "P-256",
+			"x": encode(s.ecKey.X, 32), "y": encode(s.ecKey.Y, 32),
+		},
+		{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+	}})
+	s.Require().NoError(err)
+
+	path := filepath.Join(s.T().TempDir(), "jwks.json")
+	s.Require().NoError(os.WriteFile(path, jwks, 0o600))
+
+	keySet, err := LoadJWKS(path)
+	s.Require().NoError(err)
+
+	s.authenticator, err = NewJWTAuthenticator(JWTConfig{
+		JWKS:       keySet,
+		Issuer:     "https://id.example.com",
+		Audience:   "yrn",
+		RolesClaim: "realm_access.roles",
+	})
+	s.Require().NoError(err)
+
+	s.now = time.Unix(1700000000, 0)
+	s.authenticator.now = func() time.Time { return s.now }
+}
+
+func (s *JWTAuthenticatorTestSuite) claims() map[string]any {
+	return map[string]any{
+		"iss":          "https://id.example.com",
+		"aud":          []string{"yrn", "other"},
+		"sub":          "alice",
+		"exp":          s.now.Add(time.Hour).Unix(),
+		"tenant":       "tenant-a",
+		"realm_access": map[string]any{"roles": []string{"editor"}},
+	}
+}
+
+func (s *JWTAuthenticatorTestSuite) sign(alg, kid string, claims map[string]any) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	s.Require().NoError(err)
+
+	payload, err := json.Marshal(claims)
+	s.Require().NoError(err)
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, s.rsaKey, crypto.SHA256, digest[:])
+		s.Require().NoError(err)
+	case "ES256":
+		r, sig, err := ecdsa.Sign(rand.Reader, s.ecKey, digest[:])
+		s.Require().NoError(err)
+		signature = append(r.FillBytes(make([]byte, 32)), sig.FillBytes(make([]byte, 32))...)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (s *JWTAuthenticatorTestSuite) authenticate(token string) (*yctx.Caller, error) {
+	request := httptest.NewRequest(http.MethodGet, "/flows", nil)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return s.authenticator.Authenticate(request)
+}
+
+func (s *JWTAuthenticatorTestSuite) TestAuthenticate_ValidTokens() {
+	expected := &yctx.Caller{Id: "alice", Tenant: "tenant-a", Roles: []string{"editor"}}
+
+	caller, err := s.authenticate(s.sign("RS256", "rsa-1", s.claims()))
+	s.NoError(err)
+	s.Equal(expected, caller)
+
+	caller, err = s.authenticate(s.sign("ES256", "ec-1", s.claims()))
+	s.NoError(err)
+	s.Equal(expected, caller)
+
+	// a single key can verify ES256, so the kid is optional.
+	caller, err = s.authenticate(s.sign("ES256", "", s.claims()))
+	s.NoError(err)
+	s.Equal(expected, caller)
+}
+
+func (s *JWTAuthenticatorTestSuite) TestAuthenticate_WithoutToken() {
+	caller, err := s.authenticate("")
+	s.NoError(err)
+	s.Nil(caller)
+}
+
+func (s *JWTAuthenticatorTestSuite) TestAuthenticate_RejectsInvalidTokens() {
+	with := func(name string, value any) map[string]any {
+		claims := s.claims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+
+		return claims
+	}
+
+	valid := s.sign("RS256", "rsa-1", s.claims())
+	parts := strings.Split(valid, ".")
+	tampered, _ := json.Marshal(with("tenant", "tenant-b"))
+	unsigned, _ := json.Marshal(map[string]string{"alg": "none"})
+
+	for name, token := range map[string]string{
+		"expired":        s.sign("RS256", "rsa-1", with("exp", s.now.Add(-2*time.Minute).Unix())),
+		"without exp":    s.sign("RS256", "rsa-1", with("exp", nil)),
+		"not yet valid":  s.sign("RS256", "rsa-1", with("nbf", s.now.Add(time.Hour).Unix())),
+		"issuer":         s.sign("RS256", "rsa-1", with("iss", "https://other.example.com")),
+		"audience":       s.sign("RS256", "rsa-1", with("aud", "other")),
+		"without sub":    s.sign("RS256", "rsa-1", with("sub", nil)),
+		"without tenant": s.sign("RS256", "rsa-1", with("tenant", nil)),
+		"empty tenant":   s.sign("RS256", "rsa-1", with("tenant", "")),
+		"unknown kid":    s.sign("RS256", "rsa-2", s.claims()),
+		"wrong key":      s.sign("ES256", "rsa-1", s.claims()),
+		"tampered":       parts[0] + "." + base64.RawURLEncoding.EncodeToString(tampered) + "." + parts[2],
+		"alg none":       base64.RawURLEncoding.EncodeToString(unsigned) + "." + parts[1] + ".",
+		"malformed":      "not-a-token",
+	} {
+		_, err := s.authenticate(token)
+		s.ErrorIs(err, ErrUnauthenticated, name)
+	}
+}
+
+func (s *JWTAuthenticatorTestSuite) TestAuthenticate_ToleratesClockSkew() {
+	_, err := s.authenticate(s.sign("RS256", "rsa-1", map[string]any{
+		"iss":    "https://id.example.com",
+		"aud":    "yrn",
+		"sub":    "alice",
+		"tenant": "tenant-a",
+		"exp":    s.now.Add(-30 * time.Second).Unix(),
+	}))
+	s.NoError(err)
+}
+
+func (s *JWTAuthenticatorTestSuite) TestParseJWKS_Errors() {
+	for _, jwks := range []string{
+		`not json`,
+		`{"keys": []}`,
+		`{"keys": [{"kty": "RSA", "n": "AQAB", "e": "AQAB"}]}`,
+		`{"keys": [{"kty": "EC", "crv": "P-192", "x": "AQAB", "y": "AQAB"}]}`,
+		fmt.Sprintf(`{"keys": [{"kty": "EC", "crv": "P-256", "x": "%s", "y": "!"}]}`, "AQAB"),
+	} {
+		_, err := ParseJWKS([]byte(jwks))
+		s.Error(err, jwks)
+	}
+}
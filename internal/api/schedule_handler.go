@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yrn-go/yrn/module/scheduler"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+type (
+	ScheduleHandler struct {
+		scheduler *scheduler.Scheduler
+	}
+
+	CreateScheduleRequest struct {
+		Cron     string `json:"cron" binding:"required"`
+		Timezone string `json:"timezone"`
+		Input    any    `json:"input"`
+		Enabled  *bool  `json:"enabled"`
+	}
+)
+
+func NewScheduleHandler(scheduler *scheduler.Scheduler) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduler: scheduler,
+	}
+}
+
+func (h *ScheduleHandler) Register(router gin.IRouter) {
+	router.POST("/flows/:id/schedules", h.create)
+	router.GET("/flows/:id/schedules", h.listByFlow)
+	router.GET("/schedules/:scheduleId", h.get)
+	router.DELETE("/schedules/:scheduleId", h.delete)
+}
+
+func (h *ScheduleHandler) create(c *gin.Context) {
+	var request CreateScheduleRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	schedule := &scheduler.Schedule{
+		FlowId:   c.Param("id"),
+		Cron:     request.Cron,
+		Timezone: request.Timezone,
+		Input:    request.Input,
+		Enabled:  request.Enabled == nil || *request.Enabled,
+	}
+
+	if err := h.scheduler.Create(yctx.NewContext(c.Request.Context()), schedule); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+func (h *ScheduleHandler) listByFlow(c *gin.Context) {
+	schedules, err := h.scheduler.ListByFlow(yctx.NewContext(c.Request.Context()), c.Param("id"))
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+func (h *ScheduleHandler) get(c *gin.Context) {
+	schedule, err := h.scheduler.Get(yctx.NewContext(c.Request.Context()), c.Param("scheduleId"))
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *ScheduleHandler) delete(c *gin.Context) {
+	if err := h.scheduler.Delete(yctx.NewContext(c.Request.Context()), c.Param("scheduleId")); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
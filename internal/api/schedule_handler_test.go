@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/module/scheduler"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestScheduleHandler(t *testing.T) {
+	suite.Run(t, new(ScheduleHandlerTestSuite))
+}
+
+type flowRunnerStub struct{}
+
+func (f *flowRunnerStub) Do(ctx *yctx.Context, flowId string, eventRequestData any) (any, error) {
+	return nil, nil
+}
+
+type ScheduleHandlerTestSuite struct {
+	suite.Suite
+	scheduleRepository *scheduler.InMemoryScheduleRepository
+	engine             *gin.Engine
+}
+
+func (s *ScheduleHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	s.scheduleRepository = scheduler.NewInMemoryScheduleRepository()
+	s.engine = gin.New()
+	s.engine.Use(Authenticate(NewHeaderAuthenticator()))
+
+	NewScheduleHandler(
+		scheduler.NewScheduler(s.scheduleRepository, &flowRunnerStub{}, scheduler.NewInMemoryLocker()),
+	).Register(s.engine)
+}
+
+func (s *ScheduleHandlerTestSuite) request(method, path, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+
+	return recorder
+}
+
+func (s *ScheduleHandlerTestSuite) TestCreate_ReturnsNextAndLastRun() {
+	recorder := s.request(http.MethodPost, "/flows/flow-1/schedules", `{"cron": "0 9 * * 1-5", "timezone": "America/Sao_Paulo"}`)
+	s.Equal(http.StatusCreated, recorder.Code)
+
+	var created scheduler.Schedule
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &created))
+	s.Equal("flow-1", created.FlowId)
+	s.True(created.Enabled)
+	s.NotNil(created.NextRunAt)
+
+	s.NoError(s.scheduleRepository.SaveRun(yctx.NewContext(context.Background()), created.Id, scheduler.ScheduleRun{Error: "failed"}))
+
+	recorder = s.request(http.MethodGet, "/schedules/"+created.Id, "")
+	s.Equal(http.StatusOK, recorder.Code)
+
+	var stored scheduler.Schedule
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &stored))
+	s.Equal("failed", stored.LastRun.Error)
+	s.NotNil(stored.NextRunAt)
+
+	recorder = s.request(http.MethodGet, "/flows/flow-1/schedules", "")
+	s.Equal(http.StatusOK, recorder.Code)
+	s.Contains(recorder.Body.String(), created.Id)
+}
+
+func (s *ScheduleHandlerTestSuite) TestCreate_InvalidCron() {
+	s.Equal(http.StatusBadRequest, s.request(http.MethodPost, "/flows/flow-1/schedules", `{"cron": "every day"}`).Code)
+	s.Equal(http.StatusBadRequest, s.request(http.MethodPost, "/flows/flow-1/schedules", `{}`).Code)
+}
+
+func (s *ScheduleHandlerTestSuite) TestDelete() {
+	recorder := s.request(http.MethodPost, "/flows/flow-1/schedules", `{"cron": "@hourly", "enabled": false}`)
+
+	var created scheduler.Schedule
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &created))
+	s.False(created.Enabled)
+	s.Nil(created.NextRunAt)
+
+	s.Equal(http.StatusNoContent, s.request(http.MethodDelete, "/schedules/"+created.Id, "").Code)
+	s.Equal(http.StatusNotFound, s.request(http.MethodGet, "/schedules/"+created.Id, "").Code)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yrn-go/yrn/module/secretmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+type (
+	SecretHandler struct {
+		secretService *secretmanager.SecretService
+	}
+
+	PutSecretRequest struct {
+		Value string `json:"value" binding:"required"`
+	}
+)
+
+func NewSecretHandler(secretService *secretmanager.SecretService) *SecretHandler {
+	return &SecretHandler{
+		secretService: secretService,
+	}
+}
+
+// Register adds the secrets routes. Secret values can be written but are
+// never returned.
+func (h *SecretHandler) Register(router gin.IRouter) {
+	router.GET("/secrets", h.list)
+	router.GET("/secrets/:name", h.get)
+	router.PUT("/secrets/:name", h.put)
+	router.DELETE("/secrets/:name", h.delete)
+}
+
+func (h *SecretHandler) list(c *gin.Context) {
+	ctx := yctx.NewContext(c.Request.Context())
+
+	secrets, err := h.secretService.List(ctx, secretTenant(c, ctx))
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	if secrets == nil {
+		secrets = []secretmanager.Secret{}
+	}
+
+	c.JSON(http.StatusOK, secrets)
+}
+
+func (h *SecretHandler) get(c *gin.Context) {
+	ctx := yctx.NewContext(c.Request.Context())
+
+	secret, err := h.secretService.Get(ctx, secretTenant(c, ctx), c.Param("name"))
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, secret)
+}
+
+func (h *SecretHandler) put(c *gin.Context) {
+	var request PutSecretRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx := yctx.NewContext(c.Request.Context())
+
+	secret, err := h.secretService.Put(ctx, secretTenant(c, ctx), c.Param("name"), request.Value)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, secret)
+}
+
+func (h *SecretHandler) delete(c *gin.Context) {
+	ctx := yctx.NewContext(c.Request.Context())
+
+	if err := h.secretService.Delete(ctx, secretTenant(c, ctx), c.Param("name")); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// secretTenant is the tenant of the request. The callers of every tenant
+// choose it with the tenant query parameter.
+func secretTenant(c *gin.Context, ctx *yctx.Context) string {
+	if ctx.HasAllTenants() {
+		return c.Query("tenant")
+	}
+
+	return ctx.Tenant()
+}
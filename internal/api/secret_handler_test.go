@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/module/secretmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestSecretHandler(t *testing.T) {
+	suite.Run(t, new(SecretHandlerTestSuite))
+}
+
+type SecretHandlerTestSuite struct {
+	suite.Suite
+	engine *gin.Engine
+}
+
+func (s *SecretHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	cipher, err := secretmanager.NewCipher(make([]byte, 32))
+	s.Require().NoError(err)
+
+	s.engine = gin.New()
+	s.engine.Use(Authenticate(NewHeaderAuthenticator()))
+
+	NewSecretHandler(
+		secretmanager.NewSecretService(secretmanager.NewInMemorySecretRepository(), cipher),
+	).Register(s.engine)
+}
+
+func (s *SecretHandlerTestSuite) request(method, path, tenant, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	request.Header.Set(HeaderTenantId, tenant)
+	s.engine.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (s *SecretHandlerTestSuite) TestPut_NeverReturnsValue() {
+	recorder := s.request(http.MethodPut, "/secrets/api-token", "tenant-a", `{"value": "my-token"}`)
+	s.Equal(http.StatusOK, recorder.Code)
+	s.NotContains(recorder.Body.String(), "my-token")
+
+	recorder = s.request(http.MethodGet, "/secrets/api-token", "tenant-a", "")
+	s.Equal(http.StatusOK, recorder.Code)
+	s.NotContains(recorder.Body.String(), "my-token")
+
+	var secret secretmanager.Secret
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &secret))
+	s.Equal("tenant-a", secret.Tenant)
+	s.Equal("api-token", secret.Name)
+}
+
+func (s *SecretHandlerTestSuite) TestSecretsAreScopedByTenant() {
+	s.Equal(http.StatusOK, s.request(http.MethodPut, "/secrets/api-token", "tenant-a", `{"value": "my-token"}`).Code)
+
+	s.Equal(http.StatusNotFound, s.request(http.MethodGet, "/secrets/api-token", "tenant-b", "").Code)
+	s.Equal(http.StatusNotFound, s.request(http.MethodDelete, "/secrets/api-token", "tenant-b", "").Code)
+	s.JSONEq(`[]`, s.request(http.MethodGet, "/secrets", "tenant-b", "").Body.String())
+
+	s.Equal(http.StatusNoContent, s.request(http.MethodDelete, "/secrets/api-token", "tenant-a", "").Code)
+	s.Equal(http.StatusNotFound, s.request(http.MethodGet, "/secrets/api-token", "tenant-a", "").Code)
+}
+
+func (s *SecretHandlerTestSuite) TestAllTenants_ChooseTenantInQuery() {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPut, "/secrets/api-token?tenant=tenant-a", bytes.NewBufferString(`{"value": "my-token"}`))
+	request.Header.Set(HeaderTenantId, yctx.AllTenants)
+	request.Header.Set(HeaderUserRoles, string(RoleAdmin))
+	s.engine.ServeHTTP(recorder, request)
+	s.Require().Equal(http.StatusOK, recorder.Code)
+
+	s.Equal(http.StatusOK, s.request(http.MethodGet, "/secrets/api-token", "tenant-a", "").Code)
+}
+
+func (s *SecretHandlerTestSuite) TestPut_InvalidRequest() {
+	s.Equal(http.StatusBadRequest, s.request(http.MethodPut, "/secrets/api-token", "tenant-a", `{}`).Code)
+	s.Equal(http.StatusBadRequest, s.request(http.MethodPut, "/secrets/bad%20name", "tenant-a", `{"value": "x"}`).Code)
+}
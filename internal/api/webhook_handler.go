@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yrn-go/yrn/module/webhook"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+type (
+	WebhookHandler struct {
+		webhookService *webhook.WebhookService
+	}
+
+	CreateWebhookRequest struct {
+		ResponseMode webhook.ResponseMode `json:"response_mode"`
+		Signature    *webhook.Signature   `json:"signature"`
+		Enabled      *bool                `json:"enabled"`
+	}
+
+	// WebhookResponse adds the path that triggers the webhook.
+	WebhookResponse struct {
+		*webhook.Webhook
+		Path string `json:"path"`
+	}
+)
+
+func NewWebhookHandler(webhookService *webhook.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) Register(router gin.IRouter) {
+	router.POST("/flows/:id/webhooks", h.create)
+	router.GET("/flows/:id/webhooks", h.listByFlow)
+	router.DELETE("/webhooks/:webhookId", h.delete)
+	router.Any("/hooks/:webhookId", h.trigger)
+}
+
+func (h *WebhookHandler) create(c *gin.Context) {
+	var request CreateWebhookRequest
+
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	item := &webhook.Webhook{
+		FlowId:       c.Param("id"),
+		ResponseMode: request.ResponseMode,
+		Signature:    request.Signature,
+		Enabled:      request.Enabled == nil || *request.Enabled,
+	}
+
+	if err := h.webhookService.Create(yctx.NewContext(c.Request.Context()), item); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newWebhookResponse(item))
+}
+
+func (h *WebhookHandler) listByFlow(c *gin.Context) {
+	items, err := h.webhookService.ListByFlow(yctx.NewContext(c.Request.Context()), c.Param("id"))
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response := make([]WebhookResponse, 0, len(items))
+	for i := range items {
+		response = append(response, newWebhookResponse(&items[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *WebhookHandler) delete(c *gin.Context) {
+	if err := h.webhookService.Delete(yctx.NewContext(c.Request.Context()), c.Param("webhookId")); err != nil {
+		renderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) trigger(c *gin.Context) {
+	request, err := webhook.NewRequest(c.Request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	response, mode, err := h.webhookService.Trigger(yctx.NewContext(c.Request.Context()), c.Param("webhookId"), request)
+	if err != nil {
+		renderError(c, err)
+		return
+	}
+
+	if mode == webhook.ResponseModeAsync {
+		c.Status(http.StatusAccepted)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func newWebhookResponse(item *webhook.Webhook) WebhookResponse {
+	return WebhookResponse{
+		Webhook: item,
+		Path:    "/hooks/" + item.Id,
+	}
+}
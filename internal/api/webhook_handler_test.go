@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/module/webhook"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	suite.Run(t, new(WebhookHandlerTestSuite))
+}
+
+type echoFlowRunnerStub struct{}
+
+func (f *echoFlowRunnerStub) Do(ctx *yctx.Context, flowId string, eventRequestData any) (any, error) {
+	return map[string]any{"flow_id": flowId, "event": eventRequestData}, nil
+}
+
+type secretResolverStub map[string]string
+
+func (s secretResolverStub) Resolve(ctx *yctx.Context, tenant, name string) (string, error) {
+	return s[tenant+"/"+name], nil
+}
+
+type WebhookHandlerTestSuite struct {
+	suite.Suite
+	engine *gin.Engine
+}
+
+func (s *WebhookHandlerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	s.engine = gin.New()
+	s.engine.Use(Authenticate(NewHeaderAuthenticator()))
+
+	NewWebhookHandler(
+		webhook.NewWebhookService(
+			webhook.NewInMemoryWebhookRepository(),
+			&echoFlowRunnerStub{},
+			secretResolverStub{"tenant-a/hook-key": "signing-key"},
+		),
+	).Register(s.engine)
+}
+
+func (s *WebhookHandlerTestSuite) request(method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	request.Header = header
+
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func (s *WebhookHandlerTestSuite) create(body string) WebhookResponse {
+	recorder := s.request(http.MethodPost, "/flows/flow-1/webhooks", body, http.Header{HeaderTenantId: {"tenant-a"}})
+	s.Require().Equal(http.StatusCreated, recorder.Code, recorder.Body.String())
+
+	var created WebhookResponse
+	s.Require().NoError(json.Unmarshal(recorder.Body.Bytes(), &created))
+
+	return created
+}
+
+func (s *WebhookHandlerTestSuite) TestTrigger_SyncReturnsFlowOutput() {
+	created := s.create(`{"response_mode": "sync"}`)
+	s.Equal("/hooks/"+created.Id, created.Path)
+
+	recorder := s.request(http.MethodPost, created.Path+"?source=test", `{"event": "push"}`, http.Header{"Content-Type": {"application/json"}})
+	s.Equal(http.StatusOK, recorder.Code)
+
+	var response map[string]any
+	s.NoError(json.Unmarshal(recorder.Body.Bytes(), &response))
+	s.Equal("flow-1", response["flow_id"])
+
+	event := response["event"].(map[string]any)
+	s.Equal(http.MethodPost, event["method"])
+	s.Equal(map[string]any{"event": "push"}, event["body"])
+	s.Equal(map[string]any{"source": "test"}, event["query"])
+}
+
+func (s *WebhookHandlerTestSuite) TestTrigger_AsyncReturnsAccepted() {
+	created := s.create(``)
+
+	s.Equal(http.StatusAccepted, s.request(http.MethodPost, created.Path, `{}`, http.Header{}).Code)
+}
+
+func (s *WebhookHandlerTestSuite) TestTrigger_VerifiesSignature() {
+	created := s.create(`{"response_mode": "sync", "signature": {"scheme": "github", "secret_name": "hook-key"}}`)
+
+	body := `{"action": "opened"}`
+	mac := hmac.New(sha256.New, []byte("signing-key"))
+	mac.Write([]byte(body))
+
+	s.Equal(http.StatusUnauthorized, s.request(http.MethodPost, created.Path, body, http.Header{
+		"X-Hub-Signature-256": {"sha256=" + hex.EncodeToString(make([]byte, sha256.Size))},
+	}).Code)
+
+	s.Equal(http.StatusOK, s.request(http.MethodPost, created.Path, body, http.Header{
+		"X-Hub-Signature-256": {"sha256=" + hex.EncodeToString(mac.Sum(nil))},
+	}).Code)
+}
+
+func (s *WebhookHandlerTestSuite) TestTrigger_SignatureWithoutSecrets() {
+	s.engine = gin.New()
+	s.engine.Use(Authenticate(NewHeaderAuthenticator()))
+
+	NewWebhookHandler(
+		webhook.NewWebhookService(webhook.NewInMemoryWebhookRepository(), &echoFlowRunnerStub{}, nil),
+	).Register(s.engine)
+
+	created := s.create(`{"response_mode": "sync", "signature": {"scheme": "github", "secret_name": "hook-key"}}`)
+
+	s.Equal(http.StatusServiceUnavailable, s.request(http.MethodPost, created.Path, `{}`, http.Header{
+		"X-Hub-Signature-256": {"sha256=" + hex.EncodeToString(make([]byte, sha256.Size))},
+	}).Code)
+}
+
+func (s *WebhookHandlerTestSuite) TestTrigger_UnknownOrDeletedWebhook() {
+	created := s.create(``)
+
+	tenantA := http.Header{HeaderTenantId: {"tenant-a"}}
+
+	// requests without tenant do not see the webhooks of the tenants.
+	s.Equal(http.StatusNotFound, s.request(http.MethodDelete, "/webhooks/"+created.Id, "", http.Header{}).Code)
+
+	s.Equal(http.StatusNoContent, s.request(http.MethodDelete, "/webhooks/"+created.Id, "", tenantA).Code)
+	s.Equal(http.StatusNotFound, s.request(http.MethodPost, created.Path, `{}`, http.Header{}).Code)
+	s.Equal(http.StatusNotFound, s.request(http.MethodDelete, "/webhooks/"+created.Id, "", tenantA).Code)
+}
+
+func (s *WebhookHandlerTestSuite) TestCreate_InvalidSignature() {
+	s.Equal(http.StatusBadRequest, s.request(http.MethodPost, "/flows/flow-1/webhooks", `{"signature": {"scheme": "md5", "secret_name": "hook-key"}}`, http.Header{}).Code)
+}
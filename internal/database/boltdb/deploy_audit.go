@@ -0,0 +1,43 @@
+package boltdb
+
+import (
+	"sort"
+
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	bolt "go.etcd.io/bbolt"
+)
+
+var _ flowmanager.DeployAuditRepository = (*DeployAuditRepository)(nil)
+
+type DeployAuditRepository struct {
+	store *Store
+}
+
+func NewDeployAuditRepository(store *Store) *DeployAuditRepository {
+	return &DeployAuditRepository{
+		store: store,
+	}
+}
+
+func (r *DeployAuditRepository) Save(ctx *yctx.Context, event *flowmanager.DeployEvent) error {
+	return r.store.db.Update(func(tx *bolt.Tx) error {
+		return put(tx, bucketDeployAudit, event.Id, event)
+	})
+}
+
+func (r *DeployAuditRepository) GetByFlowId(ctx *yctx.Context, flowId string) (items []flowmanager.DeployEvent, err error) {
+	err = r.store.db.View(func(tx *bolt.Tx) error {
+		items, err = list(tx, bucketDeployAudit, func(event *flowmanager.DeployEvent) bool {
+			return event.FlowId == flowId
+		})
+
+		return err
+	})
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].At.Before(items[j].At)
+	})
+
+	return
+}
@@ -0,0 +1,238 @@
+package boltdb
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	_ flowmanager.FlowReaderRepository = (*FlowRepository)(nil)
+	_ flowmanager.FlowWriteRepository  = (*FlowRepository)(nil)
+	_ flowmanager.FlowDeployRepository = (*FlowRepository)(nil)
+	_ flowmanager.FlowSearchRepository = (*FlowRepository)(nil)
+)
+
+type (
+	FlowRepository struct {
+		store *Store
+	}
+
+	// flowRecord keeps the insertion order, used to list the flows.
+	flowRecord struct {
+		Flow     flowmanager.Flow `json:"flow"`
+		Sequence uint64           `json:"sequence"`
+	}
+)
+
+func NewFlowRepository(store *Store) *FlowRepository {
+	return &FlowRepository{
+		store: store,
+	}
+}
+
+// Save stores the flow in the tenant of the context, or in its own tenant for
+// the contexts that access every tenant.
+func (f *FlowRepository) Save(ctx *yctx.Context, flow *flowmanager.Flow) error {
+	if !ctx.HasAllTenants() {
+		flow.Tenant = ctx.Tenant()
+	}
+
+	return f.store.db.Update(func(tx *bolt.Tx) error {
+		if exists(tx, bucketFlow, flow.Id) {
+			return fmt.Errorf("flow %s already exists", flow.Id)
+		}
+
+		sequence, err := tx.Bucket(bucketFlow).NextSequence()
+		if err != nil {
+			return err
+		}
+
+		return put(tx, bucketFlow, flow.Id, &flowRecord{Flow: *flow, Sequence: sequence})
+	})
+}
+
+// Update replaces every field of the flow but the tenant and the deployment
+// when the stored revision is expectedRevision.
+func (f *FlowRepository) Update(ctx *yctx.Context, flow *flowmanager.Flow, expectedRevision int) error {
+	return f.update(ctx, flow.Id, func(record *flowRecord) error {
+		if record.Flow.DeletedAt != nil {
+			return flowmanager.ErrFlowNotFound
+		}
+
+		if record.Flow.Revision != expectedRevision {
+			return flowmanager.ErrFlowVersionConflict
+		}
+
+		tenant, revision, deployment := record.Flow.Tenant, record.Flow.Revision, record.Flow.Deployment
+		record.Flow = *flow
+		record.Flow.Tenant = tenant
+		record.Flow.Revision = revision
+		record.Flow.Deployment = deployment
+		record.Flow.DeletedAt = nil
+
+		return nil
+	})
+}
+
+func (f *FlowRepository) Delete(ctx *yctx.Context, id string) error {
+	return f.update(ctx, id, func(record *flowRecord) error {
+		if record.Flow.DeletedAt != nil {
+			return flowmanager.ErrFlowNotFound
+		}
+
+		deletedAt := time.Now().UTC()
+		record.Flow.DeletedAt = &deletedAt
+		record.Flow.UpdatedAt = deletedAt
+
+		return nil
+	})
+}
+
+func (f *FlowRepository) Restore(ctx *yctx.Context, id string) error {
+	return f.update(ctx, id, func(record *flowRecord) error {
+		if record.Flow.DeletedAt == nil {
+			return flowmanager.ErrFlowNotFound
+		}
+
+		record.Flow.DeletedAt = nil
+		record.Flow.UpdatedAt = time.Now().UTC()
+
+		return nil
+	})
+}
+
+func (f *FlowRepository) SaveDeployment(ctx *yctx.Context, flowId string, expected ybase.DeployStatus, deployment *flowmanager.FlowDeployment) error {
+	err := f.update(ctx, flowId, func(record *flowRecord) error {
+		if record.Flow.DeployStatus() != expected {
+			return flowmanager.ErrDeployConflict
+		}
+
+		record.Flow.Deployment = deployment
+
+		return nil
+	})
+	if errors.Is(err, flowmanager.ErrFlowNotFound) {
+		return flowmanager.ErrDeployConflict
+	}
+
+	return err
+}
+
+func (f *FlowRepository) GetById(ctx *yctx.Context, id string) (item *flowmanager.Flow, err error) {
+	err = f.store.db.View(func(tx *bolt.Tx) error {
+		var record flowRecord
+
+		found, err := get(tx, bucketFlow, id, &record)
+		if err != nil || !found || record.Flow.DeletedAt != nil || !ctx.CanAccess(record.Flow.Tenant) {
+			return err
+		}
+
+		item = &record.Flow
+
+		return nil
+	})
+
+	return
+}
+
+func (f *FlowRepository) GetAll(ctx *yctx.Context, pagination *flowmanager.Pagination) ([]flowmanager.Flow, error) {
+	flows, err := f.find(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(flows, pagination), nil
+}
+
+func (f *FlowRepository) GetDeleted(ctx *yctx.Context, pagination *flowmanager.Pagination) ([]flowmanager.Flow, error) {
+	flows, err := f.find(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(flows, pagination), nil
+}
+
+// Search reads every flow that is not deleted and filters them in memory.
+func (f *FlowRepository) Search(ctx *yctx.Context, query *flowmanager.FlowQuery) ([]flowmanager.Flow, int, error) {
+	flows, err := f.find(ctx, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items, total := flowmanager.SearchFlows(flows, query)
+
+	return items, total, nil
+}
+
+func (f *FlowRepository) Count(ctx *yctx.Context) (int, error) {
+	flows, err := f.find(ctx, false)
+
+	return len(flows), err
+}
+
+// update changes the record of the flow in a transaction, so the checks of
+// change and the write are atomic, and increments its revision. The flows of
+// other tenants are not found.
+func (f *FlowRepository) update(ctx *yctx.Context, id string, change func(record *flowRecord) error) error {
+	return f.store.db.Update(func(tx *bolt.Tx) error {
+		var record flowRecord
+
+		found, err := get(tx, bucketFlow, id, &record)
+		if err != nil {
+			return err
+		}
+
+		if !found || !ctx.CanAccess(record.Flow.Tenant) {
+			return flowmanager.ErrFlowNotFound
+		}
+
+		if err = change(&record); err != nil {
+			return err
+		}
+
+		record.Flow.Revision++
+
+		return put(tx, bucketFlow, id, &record)
+	})
+}
+
+// find returns the deleted or not deleted flows of the tenant of the context
+// in insertion order.
+func (f *FlowRepository) find(ctx *yctx.Context, deleted bool) (flows []flowmanager.Flow, err error) {
+	err = f.store.db.View(func(tx *bolt.Tx) error {
+		records, err := list(tx, bucketFlow, func(record *flowRecord) bool {
+			return (record.Flow.DeletedAt != nil) == deleted && ctx.CanAccess(record.Flow.Tenant)
+		})
+		if err != nil {
+			return err
+		}
+
+		sort.Slice(records, func(i, j int) bool {
+			return records[i].Sequence < records[j].Sequence
+		})
+
+		flows = make([]flowmanager.Flow, 0, len(records))
+		for _, record := range records {
+			flows = append(flows, record.Flow)
+		}
+
+		return nil
+	})
+
+	return
+}
+
+func paginate[T any](items []T, pagination *flowmanager.Pagination) []T {
+	start := min(pagination.Page*pagination.Size, len(items))
+	end := min(start+pagination.Size, len(items))
+
+	return items[start:end]
+}
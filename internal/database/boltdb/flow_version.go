@@ -0,0 +1,63 @@
+package boltdb
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	bolt "go.etcd.io/bbolt"
+)
+
+var _ flowmanager.FlowVersionRepository = (*FlowVersionRepository)(nil)
+
+type FlowVersionRepository struct {
+	store *Store
+}
+
+func NewFlowVersionRepository(store *Store) *FlowVersionRepository {
+	return &FlowVersionRepository{
+		store: store,
+	}
+}
+
+func (r *FlowVersionRepository) Save(ctx *yctx.Context, version *flowmanager.FlowVersion) error {
+	return r.store.db.Update(func(tx *bolt.Tx) error {
+		if exists(tx, bucketFlowVersion, version.Id) {
+			return fmt.Errorf("%w: %s", flowmanager.ErrFlowVersionExists, version.Id)
+		}
+
+		return put(tx, bucketFlowVersion, version.Id, version)
+	})
+}
+
+func (r *FlowVersionRepository) GetByVersion(ctx *yctx.Context, flowId string, version int) (item *flowmanager.FlowVersion, err error) {
+	err = r.store.db.View(func(tx *bolt.Tx) error {
+		var flowVersion flowmanager.FlowVersion
+
+		found, err := get(tx, bucketFlowVersion, fmt.Sprintf("%s@%d", flowId, version), &flowVersion)
+		if found {
+			item = &flowVersion
+		}
+
+		return err
+	})
+
+	return
+}
+
+func (r *FlowVersionRepository) GetByFlowId(ctx *yctx.Context, flowId string) (items []flowmanager.FlowVersion, err error) {
+	err = r.store.db.View(func(tx *bolt.Tx) error {
+		items, err = list(tx, bucketFlowVersion, func(version *flowmanager.FlowVersion) bool {
+			return version.FlowId == flowId
+		})
+
+		return err
+	})
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Version < items[j].Version
+	})
+
+	return
+}
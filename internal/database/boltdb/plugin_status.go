@@ -0,0 +1,54 @@
+package boltdb
+
+import (
+	"fmt"
+
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	bolt "go.etcd.io/bbolt"
+)
+
+var _ flowmanager.PluginStatusRepository = (*PluginStatusRepository)(nil)
+
+// PluginStatusRepository keeps the status of each plugin in each execution,
+// like flowmanager.RedisPluginStatusRepository but without expiration.
+type PluginStatusRepository struct {
+	store *Store
+}
+
+func NewPluginStatusRepository(store *Store) *PluginStatusRepository {
+	return &PluginStatusRepository{
+		store: store,
+	}
+}
+
+func (r *PluginStatusRepository) Save(ctx *yctx.Context, status flowmanager.PluginStatus) error {
+	return r.store.db.Update(func(tx *bolt.Tx) error {
+		return put(tx, bucketPluginStatus, status.Key(), &status)
+	})
+}
+
+func (r *PluginStatusRepository) GetByPluginID(ctx *yctx.Context, pluginID string) (status flowmanager.PluginStatus, err error) {
+	err = r.store.db.View(func(tx *bolt.Tx) error {
+		found, err := get(tx, bucketPluginStatus, flowmanager.PluginStatusKey(ctx.Tenant(), ctx.FlowId(), ctx.ExecutionId(), pluginID), &status)
+		if err == nil && !found {
+			return fmt.Errorf("plugin status not found for ID: %s", pluginID)
+		}
+
+		return err
+	})
+
+	return
+}
+
+func (r *PluginStatusRepository) GetAll(ctx *yctx.Context) (statuses []flowmanager.PluginStatus, err error) {
+	err = r.store.db.View(func(tx *bolt.Tx) error {
+		statuses, err = list(tx, bucketPluginStatus, func(status *flowmanager.PluginStatus) bool {
+			return ctx.CanAccess(status.Tenant)
+		})
+
+		return err
+	})
+
+	return
+}
@@ -0,0 +1,96 @@
+package boltdb
+
+import (
+	"sort"
+
+	"github.com/yrn-go/yrn/module/scheduler"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	bolt "go.etcd.io/bbolt"
+)
+
+var _ scheduler.ScheduleRepository = (*ScheduleRepository)(nil)
+
+type ScheduleRepository struct {
+	store *Store
+}
+
+func NewScheduleRepository(store *Store) *ScheduleRepository {
+	return &ScheduleRepository{
+		store: store,
+	}
+}
+
+func (r *ScheduleRepository) Save(ctx *yctx.Context, schedule *scheduler.Schedule) error {
+	record := *schedule
+	record.NextRunAt = nil
+
+	return r.store.db.Update(func(tx *bolt.Tx) error {
+		return put(tx, bucketSchedule, schedule.Id, &record)
+	})
+}
+
+func (r *ScheduleRepository) GetById(ctx *yctx.Context, id string) (item *scheduler.Schedule, err error) {
+	err = r.store.db.View(func(tx *bolt.Tx) error {
+		var schedule scheduler.Schedule
+
+		found, err := get(tx, bucketSchedule, id, &schedule)
+		if found {
+			item = &schedule
+		}
+
+		return err
+	})
+
+	return
+}
+
+func (r *ScheduleRepository) GetAll(ctx *yctx.Context) ([]scheduler.Schedule, error) {
+	return r.find(func(*scheduler.Schedule) bool { return true })
+}
+
+func (r *ScheduleRepository) GetByFlowId(ctx *yctx.Context, flowId string) ([]scheduler.Schedule, error) {
+	return r.find(func(schedule *scheduler.Schedule) bool { return schedule.FlowId == flowId })
+}
+
+func (r *ScheduleRepository) Delete(ctx *yctx.Context, id string) error {
+	return r.store.db.Update(func(tx *bolt.Tx) error {
+		if !exists(tx, bucketSchedule, id) {
+			return scheduler.ErrScheduleNotFound
+		}
+
+		return tx.Bucket(bucketSchedule).Delete([]byte(id))
+	})
+}
+
+func (r *ScheduleRepository) SaveRun(ctx *yctx.Context, id string, run scheduler.ScheduleRun) error {
+	return r.store.db.Update(func(tx *bolt.Tx) error {
+		var schedule scheduler.Schedule
+
+		found, err := get(tx, bucketSchedule, id, &schedule)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return scheduler.ErrScheduleNotFound
+		}
+
+		schedule.LastRun = &run
+
+		return put(tx, bucketSchedule, id, &schedule)
+	})
+}
+
+func (r *ScheduleRepository) find(match func(schedule *scheduler.Schedule) bool) (items []scheduler.Schedule, err error) {
+	err = r.store.db.View(func(tx *bolt.Tx) error {
+		items, err = list(tx, bucketSchedule, match)
+
+		return err
+	})
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+
+	return
+}
@@ -0,0 +1,103 @@
+package boltdb
+
+import (
+	"sort"
+
+	"github.com/yrn-go/yrn/module/secretmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	bolt "go.etcd.io/bbolt"
+)
+
+var _ secretmanager.SecretRepository = (*SecretRepository)(nil)
+
+type (
+	SecretRepository struct {
+		store *Store
+	}
+
+	// secretRecord stores the encrypted value, which Secret never
+	// serializes to JSON.
+	secretRecord struct {
+		secretmanager.Secret
+		EncryptedValue []byte `json:"encrypted_value"`
+	}
+)
+
+func NewSecretRepository(store *Store) *SecretRepository {
+	return &SecretRepository{
+		store: store,
+	}
+}
+
+// secretKey groups the secrets of a tenant. Names never contain a '/'.
+func secretKey(tenant, name string) string {
+	return tenant + "/" + name
+}
+
+func (r *SecretRepository) Save(ctx *yctx.Context, secret *secretmanager.Secret) error {
+	return r.store.db.Update(func(tx *bolt.Tx) error {
+		return put(tx, bucketSecret, secretKey(secret.Tenant, secret.Name), &secretRecord{
+			Secret:         *secret,
+			EncryptedValue: secret.EncryptedValue,
+		})
+	})
+}
+
+func (r *SecretRepository) GetByName(ctx *yctx.Context, tenant, name string) (item *secretmanager.Secret, err error) {
+	err = r.store.db.View(func(tx *bolt.Tx) error {
+		var record secretRecord
+
+		found, err := get(tx, bucketSecret, secretKey(tenant, name), &record)
+		if found {
+			item = record.secret()
+		}
+
+		return err
+	})
+
+	return
+}
+
+func (r *SecretRepository) GetAll(ctx *yctx.Context, tenant string) (items []secretmanager.Secret, err error) {
+	var records []secretRecord
+
+	err = r.store.db.View(func(tx *bolt.Tx) error {
+		records, err = list(tx, bucketSecret, func(record *secretRecord) bool {
+			return record.Tenant == tenant
+		})
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items = make([]secretmanager.Secret, 0, len(records))
+	for _, record := range records {
+		items = append(items, *record.secret())
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+
+	return items, nil
+}
+
+func (r *SecretRepository) Delete(ctx *yctx.Context, tenant, name string) error {
+	return r.store.db.Update(func(tx *bolt.Tx) error {
+		key := secretKey(tenant, name)
+		if !exists(tx, bucketSecret, key) {
+			return secretmanager.ErrSecretNotFound
+		}
+
+		return tx.Bucket(bucketSecret).Delete([]byte(key))
+	})
+}
+
+func (r *secretRecord) secret() *secretmanager.Secret {
+	secret := r.Secret
+	secret.EncryptedValue = r.EncryptedValue
+
+	return &secret
+}
@@ -0,0 +1,113 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	EnvBoltPath = "BOLT_PATH"
+
+	DefaultPath = "yrn.db"
+)
+
+var (
+	bucketFlow         = []byte("flow")
+	bucketFlowVersion  = []byte("flow_version")
+	bucketDeployAudit  = []byte("flow_deploy_audit")
+	bucketPluginStatus = []byte("plugin_status")
+	bucketSchedule     = []byte("schedule")
+	bucketWebhook      = []byte("webhook")
+	bucketSecret       = []byte("secret")
+)
+
+// Store is a single file database for running every component in one
+// process. Each repository keeps its records as JSON in its own bucket.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens or creates the database file. Only one process can open the
+// file at a time.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{
+			bucketFlow, bucketFlowVersion, bucketDeployAudit, bucketPluginStatus,
+			bucketSchedule, bucketWebhook, bucketSecret,
+		} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Ping returns bolt.ErrDatabaseNotOpen after Close, used by the health probe.
+func (s *Store) Ping(ctx *yctx.Context) error {
+	return s.db.View(func(*bolt.Tx) error { return nil })
+}
+
+// get decodes the record of key into item and reports whether it exists.
+func get(tx *bolt.Tx, bucket []byte, key string, item any) (bool, error) {
+	data := tx.Bucket(bucket).Get([]byte(key))
+	if data == nil {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(data, item)
+}
+
+func put(tx *bolt.Tx, bucket []byte, key string, item any) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(bucket).Put([]byte(key), data)
+}
+
+// list decodes the records of the bucket, in key order, and keeps the ones
+// accepted by match.
+func list[T any](tx *bolt.Tx, bucket []byte, match func(item *T) bool) ([]T, error) {
+	items := make([]T, 0)
+
+	err := tx.Bucket(bucket).ForEach(func(key, data []byte) error {
+		var item T
+		if err := json.Unmarshal(data, &item); err != nil {
+			return err
+		}
+
+		if match(&item) {
+			items = append(items, item)
+		}
+
+		return nil
+	})
+
+	return items, err
+}
+
+// exists reports whether the bucket has a record for key.
+func exists(tx *bolt.Tx, bucket []byte, key string) bool {
+	return tx.Bucket(bucket).Get([]byte(key)) != nil
+}
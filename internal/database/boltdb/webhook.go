@@ -0,0 +1,68 @@
+package boltdb
+
+import (
+	"sort"
+
+	"github.com/yrn-go/yrn/module/webhook"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	bolt "go.etcd.io/bbolt"
+)
+
+var _ webhook.WebhookRepository = (*WebhookRepository)(nil)
+
+type WebhookRepository struct {
+	store *Store
+}
+
+func NewWebhookRepository(store *Store) *WebhookRepository {
+	return &WebhookRepository{
+		store: store,
+	}
+}
+
+func (r *WebhookRepository) Save(ctx *yctx.Context, item *webhook.Webhook) error {
+	return r.store.db.Update(func(tx *bolt.Tx) error {
+		return put(tx, bucketWebhook, item.Id, item)
+	})
+}
+
+func (r *WebhookRepository) GetById(ctx *yctx.Context, id string) (item *webhook.Webhook, err error) {
+	err = r.store.db.View(func(tx *bolt.Tx) error {
+		var stored webhook.Webhook
+
+		found, err := get(tx, bucketWebhook, id, &stored)
+		if found {
+			item = &stored
+		}
+
+		return err
+	})
+
+	return
+}
+
+func (r *WebhookRepository) GetByFlowId(ctx *yctx.Context, flowId string) (items []webhook.Webhook, err error) {
+	err = r.store.db.View(func(tx *bolt.Tx) error {
+		items, err = list(tx, bucketWebhook, func(item *webhook.Webhook) bool {
+			return item.FlowId == flowId
+		})
+
+		return err
+	})
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+
+	return
+}
+
+func (r *WebhookRepository) Delete(ctx *yctx.Context, id string) error {
+	return r.store.db.Update(func(tx *bolt.Tx) error {
+		if !exists(tx, bucketWebhook, id) {
+			return webhook.ErrWebhookNotFound
+		}
+
+		return tx.Bucket(bucketWebhook).Delete([]byte(id))
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/yrn-go/yrn/pkg/yctx"
 	"go.mongodb.org/mongo-driver/mongo"
 	mongoOption "go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 const (
@@ -19,35 +20,47 @@ const (
 	EnvMongoDatabase = "MONGO_DATABASE"
 )
 
-func GetCollection(ctx *yctx.Context, collectionName string) (collection *mongo.Collection, err error) {
-	var (
-		mongoDatabase *mongo.Database
-	)
-
-	mongoDatabase, err = getDatabase(ctx)
-	if err != nil {
-		return
-	}
-
-	return mongoDatabase.Collection(collectionName), nil
+// Client is the connection pool shared by every repository. It is created
+// once with NewClient and closed with Disconnect on shutdown.
+type Client struct {
+	client   *mongo.Client
+	database *mongo.Database
 }
 
-func getDatabase(ctx *yctx.Context) (database *mongo.Database, err error) {
+// NewClient connects to MONGO_URL and uses the database MONGO_DATABASE.
+func NewClient(ctx *yctx.Context) (*Client, error) {
 	databaseName := os.Getenv(EnvMongoDatabase)
 	if databaseName == "" {
 		return nil, errors.New("missing environment variable: " + EnvMongoDatabase)
 	}
 
-	var mongoClient *mongo.Client
-	mongoClient, err = getClient(ctx)
+	mongoClient, err := connect(ctx)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	return mongoClient.Database(databaseName), nil
+	return &Client{
+		client:   mongoClient,
+		database: mongoClient.Database(databaseName),
+	}, nil
 }
 
-func getClient(ctx *yctx.Context) (mongoClient *mongo.Client, err error) {
+func (c *Client) Collection(name string) *mongo.Collection {
+	return c.database.Collection(name)
+}
+
+// Ping checks the connection with the primary, used by the health probe.
+func (c *Client) Ping(ctx *yctx.Context) error {
+	return c.client.Ping(ctx.Context(), readpref.Primary())
+}
+
+// Disconnect waits for the operations in progress and closes the
+// connections.
+func (c *Client) Disconnect(ctx *yctx.Context) error {
+	return c.client.Disconnect(ctx.Context())
+}
+
+func connect(ctx *yctx.Context) (mongoClient *mongo.Client, err error) {
 	var (
 		mongoURI      *string
 		tlsConfigData *tls.Config
@@ -64,7 +77,9 @@ func getClient(ctx *yctx.Context) (mongoClient *mongo.Client, err error) {
 		SetSocketTimeout(30 * time.Second).
 		SetMaxPoolSize(100).
 		SetMinPoolSize(10).
-		SetMaxConnIdleTime(5 * time.Minute)
+		SetMaxConnIdleTime(5 * time.Minute).
+		// Documentos dentro de campos any, como Input dos triggers, são lidos como map
+		SetBSONOptions(&mongoOption.BSONOptions{DefaultDocumentM: true})
 
 	if tlsConfigData != nil {
 		mongoOptions.SetTLSConfig(tlsConfigData)
@@ -76,9 +91,10 @@ func getClient(ctx *yctx.Context) (mongoClient *mongo.Client, err error) {
 	}
 
 	// Verifica a conexão
-	err = mongoClient.Ping(ctx.Context(), nil)
+	err = mongoClient.Ping(ctx.Context(), readpref.Primary())
 	if err != nil {
-		return
+		_ = mongoClient.Disconnect(ctx.Context())
+		return nil, err
 	}
 
 	return mongoClient, nil
@@ -0,0 +1,67 @@
+package mongodb
+
+import (
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mongoOptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	CollectionDeployAuditName = "flow_deploy_audit"
+)
+
+var (
+	_ flowmanager.DeployAuditRepository = (*DeployAuditRepository)(nil)
+)
+
+type (
+	DeployAuditRepository struct {
+		client *Client
+	}
+)
+
+func NewDeployAuditRepository(client *Client) *DeployAuditRepository {
+	return &DeployAuditRepository{
+		client: client,
+	}
+}
+
+func (d *DeployAuditRepository) Save(ctx *yctx.Context, event *flowmanager.DeployEvent) (err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = d.client.Collection(CollectionDeployAuditName)
+
+	_, err = collection.InsertOne(ctx.Context(), event)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (d *DeployAuditRepository) GetByFlowId(ctx *yctx.Context, flowId string) (items []flowmanager.DeployEvent, err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = d.client.Collection(CollectionDeployAuditName)
+
+	options := mongoOptions.Find().SetSort(bson.D{{Key: "at", Value: 1}})
+
+	cursor, err := collection.Find(ctx.Context(), bson.M{"flow_id": flowId}, options)
+	if err != nil {
+		return
+	}
+	defer cursor.Close(ctx.Context())
+
+	err = cursor.All(ctx.Context(), &items)
+	if err != nil {
+		return
+	}
+
+	return items, nil
+}
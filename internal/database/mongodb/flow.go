@@ -1,7 +1,12 @@
 package mongodb
 
 import (
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/ybase"
 	"github.com/yrn-go/yrn/pkg/yctx"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -15,42 +20,200 @@ const (
 var (
 	_ flowmanager.FlowReaderRepository = (*FlowRepository)(nil)
 	_ flowmanager.FlowWriteRepository  = (*FlowRepository)(nil)
+	_ flowmanager.FlowDeployRepository = (*FlowRepository)(nil)
+	_ flowmanager.FlowSearchRepository = (*FlowRepository)(nil)
 )
 
 type (
 	FlowRepository struct {
+		client *Client
 	}
 )
 
+func NewFlowRepository(client *Client) *FlowRepository {
+	return &FlowRepository{
+		client: client,
+	}
+}
+
+// CreateIndexes creates the indexes of the flow listings, filtered by tenant
+// and sorted by creation, name or last update. It is called on startup and
+// does nothing when the indexes exist.
+func (f *FlowRepository) CreateIndexes(ctx *yctx.Context) (err error) {
+	_, err = f.client.Collection(CollectionFlowName).Indexes().CreateMany(ctx.Context(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "name", Value: 1}}},
+		{Keys: bson.D{{Key: "tenant", Value: 1}, {Key: "updated_at", Value: -1}}},
+		{Keys: bson.D{{Key: "tags", Value: 1}}},
+	})
+
+	return
+}
+
+// notDeleted is the filter of the flows that are not soft deleted.
+func notDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = bson.M{"$exists": false}
+	return filter
+}
+
+// revision is the filter of the flows at revision. The flows saved before
+// revisions existed have no field and are at revision 0.
+func revision(filter bson.M, revision int) bson.M {
+	if revision == 0 {
+		filter["revision"] = bson.M{"$in": bson.A{0, nil}}
+	} else {
+		filter["revision"] = revision
+	}
+
+	return filter
+}
+
+// incRevision is added to every update of a flow.
+var incRevision = bson.M{"revision": 1}
+
+// scoped restricts the filter to the tenant of the context, unless it
+// accesses every tenant.
+func scoped(ctx *yctx.Context, filter bson.M) bson.M {
+	if !ctx.HasAllTenants() {
+		filter["tenant"] = ctx.Tenant()
+	}
+
+	return filter
+}
+
+// Save generates the id of the flow when it is empty and stores the flow in
+// the tenant of the context, or in its own tenant for the contexts that access
+// every tenant.
 func (f *FlowRepository) Save(ctx *yctx.Context, flow *flowmanager.Flow) (err error) {
 	var (
 		collection *mongo.Collection
 	)
 
-	collection, err = GetCollection(ctx, CollectionFlowName)
+	collection = f.client.Collection(CollectionFlowName)
+
+	if flow.Id == "" {
+		flow.Id = uuid.NewString()
+	}
+
+	if !ctx.HasAllTenants() {
+		flow.Tenant = ctx.Tenant()
+	}
+
+	_, err = collection.InsertOne(ctx.Context(), flow)
 	if err != nil {
 		return
 	}
 
-	_, err = collection.InsertOne(ctx.Context(), flow)
+	return
+}
+
+// Update sets every field of the flow but the deployment when the stored
+// revision is expectedRevision.
+func (f *FlowRepository) Update(ctx *yctx.Context, flow *flowmanager.Flow, expectedRevision int) (err error) {
+	var (
+		collection *mongo.Collection
+		definition bson.M
+		data       []byte
+		result     *mongo.UpdateResult
+		count      int64
+	)
+
+	collection = f.client.Collection(CollectionFlowName)
+
+	data, err = bson.Marshal(flow)
 	if err != nil {
 		return
 	}
 
+	if err = bson.Unmarshal(data, &definition); err != nil {
+		return
+	}
+
+	delete(definition, "_id")
+	delete(definition, "tenant")
+	delete(definition, "created_at")
+	delete(definition, "deployment")
+	delete(definition, "deleted_at")
+	delete(definition, "revision")
+
+	filter := scoped(ctx, notDeleted(revision(bson.M{"_id": flow.Id}, expectedRevision)))
+	result, err = collection.UpdateOne(ctx.Context(), filter, bson.M{"$set": definition, "$inc": incRevision})
+	if err != nil {
+		return
+	}
+
+	if result.MatchedCount > 0 {
+		return
+	}
+
+	count, err = collection.CountDocuments(ctx.Context(), scoped(ctx, notDeleted(bson.M{"_id": flow.Id})))
+	if err != nil {
+		return
+	}
+
+	if count == 0 {
+		return flowmanager.ErrFlowNotFound
+	}
+
+	return flowmanager.ErrFlowVersionConflict
+}
+
+func (f *FlowRepository) Delete(ctx *yctx.Context, id string) (err error) {
+	var (
+		collection *mongo.Collection
+		result     *mongo.UpdateResult
+	)
+
+	collection = f.client.Collection(CollectionFlowName)
+
+	now := time.Now().UTC()
+	update := bson.M{"$set": bson.M{"deleted_at": now, "updated_at": now}, "$inc": incRevision}
+	result, err = collection.UpdateOne(ctx.Context(), scoped(ctx, notDeleted(bson.M{"_id": id})), update)
+	if err != nil {
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		return flowmanager.ErrFlowNotFound
+	}
+
 	return
 }
 
-func (f *FlowRepository) GetById(ctx *yctx.Context, id string) (item *flowmanager.Flow, err error) {
+func (f *FlowRepository) Restore(ctx *yctx.Context, id string) (err error) {
 	var (
 		collection *mongo.Collection
+		result     *mongo.UpdateResult
 	)
 
-	collection, err = GetCollection(ctx, CollectionFlowName)
+	collection = f.client.Collection(CollectionFlowName)
+
+	filter := scoped(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$exists": true}})
+	update := bson.M{
+		"$set":   bson.M{"updated_at": time.Now().UTC()},
+		"$unset": bson.M{"deleted_at": ""},
+		"$inc":   incRevision,
+	}
+	result, err = collection.UpdateOne(ctx.Context(), filter, update)
 	if err != nil {
 		return
 	}
 
-	filter := bson.M{"_id": id}
+	if result.MatchedCount == 0 {
+		return flowmanager.ErrFlowNotFound
+	}
+
+	return
+}
+
+func (f *FlowRepository) GetById(ctx *yctx.Context, id string) (item *flowmanager.Flow, err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = f.client.Collection(CollectionFlowName)
+
+	filter := scoped(ctx, notDeleted(bson.M{"_id": id}))
 	result := collection.FindOne(ctx.Context(), filter)
 	if result.Err() != nil {
 		if result.Err() == mongo.ErrNoDocuments {
@@ -69,20 +232,120 @@ func (f *FlowRepository) GetById(ctx *yctx.Context, id string) (item *flowmanage
 }
 
 func (f *FlowRepository) GetAll(ctx *yctx.Context, pagination *flowmanager.Pagination) (items []flowmanager.Flow, err error) {
+	return f.find(ctx, scoped(ctx, notDeleted(bson.M{})), pagination)
+}
+
+func (f *FlowRepository) GetDeleted(ctx *yctx.Context, pagination *flowmanager.Pagination) (items []flowmanager.Flow, err error) {
+	return f.find(ctx, scoped(ctx, bson.M{"deleted_at": bson.M{"$exists": true}}), pagination)
+}
+
+func (f *FlowRepository) Search(ctx *yctx.Context, query *flowmanager.FlowQuery) (items []flowmanager.Flow, total int, err error) {
 	var (
 		collection *mongo.Collection
+		count      int64
+		conditions bson.A
 	)
 
-	collection, err = GetCollection(ctx, CollectionFlowName)
+	collection = f.client.Collection(CollectionFlowName)
+
+	filter := scoped(ctx, notDeleted(bson.M{}))
+
+	if query.Tenant != "" {
+		conditions = append(conditions, bson.M{"tenant": query.Tenant})
+	}
+
+	if query.NamePrefix != "" {
+		filter["name"] = bson.M{"$regex": "^" + regexp.QuoteMeta(query.NamePrefix)}
+	}
+
+	if query.PluginSlug != "" {
+		filter["plugins.slug"] = query.PluginSlug
+	}
+
+	if query.DeployStatus == ybase.DeployStatusPending {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"deployment": nil},
+			bson.M{"deployment.status": query.DeployStatus},
+		}})
+	} else if query.DeployStatus != "" {
+		filter["deployment.status"] = query.DeployStatus
+	}
+
+	if len(query.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": query.Tags}
+	}
+
+	// each word is a case insensitive substring of the name, the description
+	// or a tag, like SearchFlows.
+	for _, word := range query.TextWords() {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(word), "$options": "i"}
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"name": pattern},
+			bson.M{"description": pattern},
+			bson.M{"tags": pattern},
+		}})
+	}
+
+	if len(conditions) > 0 {
+		filter["$and"] = conditions
+	}
+
+	count, err = collection.CountDocuments(ctx.Context(), filter)
 	if err != nil {
 		return
 	}
 
+	field, descending := query.SortField()
+	direction, operator := 1, "$gt"
+	if descending {
+		direction, operator = -1, "$lt"
+	}
+
+	if query.After != nil {
+		var value any = query.After.Name
+		if field == string(flowmanager.FlowSortUpdated) {
+			value = query.After.UpdatedAt
+		}
+
+		filter["$and"] = append(conditions, bson.M{"$or": bson.A{
+			bson.M{field: bson.M{operator: value}},
+			bson.M{field: value, "_id": bson.M{operator: query.After.Id}},
+		}})
+	}
+
+	options := mongoOptions.Find().
+		SetSort(bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}).
+		SetSkip(int64(query.Page * query.Size)).
+		SetLimit(int64(query.Size))
+
+	cursor, err := collection.Find(ctx.Context(), filter, options)
+	if err != nil {
+		return
+	}
+	defer cursor.Close(ctx.Context())
+
+	items = make([]flowmanager.Flow, 0, query.Size)
+	if err = cursor.All(ctx.Context(), &items); err != nil {
+		return
+	}
+
+	return items, int(count), nil
+}
+
+func (f *FlowRepository) find(ctx *yctx.Context, filter bson.M, pagination *flowmanager.Pagination) (items []flowmanager.Flow, err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = f.client.Collection(CollectionFlowName)
+
+	// the ids are random, so the flows are listed in creation order.
 	options := mongoOptions.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
 		SetSkip(int64(pagination.Page * pagination.Size)).
 		SetLimit(int64(pagination.Size))
 
-	cursor, err := collection.Find(ctx.Context(), bson.M{}, options)
+	cursor, err := collection.Find(ctx.Context(), filter, options)
 	if err != nil {
 		return
 	}
@@ -102,15 +365,42 @@ func (f *FlowRepository) Count(ctx *yctx.Context) (total int, err error) {
 		count      int64
 	)
 
-	collection, err = GetCollection(ctx, CollectionFlowName)
+	collection = f.client.Collection(CollectionFlowName)
+
+	count, err = collection.CountDocuments(ctx.Context(), scoped(ctx, notDeleted(bson.M{})))
 	if err != nil {
 		return
 	}
 
-	count, err = collection.CountDocuments(ctx.Context(), bson.M{})
+	return int(count), nil
+}
+
+func (f *FlowRepository) SaveDeployment(ctx *yctx.Context, flowId string, expected ybase.DeployStatus, deployment *flowmanager.FlowDeployment) (err error) {
+	var (
+		collection *mongo.Collection
+		result     *mongo.UpdateResult
+	)
+
+	collection = f.client.Collection(CollectionFlowName)
+
+	filter := bson.M{"_id": flowId, "deployment.status": expected}
+	if expected == ybase.DeployStatusPending {
+		filter = bson.M{"_id": flowId, "$or": bson.A{
+			bson.M{"deployment": nil},
+			bson.M{"deployment.status": expected},
+		}}
+	}
+
+	filter = scoped(ctx, filter)
+
+	result, err = collection.UpdateOne(ctx.Context(), filter, bson.M{"$set": bson.M{"deployment": deployment}, "$inc": incRevision})
 	if err != nil {
 		return
 	}
 
-	return int(count), nil
+	if result.MatchedCount == 0 {
+		return flowmanager.ErrDeployConflict
+	}
+
+	return
 }
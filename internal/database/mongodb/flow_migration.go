@@ -0,0 +1,119 @@
+package mongodb
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoOptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var flowType = reflect.TypeOf(flowmanager.Flow{})
+
+// MigrateLegacyFlows rewrites the flows saved before the fields of Flow were
+// mapped to bson. Those documents have an ObjectID _id generated by the
+// driver, the flow id in "id" and the lowercased Go names of the fields
+// (firstplugintorun, schemainput, ...). The flow id becomes _id, the fields
+// get their bson names and the missing timestamps come from the ObjectID.
+// It is called on startup and does nothing when there are no legacy flows.
+func (f *FlowRepository) MigrateLegacyFlows(ctx *yctx.Context) (err error) {
+	collection := f.client.Collection(CollectionFlowName)
+
+	// the migrated documents have no "id", so the cursor does not return them.
+	cursor, err := collection.Find(ctx.Context(), bson.M{"id": bson.M{"$exists": true}})
+	if err != nil {
+		return
+	}
+	defer cursor.Close(ctx.Context())
+
+	for cursor.Next(ctx.Context()) {
+		var document bson.M
+		if err = cursor.Decode(&document); err != nil {
+			return
+		}
+
+		legacyId := document["_id"]
+		delete(document, "_id")
+
+		flow := renameLegacyFields(document, flowType).(bson.M)
+		if id, _ := flow["_id"].(string); id == "" {
+			flow["_id"] = uuid.NewString()
+		}
+
+		if objectId, ok := legacyId.(primitive.ObjectID); ok {
+			createdAt := primitive.NewDateTimeFromTime(objectId.Timestamp())
+			for _, field := range []string{"created_at", "updated_at"} {
+				if _, ok := flow[field]; !ok {
+					flow[field] = createdAt
+				}
+			}
+		}
+
+		// the new document is written before the legacy one is deleted, so
+		// a migration stopped in between is completed by the next one.
+		_, err = collection.ReplaceOne(ctx.Context(), bson.M{"_id": flow["_id"]}, flow, mongoOptions.Replace().SetUpsert(true))
+		if err != nil {
+			return
+		}
+
+		if _, err = collection.DeleteOne(ctx.Context(), bson.M{"_id": legacyId}); err != nil {
+			return
+		}
+	}
+
+	return cursor.Err()
+}
+
+// renameLegacyFields renames the keys of value, decoded from a document of
+// type t written without bson tags, to the bson names of the fields of t.
+// Unknown keys are kept.
+func renameLegacyFields(value any, t reflect.Type) any {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	switch v := value.(type) {
+	case bson.M:
+		if t.Kind() != reflect.Struct {
+			return v
+		}
+
+		renamed := make(bson.M, len(v))
+		legacyKeys := make(map[string]bool, t.NumField())
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			name, _, _ := strings.Cut(field.Tag.Get("bson"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+
+			legacyKey := strings.ToLower(field.Name)
+			if item, ok := v[legacyKey]; ok {
+				renamed[name] = renameLegacyFields(item, field.Type)
+				legacyKeys[legacyKey] = true
+			}
+		}
+
+		for key, item := range v {
+			if _, ok := renamed[key]; !ok && !legacyKeys[key] {
+				renamed[key] = item
+			}
+		}
+
+		return renamed
+	case bson.A:
+		for i, item := range v {
+			v[i] = renameLegacyFields(item, t)
+		}
+
+		return v
+	}
+
+	return value
+}
@@ -0,0 +1,91 @@
+package mongodb
+
+import (
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mongoOptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	CollectionFlowVersionName = "flow_version"
+)
+
+var (
+	_ flowmanager.FlowVersionRepository = (*FlowVersionRepository)(nil)
+)
+
+type (
+	FlowVersionRepository struct {
+		client *Client
+	}
+)
+
+func NewFlowVersionRepository(client *Client) *FlowVersionRepository {
+	return &FlowVersionRepository{
+		client: client,
+	}
+}
+
+func (f *FlowVersionRepository) Save(ctx *yctx.Context, version *flowmanager.FlowVersion) (err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = f.client.Collection(CollectionFlowVersionName)
+
+	_, err = collection.InsertOne(ctx.Context(), version)
+	if mongo.IsDuplicateKeyError(err) {
+		return flowmanager.ErrFlowVersionExists
+	}
+
+	return
+}
+
+func (f *FlowVersionRepository) GetByVersion(ctx *yctx.Context, flowId string, version int) (item *flowmanager.FlowVersion, err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = f.client.Collection(CollectionFlowVersionName)
+
+	result := collection.FindOne(ctx.Context(), bson.M{"flow_id": flowId, "version": version})
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Err()
+	}
+
+	item = new(flowmanager.FlowVersion)
+	err = result.Decode(item)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (f *FlowVersionRepository) GetByFlowId(ctx *yctx.Context, flowId string) (items []flowmanager.FlowVersion, err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = f.client.Collection(CollectionFlowVersionName)
+
+	options := mongoOptions.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+
+	cursor, err := collection.Find(ctx.Context(), bson.M{"flow_id": flowId}, options)
+	if err != nil {
+		return
+	}
+	defer cursor.Close(ctx.Context())
+
+	err = cursor.All(ctx.Context(), &items)
+	if err != nil {
+		return
+	}
+
+	return items, nil
+}
@@ -0,0 +1,137 @@
+package mongodb
+
+import (
+	"github.com/yrn-go/yrn/module/scheduler"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mongoOptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	CollectionScheduleName = "schedule"
+)
+
+var (
+	_ scheduler.ScheduleRepository = (*ScheduleRepository)(nil)
+)
+
+type (
+	ScheduleRepository struct {
+		client *Client
+	}
+)
+
+func NewScheduleRepository(client *Client) *ScheduleRepository {
+	return &ScheduleRepository{
+		client: client,
+	}
+}
+
+func (s *ScheduleRepository) Save(ctx *yctx.Context, schedule *scheduler.Schedule) (err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = s.client.Collection(CollectionScheduleName)
+
+	_, err = collection.ReplaceOne(ctx.Context(), bson.M{"_id": schedule.Id}, schedule, mongoOptions.Replace().SetUpsert(true))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (s *ScheduleRepository) GetById(ctx *yctx.Context, id string) (item *scheduler.Schedule, err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = s.client.Collection(CollectionScheduleName)
+
+	result := collection.FindOne(ctx.Context(), bson.M{"_id": id})
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Err()
+	}
+
+	item = new(scheduler.Schedule)
+	err = result.Decode(item)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (s *ScheduleRepository) GetAll(ctx *yctx.Context) (items []scheduler.Schedule, err error) {
+	return s.find(ctx, bson.M{})
+}
+
+func (s *ScheduleRepository) GetByFlowId(ctx *yctx.Context, flowId string) (items []scheduler.Schedule, err error) {
+	return s.find(ctx, bson.M{"flow_id": flowId})
+}
+
+func (s *ScheduleRepository) Delete(ctx *yctx.Context, id string) (err error) {
+	var (
+		collection *mongo.Collection
+		result     *mongo.DeleteResult
+	)
+
+	collection = s.client.Collection(CollectionScheduleName)
+
+	result, err = collection.DeleteOne(ctx.Context(), bson.M{"_id": id})
+	if err != nil {
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		return scheduler.ErrScheduleNotFound
+	}
+
+	return
+}
+
+func (s *ScheduleRepository) SaveRun(ctx *yctx.Context, id string, run scheduler.ScheduleRun) (err error) {
+	var (
+		collection *mongo.Collection
+		result     *mongo.UpdateResult
+	)
+
+	collection = s.client.Collection(CollectionScheduleName)
+
+	result, err = collection.UpdateOne(ctx.Context(), bson.M{"_id": id}, bson.M{"$set": bson.M{"last_run": run}})
+	if err != nil {
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		return scheduler.ErrScheduleNotFound
+	}
+
+	return
+}
+
+func (s *ScheduleRepository) find(ctx *yctx.Context, filter bson.M) (items []scheduler.Schedule, err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = s.client.Collection(CollectionScheduleName)
+
+	cursor, err := collection.Find(ctx.Context(), filter, mongoOptions.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return
+	}
+	defer cursor.Close(ctx.Context())
+
+	err = cursor.All(ctx.Context(), &items)
+	if err != nil {
+		return
+	}
+
+	return items, nil
+}
@@ -0,0 +1,115 @@
+package mongodb
+
+import (
+	"github.com/yrn-go/yrn/module/secretmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mongoOptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	CollectionSecretName = "secret"
+)
+
+var (
+	_ secretmanager.SecretRepository = (*SecretRepository)(nil)
+)
+
+type (
+	SecretRepository struct {
+		client *Client
+	}
+)
+
+func NewSecretRepository(client *Client) *SecretRepository {
+	return &SecretRepository{
+		client: client,
+	}
+}
+
+func (s *SecretRepository) Save(ctx *yctx.Context, secret *secretmanager.Secret) (err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = s.client.Collection(CollectionSecretName)
+
+	filter := bson.M{"tenant": secret.Tenant, "name": secret.Name}
+	_, err = collection.ReplaceOne(ctx.Context(), filter, secret, mongoOptions.Replace().SetUpsert(true))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (s *SecretRepository) GetByName(ctx *yctx.Context, tenant, name string) (item *secretmanager.Secret, err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = s.client.Collection(CollectionSecretName)
+
+	filter := bson.M{"tenant": tenant, "name": name}
+	result := collection.FindOne(ctx.Context(), filter)
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Err()
+	}
+
+	item = new(secretmanager.Secret)
+	err = result.Decode(item)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (s *SecretRepository) GetAll(ctx *yctx.Context, tenant string) (items []secretmanager.Secret, err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = s.client.Collection(CollectionSecretName)
+
+	options := mongoOptions.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}}).
+		SetProjection(bson.M{"encrypted_value": 0})
+
+	cursor, err := collection.Find(ctx.Context(), bson.M{"tenant": tenant}, options)
+	if err != nil {
+		return
+	}
+	defer cursor.Close(ctx.Context())
+
+	err = cursor.All(ctx.Context(), &items)
+	if err != nil {
+		return
+	}
+
+	return items, nil
+}
+
+func (s *SecretRepository) Delete(ctx *yctx.Context, tenant, name string) (err error) {
+	var (
+		collection *mongo.Collection
+		result     *mongo.DeleteResult
+	)
+
+	collection = s.client.Collection(CollectionSecretName)
+
+	result, err = collection.DeleteOne(ctx.Context(), bson.M{"tenant": tenant, "name": name})
+	if err != nil {
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		return secretmanager.ErrSecretNotFound
+	}
+
+	return
+}
@@ -0,0 +1,111 @@
+package mongodb
+
+import (
+	"github.com/yrn-go/yrn/module/webhook"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mongoOptions "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	CollectionWebhookName = "webhook"
+)
+
+var (
+	_ webhook.WebhookRepository = (*WebhookRepository)(nil)
+)
+
+type (
+	WebhookRepository struct {
+		client *Client
+	}
+)
+
+func NewWebhookRepository(client *Client) *WebhookRepository {
+	return &WebhookRepository{
+		client: client,
+	}
+}
+
+func (w *WebhookRepository) Save(ctx *yctx.Context, item *webhook.Webhook) (err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = w.client.Collection(CollectionWebhookName)
+
+	_, err = collection.ReplaceOne(ctx.Context(), bson.M{"_id": item.Id}, item, mongoOptions.Replace().SetUpsert(true))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (w *WebhookRepository) GetById(ctx *yctx.Context, id string) (item *webhook.Webhook, err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = w.client.Collection(CollectionWebhookName)
+
+	result := collection.FindOne(ctx.Context(), bson.M{"_id": id})
+	if result.Err() != nil {
+		if result.Err() == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, result.Err()
+	}
+
+	item = new(webhook.Webhook)
+	err = result.Decode(item)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (w *WebhookRepository) GetByFlowId(ctx *yctx.Context, flowId string) (items []webhook.Webhook, err error) {
+	var (
+		collection *mongo.Collection
+	)
+
+	collection = w.client.Collection(CollectionWebhookName)
+
+	options := mongoOptions.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := collection.Find(ctx.Context(), bson.M{"flow_id": flowId}, options)
+	if err != nil {
+		return
+	}
+	defer cursor.Close(ctx.Context())
+
+	err = cursor.All(ctx.Context(), &items)
+	if err != nil {
+		return
+	}
+
+	return items, nil
+}
+
+func (w *WebhookRepository) Delete(ctx *yctx.Context, id string) (err error) {
+	var (
+		collection *mongo.Collection
+		result     *mongo.DeleteResult
+	)
+
+	collection = w.client.Collection(CollectionWebhookName)
+
+	result, err = collection.DeleteOne(ctx.Context(), bson.M{"_id": id})
+	if err != nil {
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		return webhook.ErrWebhookNotFound
+	}
+
+	return
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"errors"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+const (
+	EnvPostgresUrl = "POSTGRES_URL"
+)
+
+// NewPool connects to the database of POSTGRES_URL. The pool is owned by the
+// caller, which must close it on shutdown.
+func NewPool(ctx *yctx.Context) (pool *pgxpool.Pool, err error) {
+	connString := os.Getenv(EnvPostgresUrl)
+	if connString == "" {
+		return nil, errors.New("missing environment variable: " + EnvPostgresUrl)
+	}
+
+	pool, err = pgxpool.New(ctx.Context(), connString)
+	if err != nil {
+		return
+	}
+
+	if err = pool.Ping(ctx.Context()); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,297 @@
+package postgres
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+const flowColumns = `id, name, description, tenant, tags, first_plugin_to_run, plugins, triggers, version, revision, deployment, deleted_at, created_at, updated_at`
+
+var (
+	_ flowmanager.FlowReaderRepository = (*FlowRepository)(nil)
+	_ flowmanager.FlowWriteRepository  = (*FlowRepository)(nil)
+	_ flowmanager.FlowDeployRepository = (*FlowRepository)(nil)
+	_ flowmanager.FlowSearchRepository = (*FlowRepository)(nil)
+)
+
+// FlowRepository stores flows in the flow table, created by Migrate, with the
+// plugins, triggers and deployment as JSONB.
+type FlowRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewFlowRepository(pool *pgxpool.Pool) *FlowRepository {
+	return &FlowRepository{
+		pool: pool,
+	}
+}
+
+// Save stores the flow in the tenant of the context, or in its own tenant for
+// the contexts that access every tenant.
+func (f *FlowRepository) Save(ctx *yctx.Context, flow *flowmanager.Flow) error {
+	if !ctx.HasAllTenants() {
+		flow.Tenant = ctx.Tenant()
+	}
+
+	_, err := f.pool.Exec(ctx.Context(), `
+		INSERT INTO flow (`+flowColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		flow.Id, flow.Name, flow.Description, flow.Tenant, flow.Tags, flow.FirstPluginToRun,
+		jsonArray(flow.Plugins), jsonArray(flow.Triggers), flow.Version, flow.Revision, flow.Deployment, flow.DeletedAt,
+		flow.CreatedAt, flow.UpdatedAt,
+	)
+
+	return err
+}
+
+// Update sets every column of the flow but the tenant, the deployment and the
+// creation time when the stored revision is expectedRevision.
+func (f *FlowRepository) Update(ctx *yctx.Context, flow *flowmanager.Flow, expectedRevision int) error {
+	tag, err := f.pool.Exec(ctx.Context(), `
+		UPDATE flow
+		SET name = $4, description = $5, tags = $6, first_plugin_to_run = $7,
+			plugins = $8, triggers = $9, version = $10, revision = revision + 1, updated_at = $11
+		WHERE id = $1 AND revision = $2 AND deleted_at IS NULL AND `+tenantScope("$3"),
+		flow.Id, expectedRevision, tenantArg(ctx), flow.Name, flow.Description, flow.Tags, flow.FirstPluginToRun,
+		jsonArray(flow.Plugins), jsonArray(flow.Triggers), flow.Version, flow.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = f.pool.QueryRow(ctx.Context(), `
+		SELECT EXISTS (SELECT 1 FROM flow WHERE id = $1 AND deleted_at IS NULL AND `+tenantScope("$2")+`)`,
+		flow.Id, tenantArg(ctx),
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return flowmanager.ErrFlowNotFound
+	}
+
+	return flowmanager.ErrFlowVersionConflict
+}
+
+func (f *FlowRepository) Delete(ctx *yctx.Context, id string) error {
+	return f.exec(ctx, `UPDATE flow SET deleted_at = now(), updated_at = now(), revision = revision + 1 WHERE id = $1 AND deleted_at IS NULL`, id)
+}
+
+func (f *FlowRepository) Restore(ctx *yctx.Context, id string) error {
+	return f.exec(ctx, `UPDATE flow SET deleted_at = NULL, updated_at = now(), revision = revision + 1 WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+}
+
+func (f *FlowRepository) GetById(ctx *yctx.Context, id string) (*flowmanager.Flow, error) {
+	rows, err := f.pool.Query(ctx.Context(), `
+		SELECT `+flowColumns+` FROM flow
+		WHERE id = $1 AND deleted_at IS NULL AND `+tenantScope("$2"),
+		id, tenantArg(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := pgx.CollectExactlyOneRow(rows, scanFlow)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (f *FlowRepository) GetAll(ctx *yctx.Context, pagination *flowmanager.Pagination) ([]flowmanager.Flow, error) {
+	return f.find(ctx, `deleted_at IS NULL`, pagination)
+}
+
+func (f *FlowRepository) GetDeleted(ctx *yctx.Context, pagination *flowmanager.Pagination) ([]flowmanager.Flow, error) {
+	return f.find(ctx, `deleted_at IS NOT NULL`, pagination)
+}
+
+func (f *FlowRepository) Count(ctx *yctx.Context) (total int, err error) {
+	err = f.pool.QueryRow(ctx.Context(), `SELECT count(*) FROM flow WHERE deleted_at IS NULL AND `+tenantScope("$1"), tenantArg(ctx)).Scan(&total)
+	return
+}
+
+func (f *FlowRepository) SaveDeployment(ctx *yctx.Context, flowId string, expected ybase.DeployStatus, deployment *flowmanager.FlowDeployment) error {
+	tag, err := f.pool.Exec(ctx.Context(), `
+		UPDATE flow
+		SET deployment = $3, revision = revision + 1
+		WHERE id = $1 AND coalesce(deployment->>'status', $4) = $2 AND `+tenantScope("$5"),
+		flowId, expected, deployment, ybase.DeployStatusPending, tenantArg(ctx),
+	)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return flowmanager.ErrDeployConflict
+	}
+
+	return nil
+}
+
+func (f *FlowRepository) Search(ctx *yctx.Context, query *flowmanager.FlowQuery) ([]flowmanager.Flow, int, error) {
+	var (
+		where = []string{`deleted_at IS NULL`, tenantScope("$1")}
+		args  = []any{tenantArg(ctx)}
+		total int
+	)
+
+	arg := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if query.Tenant != "" {
+		where = append(where, `tenant = `+arg(query.Tenant))
+	}
+
+	if query.NamePrefix != "" {
+		where = append(where, `starts_with(name, `+arg(query.NamePrefix)+`)`)
+	}
+
+	if query.PluginSlug != "" {
+		where = append(where, `plugins @> jsonb_build_array(jsonb_build_object('slug', `+arg(query.PluginSlug)+`::text))`)
+	}
+
+	if query.DeployStatus != "" {
+		where = append(where, `coalesce(deployment->>'status', `+arg(ybase.DeployStatusPending)+`) = `+arg(query.DeployStatus))
+	}
+
+	if len(query.Tags) > 0 {
+		where = append(where, `tags @> `+arg(query.Tags))
+	}
+
+	// each word is a case insensitive substring of the name, the description
+	// or a tag, like SearchFlows.
+	for _, word := range query.TextWords() {
+		pattern := arg("%" + likeEscaper.Replace(word) + "%")
+		where = append(where, `(name ILIKE `+pattern+` OR description ILIKE `+pattern+
+			` OR EXISTS (SELECT 1 FROM unnest(tags) AS tag WHERE tag ILIKE `+pattern+`))`)
+	}
+
+	err := f.pool.QueryRow(ctx.Context(), `SELECT count(*) FROM flow WHERE `+strings.Join(where, ` AND `), args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	field, descending := query.SortField()
+	direction, operator := `ASC`, `>`
+	if descending {
+		direction, operator = `DESC`, `<`
+	}
+
+	if query.After != nil {
+		var value any = query.After.Name
+		if field == string(flowmanager.FlowSortUpdated) {
+			value = query.After.UpdatedAt
+		}
+
+		where = append(where, `(`+field+`, id) `+operator+` (`+arg(value)+`, `+arg(query.After.Id)+`)`)
+	}
+
+	rows, err := f.pool.Query(ctx.Context(), `
+		SELECT `+flowColumns+` FROM flow
+		WHERE `+strings.Join(where, ` AND `)+`
+		ORDER BY `+field+` `+direction+`, id `+direction+`
+		LIMIT `+arg(query.Size)+` OFFSET `+arg(query.Page*query.Size),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items, err := pgx.CollectRows(rows, scanFlow)
+
+	return items, total, err
+}
+
+func (f *FlowRepository) find(ctx *yctx.Context, where string, pagination *flowmanager.Pagination) ([]flowmanager.Flow, error) {
+	rows, err := f.pool.Query(ctx.Context(), `
+		SELECT `+flowColumns+` FROM flow
+		WHERE `+where+` AND `+tenantScope("$3")+`
+		ORDER BY created_at, id
+		LIMIT $1 OFFSET $2`,
+		pagination.Size, pagination.Page*pagination.Size, tenantArg(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, scanFlow)
+}
+
+// exec runs a statement on one flow of the tenant of the context and returns
+// ErrFlowNotFound when no row was changed.
+func (f *FlowRepository) exec(ctx *yctx.Context, sql string, id string) error {
+	tag, err := f.pool.Exec(ctx.Context(), sql+` AND `+tenantScope("$2"), id, tenantArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return flowmanager.ErrFlowNotFound
+	}
+
+	return nil
+}
+
+// jsonArray returns an empty slice for nil, which pgx would write as NULL in
+// the JSONB NOT NULL columns.
+func jsonArray[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+
+	return items
+}
+
+// tenantScope restricts a query to the tenant in param, the tenantArg of the
+// context.
+func tenantScope(param string) string {
+	return `(` + param + `::text IS NULL OR tenant = ` + param + `)`
+}
+
+// tenantArg is the tenant of the context, or NULL for the contexts that
+// access every tenant.
+func tenantArg(ctx *yctx.Context) any {
+	if ctx.HasAllTenants() {
+		return nil
+	}
+
+	return ctx.Tenant()
+}
+
+func scanFlow(row pgx.CollectableRow) (item flowmanager.Flow, err error) {
+	err = row.Scan(
+		&item.Id, &item.Name, &item.Description, &item.Tenant, &item.Tags, &item.FirstPluginToRun,
+		&item.Plugins, &item.Triggers, &item.Version, &item.Revision, &item.Deployment, &item.DeletedAt,
+		&item.CreatedAt, &item.UpdatedAt,
+	)
+
+	// timestamptz is read in the local time zone.
+	item.CreatedAt = item.CreatedAt.UTC()
+	item.UpdatedAt = item.UpdatedAt.UTC()
+
+	return
+}
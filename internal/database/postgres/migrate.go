@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"embed"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+// migrationLockId is the advisory lock held while migrating, so replicas
+// starting together apply each migration once.
+const migrationLockId = 7_240_301
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate applies the migrations that were not applied yet, in the order of
+// their file names.
+func Migrate(ctx *yctx.Context, pool *pgxpool.Pool) error {
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(names)
+
+	return pgx.BeginFunc(ctx.Context(), pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx.Context(), `SELECT pg_advisory_xact_lock($1)`, migrationLockId); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx.Context(), `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`); err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			version := strings.TrimSuffix(strings.TrimPrefix(name, "migrations/"), ".sql")
+
+			var applied bool
+			if err := tx.QueryRow(ctx.Context(), `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version).Scan(&applied); err != nil {
+				return err
+			}
+
+			if applied {
+				continue
+			}
+
+			script, err := migrations.ReadFile(name)
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec(ctx.Context(), string(script)); err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec(ctx.Context(), `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
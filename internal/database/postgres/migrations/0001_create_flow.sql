@@ -0,0 +1,16 @@
+CREATE TABLE flow (
+    id                  TEXT PRIMARY KEY,
+    name                TEXT        NOT NULL DEFAULT '',
+    description         TEXT        NOT NULL DEFAULT '',
+    tenant              TEXT        NOT NULL DEFAULT '',
+    first_plugin_to_run TEXT        NOT NULL DEFAULT '',
+    plugins             JSONB       NOT NULL DEFAULT '[]',
+    triggers            JSONB       NOT NULL DEFAULT '[]',
+    version             INTEGER     NOT NULL DEFAULT 0,
+    deployment          JSONB,
+    deleted_at          TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX flow_tenant_idx ON flow (tenant, created_at) WHERE deleted_at IS NULL;
@@ -0,0 +1,6 @@
+ALTER TABLE flow ADD COLUMN tags TEXT[];
+
+CREATE INDEX flow_name_idx ON flow (tenant, name, id) WHERE deleted_at IS NULL;
+CREATE INDEX flow_updated_idx ON flow (tenant, updated_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX flow_tags_idx ON flow USING GIN (tags);
+CREATE INDEX flow_text_idx ON flow USING GIN (to_tsvector('simple', name || ' ' || description));
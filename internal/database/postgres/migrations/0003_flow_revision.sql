@@ -0,0 +1 @@
+ALTER TABLE flow ADD COLUMN revision INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- q is matched as case insensitive substrings with ILIKE, which does not use
+-- the full text index.
+DROP INDEX IF EXISTS flow_text_idx;
@@ -0,0 +1,218 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/internal/database/boltdb"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/module/scheduler"
+	"github.com/yrn-go/yrn/module/secretmanager"
+	"github.com/yrn-go/yrn/module/webhook"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestBoltStore(t *testing.T) {
+	suite.Run(t, new(BoltStoreTestSuite))
+}
+
+type BoltStoreTestSuite struct {
+	suite.Suite
+	ctx   *yctx.Context
+	path  string
+	store *boltdb.Store
+}
+
+func (s *BoltStoreTestSuite) SetupTest() {
+	s.ctx = yctx.NewContext(context.Background())
+	s.path = filepath.Join(s.T().TempDir(), "yrn.db")
+	s.reopen()
+}
+
+func (s *BoltStoreTestSuite) TearDownTest() {
+	s.NoError(s.store.Close())
+}
+
+// reopen closes the file and opens it again, like a restart of the binary.
+func (s *BoltStoreTestSuite) reopen() {
+	if s.store != nil {
+		s.Require().NoError(s.store.Close())
+	}
+
+	store, err := boltdb.Open(s.path)
+	s.Require().NoError(err)
+	s.store = store
+}
+
+func (s *BoltStoreTestSuite) TestPluginStatus_SurvivesRestart() {
+	repository := boltdb.NewPluginStatusRepository(s.store)
+	s.Require().NoError(repository.Save(s.ctx, flowmanager.PluginStatus{
+		FlowID:       "flow-1",
+		FlowVersion:  2,
+		ExecutionID:  "execution-1",
+		PluginID:     "fetch",
+		Status:       "error",
+		ErrorMessage: "timeout",
+		Output:       map[string]any{"status": 504.0},
+	}))
+
+	s.reopen()
+	repository = boltdb.NewPluginStatusRepository(s.store)
+
+	execution := s.ctx.WithExecution("flow-1", "execution-1")
+
+	status, err := repository.GetByPluginID(execution, "fetch")
+	s.Require().NoError(err)
+	s.Equal(2, status.FlowVersion)
+	s.Equal("timeout", status.ErrorMessage)
+	s.Equal(map[string]any{"status": 504.0}, status.Output)
+
+	_, err = repository.GetByPluginID(execution, "missing")
+	s.Error(err)
+
+	statuses, err := repository.GetAll(s.ctx)
+	s.NoError(err)
+	s.Len(statuses, 1)
+}
+
+func (s *BoltStoreTestSuite) TestPluginStatus_IsScopedByTenant() {
+	repository := boltdb.NewPluginStatusRepository(s.store)
+	for _, tenant := range []string{"tenant-a", "tenant-b"} {
+		s.Require().NoError(repository.Save(s.ctx, flowmanager.PluginStatus{
+			Tenant:      tenant,
+			FlowID:      "flow-1",
+			ExecutionID: "execution-1",
+			PluginID:    "fetch",
+			Status:      "success",
+		}))
+	}
+
+	execution := s.ctx.WithExecution("flow-1", "execution-1")
+
+	status, err := repository.GetByPluginID(execution.WithTenant("tenant-a"), "fetch")
+	s.Require().NoError(err)
+	s.Equal("tenant-a", status.Tenant)
+
+	statuses, err := repository.GetAll(s.ctx.WithTenant("tenant-b"))
+	s.Require().NoError(err)
+	s.Require().Len(statuses, 1)
+	s.Equal("tenant-b", statuses[0].Tenant)
+
+	_, err = repository.GetByPluginID(execution.WithTenant("tenant-c"), "fetch")
+	s.Error(err)
+
+	statuses, err = repository.GetAll(s.ctx)
+	s.NoError(err)
+	s.Empty(statuses)
+
+	statuses, err = repository.GetAll(s.ctx.WithAllTenants())
+	s.NoError(err)
+	s.Len(statuses, 2)
+}
+
+func (s *BoltStoreTestSuite) TestPluginStatus_KeepsEveryExecution() {
+	repository := boltdb.NewPluginStatusRepository(s.store)
+	for _, executionID := range []string{"execution-1", "execution-2"} {
+		s.Require().NoError(repository.Save(s.ctx, flowmanager.PluginStatus{
+			FlowID:      "flow-1",
+			ExecutionID: executionID,
+			PluginID:    "fetch",
+			Status:      "success " + executionID,
+		}))
+	}
+
+	for _, executionID := range []string{"execution-1", "execution-2"} {
+		status, err := repository.GetByPluginID(s.ctx.WithExecution("flow-1", executionID), "fetch")
+		s.Require().NoError(err)
+		s.Equal("success "+executionID, status.Status)
+	}
+
+	statuses, err := repository.GetAll(s.ctx)
+	s.NoError(err)
+	s.Len(statuses, 2)
+}
+
+func (s *BoltStoreTestSuite) TestFlowVersions() {
+	repository := boltdb.NewFlowVersionRepository(s.store)
+
+	for _, version := range []int{2, 1} {
+		s.Require().NoError(repository.Save(s.ctx, &flowmanager.FlowVersion{
+			Id:      fmt.Sprintf("flow-1@%d", version),
+			FlowId:  "flow-1",
+			Version: version,
+		}))
+	}
+
+	s.ErrorIs(repository.Save(s.ctx, &flowmanager.FlowVersion{Id: "flow-1@1", FlowId: "flow-1", Version: 1}), flowmanager.ErrFlowVersionExists)
+
+	versions, err := repository.GetByFlowId(s.ctx, "flow-1")
+	s.Require().NoError(err)
+	s.Require().Len(versions, 2)
+	s.Equal(1, versions[0].Version)
+
+	version, err := repository.GetByVersion(s.ctx, "flow-1", 2)
+	s.Require().NoError(err)
+	s.Equal("flow-1@2", version.Id)
+
+	version, err = repository.GetByVersion(s.ctx, "flow-1", 3)
+	s.NoError(err)
+	s.Nil(version)
+}
+
+func (s *BoltStoreTestSuite) TestSchedules() {
+	repository := boltdb.NewScheduleRepository(s.store)
+	nextRunAt := time.Now()
+
+	s.Require().NoError(repository.Save(s.ctx, &scheduler.Schedule{Id: "s1", FlowId: "flow-1", Cron: "@hourly", NextRunAt: &nextRunAt}))
+	s.Require().NoError(repository.SaveRun(s.ctx, "s1", scheduler.ScheduleRun{Error: "failed"}))
+	s.ErrorIs(repository.SaveRun(s.ctx, "missing", scheduler.ScheduleRun{}), scheduler.ErrScheduleNotFound)
+
+	schedules, err := repository.GetByFlowId(s.ctx, "flow-1")
+	s.Require().NoError(err)
+	s.Require().Len(schedules, 1)
+	s.Equal("failed", schedules[0].LastRun.Error)
+	s.Nil(schedules[0].NextRunAt)
+
+	s.NoError(repository.Delete(s.ctx, "s1"))
+	s.ErrorIs(repository.Delete(s.ctx, "s1"), scheduler.ErrScheduleNotFound)
+}
+
+func (s *BoltStoreTestSuite) TestWebhooks() {
+	repository := boltdb.NewWebhookRepository(s.store)
+
+	s.Require().NoError(repository.Save(s.ctx, &webhook.Webhook{Id: "w1", FlowId: "flow-1", Enabled: true}))
+
+	item, err := repository.GetById(s.ctx, "w1")
+	s.Require().NoError(err)
+	s.True(item.Enabled)
+
+	item, err = repository.GetById(s.ctx, "missing")
+	s.NoError(err)
+	s.Nil(item)
+
+	s.NoError(repository.Delete(s.ctx, "w1"))
+	s.ErrorIs(repository.Delete(s.ctx, "w1"), webhook.ErrWebhookNotFound)
+}
+
+func (s *BoltStoreTestSuite) TestSecrets_KeepEncryptedValuePerTenant() {
+	repository := boltdb.NewSecretRepository(s.store)
+
+	s.Require().NoError(repository.Save(s.ctx, &secretmanager.Secret{Tenant: "tenant-a", Name: "token", EncryptedValue: []byte{1, 2, 3}}))
+	s.Require().NoError(repository.Save(s.ctx, &secretmanager.Secret{Tenant: "tenant-b", Name: "token", EncryptedValue: []byte{4}}))
+
+	secret, err := repository.GetByName(s.ctx, "tenant-a", "token")
+	s.Require().NoError(err)
+	s.Equal([]byte{1, 2, 3}, secret.EncryptedValue)
+
+	secrets, err := repository.GetAll(s.ctx, "tenant-b")
+	s.Require().NoError(err)
+	s.Require().Len(secrets, 1)
+	s.Equal([]byte{4}, secrets[0].EncryptedValue)
+
+	s.NoError(repository.Delete(s.ctx, "tenant-a", "token"))
+	s.ErrorIs(repository.Delete(s.ctx, "tenant-a", "token"), secretmanager.ErrSecretNotFound)
+}
@@ -6,13 +6,17 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/module/secretmanager"
+	"github.com/yrn-go/yrn/pkg/plugincore"
 	"github.com/yrn-go/yrn/pkg/pluginhttp"
 	"github.com/yrn-go/yrn/pkg/pluginmapper"
+	"github.com/yrn-go/yrn/pkg/ybase"
 	"github.com/yrn-go/yrn/pkg/yctx"
 	"golang.org/x/exp/slog"
 )
@@ -24,6 +28,7 @@ func TestFlowExecutor(t *testing.T) {
 type FlowExecutorTestSuite struct {
 	suite.Suite
 	flowReaderRepositoryMock *flowmanager.FlowReaderRepositoryMock
+	flowVersionRepository    *flowmanager.InMemoryFlowVersionRepository
 	pluginManager            *pluginmapper.PluginManagerLocal
 	statusRepoMock           *flowmanager.PluginStatusRepositoryMock
 	flowExecutor             *flowmanager.FlowExecutor
@@ -31,9 +36,16 @@ type FlowExecutorTestSuite struct {
 
 func (s *FlowExecutorTestSuite) SetupTest() {
 	s.flowReaderRepositoryMock = new(flowmanager.FlowReaderRepositoryMock)
-	s.pluginManager = pluginmapper.NewPluginManagerLocal()
+	s.flowVersionRepository = flowmanager.NewInMemoryFlowVersionRepository()
+	// the flows call a local test server.
+	s.T().Setenv(pluginhttp.EnvEgressAllowPrivate, "true")
+
+	var err error
+	s.pluginManager, err = pluginmapper.NewPluginManagerLocal()
+	s.Require().NoError(err)
+
 	s.statusRepoMock = new(flowmanager.PluginStatusRepositoryMock)
-	s.flowExecutor = flowmanager.NewFlowExecutor(s.flowReaderRepositoryMock, s.pluginManager, s.statusRepoMock)
+	s.flowExecutor = flowmanager.NewFlowExecutor(s.flowReaderRepositoryMock, s.flowVersionRepository, s.pluginManager, s.statusRepoMock, nil)
 }
 
 func (suite *FlowExecutorTestSuite) TearDownTest() {}
@@ -67,11 +79,13 @@ func (suite *FlowExecutorTestSuite) TestExecute_WithSuccess() {
 	defer mockServer.Close()
 
 	var (
-		ctx          = yctx.NewContext(context.Background())
+		ctx          = flowmanager.WithTestMode(yctx.NewContext(context.Background()))
 		flowExecutor = flowmanager.NewFlowExecutor(
 			suite.flowReaderRepositoryMock,
+			suite.flowVersionRepository,
 			suite.pluginManager,
 			suite.statusRepoMock,
+			nil,
 		)
 		flowId           = "flow-test-id"
 		eventRequestData = map[string]any{
@@ -135,3 +149,243 @@ func (suite *FlowExecutorTestSuite) TestExecute_WithSuccess() {
 
 	slog.Info("response", slog.Any("response", response))
 }
+
+func (suite *FlowExecutorTestSuite) TestExecute_WithJSONTemplateMode() {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestBody, _ := io.ReadAll(r.Body)
+
+		var requestData any
+		_ = json.Unmarshal(requestBody, &requestData)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"request": requestData})
+	}))
+
+	defer mockServer.Close()
+
+	var (
+		ctx              = flowmanager.WithTestMode(yctx.NewContext(context.Background()))
+		flowId           = "flow-json-template-mode"
+		userName         = "John \"Johnny\" Doe\nSecond line"
+		eventRequestData = map[string]any{
+			"user_name": userName,
+			"tags":      []any{"a", "b"},
+		}
+		schemaBody, _ = json.Marshal(pluginhttp.HTTPSchema{
+			Request: pluginhttp.HTTPRequest{
+				Method: http.MethodPost,
+				URL:    mockServer.URL + "/data",
+				Body: map[string]any{
+					"name": "{{ .data.user_name }}",
+					"tags": "{{ .data.tags }}",
+				},
+			},
+		})
+		flowInfo = &flowmanager.Flow{
+			Id:               flowId,
+			FirstPluginToRun: "json_mode",
+			Plugins: []flowmanager.FlowPlugin{
+				{
+					Id:           "json_mode",
+					Slug:         pluginhttp.SlugHttp,
+					SchemaInput:  string(schemaBody),
+					TemplateMode: plugincore.TemplateModeJSON,
+				},
+			},
+		}
+	)
+
+	suite.flowReaderRepositoryMock.
+		On("GetById", mock.Anything, flowId).
+		Return(flowInfo)
+
+	suite.statusRepoMock.
+		On("Save", mock.Anything, mock.Anything).
+		Return(nil)
+
+	response, err := suite.flowExecutor.Do(ctx, flowId, eventRequestData)
+	suite.NoError(err)
+	suite.Equal(map[string]any{
+		"request": map[string]any{
+			"name": userName,
+			"tags": []any{"a", "b"},
+		},
+	}, response)
+}
+
+func (suite *FlowExecutorTestSuite) TestExecute_ResolvesSecretsOfFlowTenant() {
+	const (
+		flowId      = "flow-with-secret"
+		secretValue = "token-\"tenant-a\""
+	)
+
+	var receivedAuthorization string
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedAuthorization = r.Header.Get("Authorization")
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "echo": r.Header.Get("Authorization")})
+	}))
+
+	defer mockServer.Close()
+
+	cipher, err := secretmanager.NewCipher(make([]byte, 32))
+	suite.Require().NoError(err)
+
+	var (
+		ctx           = flowmanager.WithTestMode(yctx.NewContext(context.Background())).WithTenant("tenant-a")
+		secretService = secretmanager.NewSecretService(secretmanager.NewInMemorySecretRepository(), cipher)
+		flowExecutor  = flowmanager.NewFlowExecutor(
+			suite.flowReaderRepositoryMock,
+			suite.flowVersionRepository,
+			suite.pluginManager,
+			suite.statusRepoMock,
+			secretService,
+		)
+		schemaInput = `{"request": {"method": "GET", "url": "` + mockServer.URL + `", "headers": {"Authorization": "Bearer {{ secret "api-token" }}"}}}`
+	)
+
+	_, err = secretService.Put(ctx.WithAllTenants(), "tenant-a", "api-token", secretValue)
+	suite.Require().NoError(err)
+	_, err = secretService.Put(ctx.WithAllTenants(), "tenant-b", "api-token", "token-tenant-b")
+	suite.Require().NoError(err)
+
+	suite.flowReaderRepositoryMock.
+		On("GetById", mock.Anything, flowId).
+		Return(&flowmanager.Flow{
+			Id:               flowId,
+			Tenant:           "tenant-a",
+			FirstPluginToRun: "http",
+			Plugins: []flowmanager.FlowPlugin{
+				{Id: "http", Slug: pluginhttp.SlugHttp, SchemaInput: schemaInput},
+			},
+		})
+
+	suite.statusRepoMock.
+		On("Save", mock.Anything, mock.MatchedBy(func(status flowmanager.PluginStatus) bool {
+			data, _ := json.Marshal(status)
+			return !strings.Contains(string(data), "token-")
+		})).
+		Return(nil)
+
+	response, err := flowExecutor.Do(ctx, flowId, nil)
+	suite.NoError(err)
+	suite.Equal(map[string]any{"ok": true, "echo": "Bearer " + secretValue}, response)
+	suite.Equal("Bearer "+secretValue, receivedAuthorization)
+	suite.statusRepoMock.AssertNumberOfCalls(suite.T(), "Save", 2)
+}
+
+func (suite *FlowExecutorTestSuite) TestExecute_RefusesFlowsNotInOperation() {
+	var (
+		ctx  = yctx.NewContext(context.Background())
+		flow = &flowmanager.Flow{
+			Id:               "flow-not-deployed",
+			FirstPluginToRun: "missing",
+			Deployment:       &flowmanager.FlowDeployment{Status: ybase.DeployStatusFailed},
+		}
+	)
+
+	suite.flowReaderRepositoryMock.
+		On("GetById", mock.Anything, flow.Id).
+		Return(flow)
+
+	_, err := suite.flowExecutor.Do(ctx, flow.Id, nil)
+	suite.ErrorIs(err, flowmanager.ErrFlowNotDeployed)
+
+	flow.Deployment = nil
+
+	_, err = suite.flowExecutor.Do(ctx, flow.Id, nil)
+	suite.ErrorIs(err, flowmanager.ErrFlowNotDeployed)
+
+	flow.Deployment = &flowmanager.FlowDeployment{Status: ybase.DeployStatusInOperation}
+
+	_, err = suite.flowExecutor.Do(ctx, flow.Id, nil)
+	suite.NotErrorIs(err, flowmanager.ErrFlowNotDeployed)
+}
+
+func (suite *FlowExecutorTestSuite) TestExecute_RunsDeployedVersion() {
+	var requestedPaths []string
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPaths = append(requestedPaths, r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"path": r.URL.Path})
+	}))
+
+	defer mockServer.Close()
+
+	newFlow := func(version int, path string) flowmanager.Flow {
+		return flowmanager.Flow{
+			Id:               "flow-deployed-version",
+			Version:          version,
+			FirstPluginToRun: "fetch",
+			Plugins: []flowmanager.FlowPlugin{
+				{
+					Id:          "fetch",
+					Slug:        pluginhttp.SlugHttp,
+					SchemaInput: `{"request": {"method": "GET", "url": "` + mockServer.URL + path + `"}}`,
+				},
+			},
+		}
+	}
+
+	var (
+		ctx      = yctx.NewContext(context.Background())
+		deployed = newFlow(1, "/deployed")
+		active   = newFlow(2, "/draft")
+	)
+
+	active.Deployment = &flowmanager.FlowDeployment{Status: ybase.DeployStatusInOperation, Version: 1}
+
+	suite.Require().NoError(suite.flowVersionRepository.Save(ctx, &flowmanager.FlowVersion{
+		Id:      "flow-deployed-version@1",
+		FlowId:  deployed.Id,
+		Version: 1,
+		Flow:    deployed,
+	}))
+
+	suite.flowReaderRepositoryMock.
+		On("GetById", mock.Anything, active.Id).
+		Return(&active)
+
+	suite.statusRepoMock.
+		On("Save", mock.Anything, mock.Anything).
+		Return(nil)
+
+	_, err := suite.flowExecutor.Do(ctx, active.Id, nil)
+	suite.Require().NoError(err)
+
+	_, err = suite.flowExecutor.Do(flowmanager.WithTestMode(ctx), active.Id, nil)
+	suite.Require().NoError(err)
+
+	suite.Equal([]string{"/deployed", "/draft"}, requestedPaths)
+
+	active.Deployment.Version = 3
+
+	_, err = suite.flowExecutor.Do(ctx, active.Id, nil)
+	suite.ErrorIs(err, flowmanager.ErrFlowVersionNotFound)
+}
+
+func (suite *FlowExecutorTestSuite) TestExecute_RefusesFlowsOfOtherTenants() {
+	var (
+		ctx  = flowmanager.WithTestMode(yctx.NewContext(context.Background()))
+		flow = &flowmanager.Flow{
+			Id:               "flow-tenant-a",
+			Tenant:           "tenant-a",
+			FirstPluginToRun: "missing",
+		}
+	)
+
+	suite.flowReaderRepositoryMock.
+		On("GetById", mock.Anything, flow.Id).
+		Return(flow)
+
+	_, err := suite.flowExecutor.Do(ctx.WithTenant("tenant-b"), flow.Id, nil)
+	suite.ErrorIs(err, flowmanager.ErrFlowNotFound)
+
+	_, err = suite.flowExecutor.Do(ctx.WithTenant("tenant-a"), flow.Id, nil)
+	suite.NotErrorIs(err, flowmanager.ErrFlowNotFound)
+	suite.statusRepoMock.AssertNotCalled(suite.T(), "Save", mock.Anything, mock.Anything)
+}
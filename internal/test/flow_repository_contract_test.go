@@ -0,0 +1,451 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/internal/database/boltdb"
+	"github.com/yrn-go/yrn/internal/database/mongodb"
+	"github.com/yrn-go/yrn/internal/database/postgres"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+// The contract suite runs against a real database only when its test URL is
+// set. The database is cleaned before each test, so never point these
+// variables to a database in use.
+const (
+	EnvTestMongoUrl    = "YRN_TEST_MONGO_URL"
+	EnvTestPostgresUrl = "YRN_TEST_POSTGRES_URL"
+)
+
+// flowRepository is the set of interfaces every flow store implements.
+type flowRepository interface {
+	flowmanager.FlowReaderRepository
+	flowmanager.FlowWriteRepository
+	flowmanager.FlowDeployRepository
+	flowmanager.FlowSearchRepository
+}
+
+func TestMongoFlowRepositoryContract(t *testing.T) {
+	url := os.Getenv(EnvTestMongoUrl)
+	if url == "" {
+		t.Skip(EnvTestMongoUrl + " is not set")
+	}
+
+	t.Setenv(mongodb.EnvMongoUrl, url)
+	t.Setenv(mongodb.EnvMongoDatabase, "yrn_contract_test")
+
+	suite.Run(t, &FlowRepositoryContractSuite{
+		newRepository: func(t *testing.T, ctx *yctx.Context) flowRepository {
+			client, err := mongodb.NewClient(ctx)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				_ = client.Disconnect(yctx.NewContext(context.Background()))
+			})
+
+			require.NoError(t, client.Collection(mongodb.CollectionFlowName).Drop(ctx.Context()))
+
+			repository := mongodb.NewFlowRepository(client)
+			require.NoError(t, repository.CreateIndexes(ctx))
+
+			return repository
+		},
+	})
+}
+
+func TestPostgresFlowRepositoryContract(t *testing.T) {
+	url := os.Getenv(EnvTestPostgresUrl)
+	if url == "" {
+		t.Skip(EnvTestPostgresUrl + " is not set")
+	}
+
+	t.Setenv(postgres.EnvPostgresUrl, url)
+
+	ctx := yctx.NewContext(context.Background())
+
+	pool, err := postgres.NewPool(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	if err = postgres.Migrate(ctx, pool); err != nil {
+		t.Fatal(err)
+	}
+
+	suite.Run(t, &FlowRepositoryContractSuite{
+		newRepository: func(t *testing.T, ctx *yctx.Context) flowRepository {
+			_, err := pool.Exec(ctx.Context(), `TRUNCATE flow`)
+			require.NoError(t, err)
+
+			return postgres.NewFlowRepository(pool)
+		},
+	})
+}
+
+func TestBoltFlowRepositoryContract(t *testing.T) {
+	suite.Run(t, &FlowRepositoryContractSuite{
+		newRepository: func(t *testing.T, ctx *yctx.Context) flowRepository {
+			store, err := boltdb.Open(filepath.Join(t.TempDir(), "yrn.db"))
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = store.Close() })
+
+			return boltdb.NewFlowRepository(store)
+		},
+	})
+}
+
+type FlowRepositoryContractSuite struct {
+	suite.Suite
+	ctx        *yctx.Context
+	repository flowRepository
+	// newRepository returns a repository without flows.
+	newRepository func(t *testing.T, ctx *yctx.Context) flowRepository
+}
+
+func (s *FlowRepositoryContractSuite) SetupTest() {
+	s.ctx = yctx.NewContext(context.Background())
+	s.repository = s.newRepository(s.T(), s.ctx)
+}
+
+func (s *FlowRepositoryContractSuite) newFlow(id string) *flowmanager.Flow {
+	return &flowmanager.Flow{
+		Id:               id,
+		Name:             "Flow " + id,
+		Description:      "contract",
+		Tenant:           "tenant-a",
+		FirstPluginToRun: "fetch",
+		Plugins: []flowmanager.FlowPlugin{
+			{
+				Id:               "fetch",
+				Slug:             "http",
+				SchemaInput:      `{"request": {"method": "GET", "url": "{{ .data.url }}"}}`,
+				NextToBeExecuted: []string{"notify"},
+			},
+			{Id: "notify", Slug: "http", SchemaInput: `{"request": {"method": "POST"}}`},
+		},
+		Triggers: []flowmanager.FlowTrigger{
+			{Id: "nightly", Type: flowmanager.TriggerTypeSchedule, Schedule: &flowmanager.ScheduleTrigger{Cron: "0 3 * * *"}},
+		},
+		Version:  1,
+		Revision: 1,
+	}
+}
+
+func (s *FlowRepositoryContractSuite) TestSave_GetById() {
+	flow := s.newFlow("flow-1")
+	s.Require().NoError(s.repository.Save(s.ctx, flow))
+
+	stored, err := s.repository.GetById(s.ctx, "flow-1")
+	s.Require().NoError(err)
+	s.Require().NotNil(stored)
+	s.Equal(flow, stored)
+
+	stored, err = s.repository.GetById(s.ctx, "missing")
+	s.NoError(err)
+	s.Nil(stored)
+
+	s.Error(s.repository.Save(s.ctx, s.newFlow("flow-1")))
+}
+
+func (s *FlowRepositoryContractSuite) TestSave_WithoutPluginsAndTriggers() {
+	flow := s.newFlow("flow-1")
+	flow.Plugins, flow.Triggers = nil, nil
+	s.Require().NoError(s.repository.Save(s.ctx, flow))
+
+	updated := s.newFlow("flow-1")
+	updated.Plugins, updated.Triggers = nil, nil
+	updated.Version = 2
+	s.Require().NoError(s.repository.Update(s.ctx, updated, 1))
+
+	stored, err := s.repository.GetById(s.ctx, "flow-1")
+	s.Require().NoError(err)
+	s.Require().NotNil(stored)
+	s.Empty(stored.Plugins)
+	s.Empty(stored.Triggers)
+	s.Equal(2, stored.Version)
+}
+
+func (s *FlowRepositoryContractSuite) TestGetAll_PaginatesAndCounts() {
+	for _, id := range []string{"flow-1", "flow-2", "flow-3"} {
+		s.Require().NoError(s.repository.Save(s.ctx, s.newFlow(id)))
+	}
+
+	items, err := s.repository.GetAll(s.ctx, &flowmanager.Pagination{Page: 0, Size: 2})
+	s.Require().NoError(err)
+	s.Len(items, 2)
+
+	items, err = s.repository.GetAll(s.ctx, &flowmanager.Pagination{Page: 1, Size: 2})
+	s.Require().NoError(err)
+	s.Len(items, 1)
+
+	total, err := s.repository.Count(s.ctx)
+	s.Require().NoError(err)
+	s.Equal(3, total)
+}
+
+func (s *FlowRepositoryContractSuite) TestUpdate_ChecksRevisionAndKeepsDeployment() {
+	flow := s.newFlow("flow-1")
+	s.Require().NoError(s.repository.Save(s.ctx, flow))
+	s.Require().NoError(s.repository.SaveDeployment(s.ctx, "flow-1", ybase.DeployStatusPending, &flowmanager.FlowDeployment{
+		Status:  ybase.DeployStatusInOperation,
+		Version: 1,
+	}))
+
+	// the deployment is a write as well.
+	stale := s.newFlow("flow-1")
+	stale.Version = 2
+	s.ErrorIs(s.repository.Update(s.ctx, stale, 1), flowmanager.ErrFlowVersionConflict)
+
+	updated := s.newFlow("flow-1")
+	updated.Name = "Renamed"
+	updated.Version = 2
+	s.Require().NoError(s.repository.Update(s.ctx, updated, 2))
+	s.ErrorIs(s.repository.Update(s.ctx, s.newFlow("missing"), 1), flowmanager.ErrFlowNotFound)
+
+	stored, err := s.repository.GetById(s.ctx, "flow-1")
+	s.Require().NoError(err)
+	s.Equal("Renamed", stored.Name)
+	s.Equal(2, stored.Version)
+	s.Equal(3, stored.Revision)
+	s.Require().NotNil(stored.Deployment)
+	s.Equal(ybase.DeployStatusInOperation, stored.Deployment.Status)
+
+	// going back to version 1 does not make the revision of version 1 valid again.
+	rolledBack := s.newFlow("flow-1")
+	s.Require().NoError(s.repository.Update(s.ctx, rolledBack, 3))
+	s.ErrorIs(s.repository.Update(s.ctx, updated, 2), flowmanager.ErrFlowVersionConflict)
+
+	stored, err = s.repository.GetById(s.ctx, "flow-1")
+	s.Require().NoError(err)
+	s.Equal(1, stored.Version)
+	s.Equal(4, stored.Revision)
+}
+
+func (s *FlowRepositoryContractSuite) TestDelete_Restore() {
+	s.Require().NoError(s.repository.Save(s.ctx, s.newFlow("flow-1")))
+	s.Require().NoError(s.repository.Save(s.ctx, s.newFlow("flow-2")))
+
+	s.ErrorIs(s.repository.Restore(s.ctx, "flow-1"), flowmanager.ErrFlowNotFound)
+	s.Require().NoError(s.repository.Delete(s.ctx, "flow-1"))
+	s.ErrorIs(s.repository.Delete(s.ctx, "flow-1"), flowmanager.ErrFlowNotFound)
+	s.ErrorIs(s.repository.Update(s.ctx, s.newFlow("flow-1"), 1), flowmanager.ErrFlowNotFound)
+
+	stored, err := s.repository.GetById(s.ctx, "flow-1")
+	s.NoError(err)
+	s.Nil(stored)
+
+	total, _ := s.repository.Count(s.ctx)
+	s.Equal(1, total)
+
+	deleted, err := s.repository.GetDeleted(s.ctx, &flowmanager.Pagination{Size: 10})
+	s.Require().NoError(err)
+	s.Require().Len(deleted, 1)
+	s.Equal("flow-1", deleted[0].Id)
+	s.Require().NotNil(deleted[0].DeletedAt)
+	s.WithinDuration(time.Now(), *deleted[0].DeletedAt, time.Minute)
+
+	s.Require().NoError(s.repository.Restore(s.ctx, "flow-1"))
+
+	stored, err = s.repository.GetById(s.ctx, "flow-1")
+	s.Require().NoError(err)
+	s.Require().NotNil(stored)
+	s.Nil(stored.DeletedAt)
+}
+
+func (s *FlowRepositoryContractSuite) TestSaveDeployment_ChecksExpectedStatus() {
+	s.Require().NoError(s.repository.Save(s.ctx, s.newFlow("flow-1")))
+
+	inProgress := &flowmanager.FlowDeployment{Status: ybase.DeployStatusInProgress}
+	s.Require().NoError(s.repository.SaveDeployment(s.ctx, "flow-1", ybase.DeployStatusPending, inProgress))
+	s.ErrorIs(s.repository.SaveDeployment(s.ctx, "flow-1", ybase.DeployStatusPending, inProgress), flowmanager.ErrDeployConflict)
+	s.ErrorIs(s.repository.SaveDeployment(s.ctx, "missing", ybase.DeployStatusPending, inProgress), flowmanager.ErrDeployConflict)
+
+	s.Require().NoError(s.repository.SaveDeployment(s.ctx, "flow-1", ybase.DeployStatusInProgress, &flowmanager.FlowDeployment{
+		Status: ybase.DeployStatusInOperation,
+	}))
+
+	stored, err := s.repository.GetById(s.ctx, "flow-1")
+	s.Require().NoError(err)
+	s.Equal(ybase.DeployStatusInOperation, stored.DeployStatus())
+}
+
+func (s *FlowRepositoryContractSuite) TestSearch_FiltersSortsAndPaginates() {
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	flows := []struct {
+		id, name, tenant string
+		tags             []string
+		updatedAt        time.Time
+	}{
+		{id: "flow-1", name: "Orders sync", tenant: "tenant-a", tags: []string{"erp", "orders"}, updatedAt: updatedAt},
+		{id: "flow-2", name: "Orders report", tenant: "tenant-a", tags: []string{"orders"}, updatedAt: updatedAt.Add(2 * time.Hour)},
+		{id: "flow-3", name: "Invoices", tenant: "tenant-a", tags: []string{"erp"}, updatedAt: updatedAt.Add(time.Hour)},
+		{id: "flow-4", name: "Payments", tenant: "tenant-b", updatedAt: updatedAt},
+	}
+
+	for _, item := range flows {
+		flow := s.newFlow(item.id)
+		flow.Name = item.name
+		flow.Tenant = item.tenant
+		flow.Tags = item.tags
+		flow.UpdatedAt = item.updatedAt
+		s.Require().NoError(s.repository.Save(s.ctx, flow))
+	}
+
+	s.Require().NoError(s.repository.SaveDeployment(s.ctx, "flow-1", ybase.DeployStatusPending, &flowmanager.FlowDeployment{
+		Status: ybase.DeployStatusInOperation,
+	}))
+	s.Require().NoError(s.repository.Delete(s.ctx, "flow-4"))
+
+	search := func(query flowmanager.FlowQuery) (ids []string, total int) {
+		if query.Size == 0 {
+			query.Size = 10
+		}
+
+		items, total, err := s.repository.Search(s.ctx, &query)
+		s.Require().NoError(err)
+
+		for _, item := range items {
+			ids = append(ids, item.Id)
+		}
+
+		return ids, total
+	}
+
+	ids, total := search(flowmanager.FlowQuery{})
+	s.Equal([]string{"flow-3", "flow-2", "flow-1"}, ids)
+	s.Equal(3, total)
+
+	ids, _ = search(flowmanager.FlowQuery{Tenant: "tenant-b"})
+	s.Empty(ids)
+
+	ids, _ = search(flowmanager.FlowQuery{NamePrefix: "Orders"})
+	s.Equal([]string{"flow-2", "flow-1"}, ids)
+
+	ids, _ = search(flowmanager.FlowQuery{PluginSlug: "http", Tags: []string{"erp", "orders"}})
+	s.Equal([]string{"flow-1"}, ids)
+
+	ids, _ = search(flowmanager.FlowQuery{DeployStatus: ybase.DeployStatusPending})
+	s.Equal([]string{"flow-3", "flow-2"}, ids)
+
+	ids, _ = search(flowmanager.FlowQuery{Text: "invoices"})
+	s.Equal([]string{"flow-3"}, ids)
+
+	// words are case insensitive substrings of the name, the description or
+	// a tag.
+	ids, _ = search(flowmanager.FlowQuery{Text: "INVOI"})
+	s.Equal([]string{"flow-3"}, ids)
+
+	ids, _ = search(flowmanager.FlowQuery{Text: "sync ERP"})
+	s.Equal([]string{"flow-1"}, ids)
+
+	ids, _ = search(flowmanager.FlowQuery{Text: "contr"})
+	s.Equal([]string{"flow-3", "flow-2", "flow-1"}, ids)
+
+	ids, _ = search(flowmanager.FlowQuery{Text: "_%"})
+	s.Empty(ids)
+
+	ids, _ = search(flowmanager.FlowQuery{Sort: flowmanager.FlowSortUpdatedDesc})
+	s.Equal([]string{"flow-2", "flow-3", "flow-1"}, ids)
+
+	ids, total = search(flowmanager.FlowQuery{Pagination: flowmanager.Pagination{Page: 1, Size: 2}})
+	s.Equal([]string{"flow-1"}, ids)
+	s.Equal(3, total)
+
+	ids, total = search(flowmanager.FlowQuery{
+		Pagination: flowmanager.Pagination{Size: 2},
+		Sort:       flowmanager.FlowSortUpdated,
+		After:      &flowmanager.FlowCursor{Id: "flow-3", Name: "Invoices", UpdatedAt: updatedAt.Add(time.Hour)},
+	})
+	s.Equal([]string{"flow-2"}, ids)
+	s.Equal(3, total)
+}
+
+func (s *FlowRepositoryContractSuite) TestTenantScope() {
+	tenantA := s.ctx.WithTenant("tenant-a")
+	tenantB := s.ctx.WithTenant("tenant-b")
+
+	s.Require().NoError(s.repository.Save(tenantA, s.newFlow("flow-a")))
+	s.Require().NoError(s.repository.Save(tenantB, s.newFlow("flow-b")))
+
+	stored, err := s.repository.GetById(tenantA, "flow-b")
+	s.NoError(err)
+	s.Nil(stored)
+
+	stored, err = s.repository.GetById(tenantB, "flow-b")
+	s.Require().NoError(err)
+	s.Require().NotNil(stored)
+	s.Equal("tenant-b", stored.Tenant)
+
+	items, err := s.repository.GetAll(tenantA, &flowmanager.Pagination{Size: 10})
+	s.Require().NoError(err)
+	s.Require().Len(items, 1)
+	s.Equal("flow-a", items[0].Id)
+
+	total, err := s.repository.Count(tenantA)
+	s.Require().NoError(err)
+	s.Equal(1, total)
+
+	found, total, err := s.repository.Search(tenantA, &flowmanager.FlowQuery{Pagination: flowmanager.Pagination{Size: 10}, Sort: flowmanager.FlowSortName})
+	s.Require().NoError(err)
+	s.Len(found, 1)
+	s.Equal(1, total)
+
+	updated := s.newFlow("flow-b")
+	updated.Version = 2
+	s.ErrorIs(s.repository.Update(tenantA, updated, 1), flowmanager.ErrFlowNotFound)
+	s.ErrorIs(s.repository.Delete(tenantA, "flow-b"), flowmanager.ErrFlowNotFound)
+	s.ErrorIs(s.repository.SaveDeployment(tenantA, "flow-b", ybase.DeployStatusPending, &flowmanager.FlowDeployment{
+		Status: ybase.DeployStatusInProgress,
+	}), flowmanager.ErrDeployConflict)
+
+	// the tenant of a flow does not change on update.
+	updated.Tenant = "tenant-a"
+	s.Require().NoError(s.repository.Update(tenantB, updated, 1))
+	s.Require().NoError(s.repository.Delete(tenantB, "flow-b"))
+	s.ErrorIs(s.repository.Restore(tenantA, "flow-b"), flowmanager.ErrFlowNotFound)
+
+	deleted, err := s.repository.GetDeleted(tenantA, &flowmanager.Pagination{Size: 10})
+	s.Require().NoError(err)
+	s.Empty(deleted)
+
+	s.Require().NoError(s.repository.Restore(tenantB, "flow-b"))
+
+	// a context without tenant sees no tenant.
+	total, err = s.repository.Count(s.ctx)
+	s.Require().NoError(err)
+	s.Equal(0, total)
+
+	stored, err = s.repository.GetById(s.ctx, "flow-b")
+	s.Require().NoError(err)
+	s.Nil(stored)
+
+	// only the contexts of every tenant see them all.
+	allTenants := s.ctx.WithAllTenants()
+
+	total, err = s.repository.Count(allTenants)
+	s.Require().NoError(err)
+	s.Equal(2, total)
+
+	stored, err = s.repository.GetById(allTenants, "flow-b")
+	s.Require().NoError(err)
+	s.Equal("tenant-b", stored.Tenant)
+
+	// they keep the tenant of the flows they save.
+	other := s.newFlow("flow-c")
+	other.Tenant = "tenant-c"
+	s.Require().NoError(s.repository.Save(allTenants, other))
+
+	stored, err = s.repository.GetById(s.ctx.WithTenant("tenant-c"), "flow-c")
+	s.Require().NoError(err)
+	s.Require().NotNil(stored)
+	s.Equal("tenant-c", stored.Tenant)
+}
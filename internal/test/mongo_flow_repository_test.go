@@ -0,0 +1,125 @@
+package test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/internal/database/mongodb"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMongoFlowRepository(t *testing.T) {
+	url := os.Getenv(EnvTestMongoUrl)
+	if url == "" {
+		t.Skip(EnvTestMongoUrl + " is not set")
+	}
+
+	t.Setenv(mongodb.EnvMongoUrl, url)
+	t.Setenv(mongodb.EnvMongoDatabase, "yrn_contract_test")
+
+	suite.Run(t, new(MongoFlowRepositoryTestSuite))
+}
+
+type MongoFlowRepositoryTestSuite struct {
+	suite.Suite
+	ctx        *yctx.Context
+	client     *mongodb.Client
+	repository *mongodb.FlowRepository
+}
+
+func (s *MongoFlowRepositoryTestSuite) SetupTest() {
+	s.ctx = yctx.NewContext(context.Background())
+
+	client, err := mongodb.NewClient(s.ctx)
+	s.Require().NoError(err)
+	s.client = client
+
+	s.Require().NoError(client.Collection(mongodb.CollectionFlowName).Drop(s.ctx.Context()))
+
+	s.repository = mongodb.NewFlowRepository(client)
+	s.Require().NoError(s.repository.CreateIndexes(s.ctx))
+}
+
+func (s *MongoFlowRepositoryTestSuite) TearDownTest() {
+	_ = s.client.Disconnect(s.ctx)
+}
+
+func (s *MongoFlowRepositoryTestSuite) TestSave_GeneratesIdAndMapsFields() {
+	flow := &flowmanager.Flow{Name: "Orders", FirstPluginToRun: "fetch", Version: 1}
+	s.Require().NoError(s.repository.Save(s.ctx, flow))
+	s.NotEmpty(flow.Id)
+
+	var document bson.M
+	s.Require().NoError(s.client.Collection(mongodb.CollectionFlowName).
+		FindOne(s.ctx.Context(), bson.M{"_id": flow.Id}).
+		Decode(&document))
+	s.Equal("fetch", document["first_plugin_to_run"])
+	s.NotContains(document, "id")
+
+	stored, err := s.repository.GetById(s.ctx, flow.Id)
+	s.Require().NoError(err)
+	s.Require().NotNil(stored)
+	s.Equal("Orders", stored.Name)
+}
+
+func (s *MongoFlowRepositoryTestSuite) TestMigrateLegacyFlows() {
+	legacyId := primitive.NewObjectID()
+
+	// written by the driver before the fields were mapped to bson.
+	_, err := s.client.Collection(mongodb.CollectionFlowName).InsertOne(s.ctx.Context(), bson.M{
+		"_id":              legacyId,
+		"id":               "legacy-1",
+		"name":             "Legacy",
+		"tenant":           "tenant-a",
+		"firstplugintorun": "fetch",
+		"plugins": bson.A{bson.M{
+			"id":               "fetch",
+			"slug":             "http",
+			"schemainput":      `{"request": {"method": "GET"}}`,
+			"nexttobeexecuted": bson.A{},
+		}},
+		"version":    1,
+		"deployment": bson.M{"status": "IN_OPERATION", "updatedby": "alice"},
+	})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.repository.MigrateLegacyFlows(s.ctx))
+	s.Require().NoError(s.repository.MigrateLegacyFlows(s.ctx))
+
+	stored, err := s.repository.GetById(s.ctx, "legacy-1")
+	s.Require().NoError(err)
+	s.Require().NotNil(stored)
+	s.Equal("Legacy", stored.Name)
+	s.Equal("fetch", stored.FirstPluginToRun)
+	s.Require().Len(stored.Plugins, 1)
+	s.Equal(`{"request": {"method": "GET"}}`, stored.Plugins[0].SchemaInput)
+	s.Require().NotNil(stored.Deployment)
+	s.Equal("alice", stored.Deployment.UpdatedBy)
+	s.Equal(legacyId.Timestamp().UTC(), stored.CreatedAt)
+
+	count, err := s.client.Collection(mongodb.CollectionFlowName).CountDocuments(s.ctx.Context(), bson.M{})
+	s.Require().NoError(err)
+	s.EqualValues(1, count)
+}
+
+func (s *MongoFlowRepositoryTestSuite) TestGetAll_ListsInCreationOrder() {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, id := range []string{"c", "a", "b"} {
+		s.Require().NoError(s.repository.Save(s.ctx, &flowmanager.Flow{
+			Id:        id,
+			CreatedAt: createdAt.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	items, err := s.repository.GetAll(s.ctx, &flowmanager.Pagination{Size: 10})
+	s.Require().NoError(err)
+	s.Require().Len(items, 3)
+	s.Equal([]string{"c", "a", "b"}, []string{items[0].Id, items[1].Id, items[2].Id})
+}
@@ -0,0 +1,42 @@
+package flowmanager
+
+import (
+	"sync"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var _ DeployAuditRepository = (*InMemoryDeployAuditRepository)(nil)
+
+// InMemoryDeployAuditRepository implementa DeployAuditRepository usando memória
+type InMemoryDeployAuditRepository struct {
+	events []DeployEvent
+	mu     sync.RWMutex
+}
+
+// NewInMemoryDeployAuditRepository cria uma nova instância do repositório em memória
+func NewInMemoryDeployAuditRepository() *InMemoryDeployAuditRepository {
+	return &InMemoryDeployAuditRepository{}
+}
+
+func (r *InMemoryDeployAuditRepository) Save(ctx *yctx.Context, event *DeployEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *InMemoryDeployAuditRepository) GetByFlowId(ctx *yctx.Context, flowId string) ([]DeployEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]DeployEvent, 0)
+	for _, event := range r.events {
+		if event.FlowId == flowId {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
@@ -8,7 +8,9 @@ import (
 
 	"sync"
 
+	"github.com/yrn-go/yrn/pkg/plugincore"
 	"github.com/yrn-go/yrn/pkg/yctx"
+	"github.com/yrn-go/yrn/pkg/yredact"
 	"golang.org/x/exp/slog"
 )
 
@@ -25,6 +27,16 @@ type EventProducerBody struct {
 	SharedForAll map[string]any `json:"sharedForAll"`
 }
 
+// OutputValidationError indica que a saída de um plugin não corresponde ao seu schema de saída
+type OutputValidationError struct {
+	PluginID string
+	Errors   []plugincore.FieldError
+}
+
+func (e *OutputValidationError) Error() string {
+	return fmt.Sprintf("plugin %s output %s", e.PluginID, (&plugincore.ValidationError{Errors: e.Errors}).Error())
+}
+
 // PluginMetrics contém métricas de execução de um plugin
 type PluginMetrics struct {
 	StartTime     time.Time
@@ -38,18 +50,31 @@ type PluginMetrics struct {
 
 // PluginStatus representa o status atual de um plugin
 type PluginStatus struct {
-	PluginID   string
-	Status     string
-	StartTime  time.Time
-	EndTime    time.Time
-	Error      error
-	Metrics    PluginMetrics
-	Input      any
-	Output     any
-	SharedData map[string]any
+	Tenant      string
+	FlowID      string
+	FlowVersion int
+	ExecutionID string
+	PluginID    string
+	// Attempt é o número do evento recebido pelo plugin na execução
+	Attempt          int
+	Status           string
+	StartTime        time.Time
+	EndTime          time.Time
+	Error            error `json:"-"`
+	ErrorMessage     string
+	ValidationErrors []plugincore.FieldError
+	Metrics          PluginMetrics
+	Input            any
+	Output           any
+	SharedData       map[string]any
+	// Metadata contém os valores que o plugin registrou em ctx.Values()
+	Metadata map[string]any
 }
 
-// PluginStatusRepository define a interface para o repositório de status
+// PluginStatusRepository define a interface para o repositório de status. Cada
+// execução do fluxo tem o seu status por plugin, o da última tentativa, e GetByPluginID busca o da
+// execução do contexto (ctx.WithExecution). As leituras retornam apenas os
+// status do tenant do contexto, quando ele tem um.
 type PluginStatusRepository interface {
 	Save(ctx *yctx.Context, status PluginStatus) error
 	GetByPluginID(ctx *yctx.Context, pluginID string) (PluginStatus, error)
@@ -58,11 +83,16 @@ type PluginStatusRepository interface {
 
 // EventManager gerencia a execução de plugins em um fluxo
 type EventManager struct {
+	tenant               string
+	flowId               string
+	flowVersion          int
 	pluginManager        PluginManager
 	plugins              map[string]FlowPlugin
 	numberOfPluginsToRun int
 	metrics              map[string]PluginMetrics
 	statusRepo           PluginStatusRepository
+	redactor             *yredact.Redactor
+	sensitivePaths       map[string][]string
 }
 
 // NewEventManager cria uma nova instância do EventManager
@@ -79,9 +109,18 @@ func NewEventManager(
 		numberOfPluginsToRun: 0,
 		metrics:              metrics,
 		statusRepo:           statusRepo,
+		redactor:             yredact.NewRedactorFromEnv(),
+		sensitivePaths:       make(map[string][]string),
 	}
 }
 
+// SetFlow define o fluxo, a versão e o tenant registrados no status dos plugins
+func (e *EventManager) SetFlow(flowId string, version int, tenant string) {
+	e.tenant = tenant
+	e.flowId = flowId
+	e.flowVersion = version
+}
+
 // Register registra um novo plugin no EventManager
 func (e *EventManager) Register(pluginExecutor FlowPlugin) error {
 	if pluginExecutor.Id == "" {
@@ -114,23 +153,123 @@ func getCPUUsage() time.Duration {
 	return time.Duration(runtime.NumGoroutine()) * time.Millisecond
 }
 
-// savePluginStatus salva o status atual do plugin
+// savePluginStatus salva o status atual do plugin, com os dados sensíveis ocultados
 func (e *EventManager) savePluginStatus(ctx *yctx.Context, pluginID string, status string, input, output any, err error, metrics PluginMetrics, sharedData map[string]any) error {
 	pluginStatus := PluginStatus{
-		PluginID:   pluginID,
-		Status:     status,
-		StartTime:  metrics.StartTime,
-		EndTime:    metrics.EndTime,
-		Error:      err,
-		Metrics:    metrics,
-		Input:      input,
-		Output:     output,
-		SharedData: sharedData,
+		Tenant:      e.tenant,
+		FlowID:      e.flowId,
+		FlowVersion: e.flowVersion,
+		ExecutionID: ctx.ExecutionId(),
+		PluginID:    pluginID,
+		Attempt:     ctx.Attempt(),
+		Status:      status,
+		StartTime:   metrics.StartTime,
+		EndTime:     metrics.EndTime,
+		Error:       err,
+		Metrics:     metrics,
+		Input:       e.redactor.Redact(input, e.inputSensitivePaths()...),
+		Output:      e.redactor.Redact(output, e.sensitivePaths[pluginID]...),
+		SharedData:  e.redactSharedData(sharedData),
+	}
+
+	pluginStatus.Metadata = e.redactMetadata(ctx.Values().All())
+
+	if err != nil {
+		var (
+			validationErr       *plugincore.ValidationError
+			outputValidationErr *OutputValidationError
+		)
+
+		pluginStatus.ErrorMessage = e.redactor.RedactString(err.Error())
+
+		switch {
+		case errors.As(err, &validationErr):
+			pluginStatus.ValidationErrors = e.redactFieldErrors(validationErr.Errors)
+		case errors.As(err, &outputValidationErr):
+			pluginStatus.ValidationErrors = e.redactFieldErrors(outputValidationErr.Errors)
+		}
 	}
 
 	return e.statusRepo.Save(ctx, pluginStatus)
 }
 
+// AddSensitiveValues registra valores, como secrets resolvidos, que devem ser ocultados no status e nos logs
+func (e *EventManager) AddSensitiveValues(values ...string) {
+	e.redactor.AddValues(values...)
+}
+
+// registerSensitivePaths guarda os campos marcados como "sensitive" no schema de saída do plugin
+func (e *EventManager) registerSensitivePaths(ctx *yctx.Context, pluginID string, pluginExecutor PluginExecutor) {
+	schemaProvider, ok := pluginExecutor.(PluginOutputSchemaProvider)
+	if !ok {
+		return
+	}
+
+	paths, err := yredact.SensitivePaths(schemaProvider.OutputSchema())
+	if err != nil {
+		ctx.Logger().Warn("invalid plugin output schema",
+			slog.String("plugin_id", pluginID),
+			slog.String("error", e.redactor.RedactString(err.Error())))
+		return
+	}
+
+	e.sensitivePaths[pluginID] = paths
+}
+
+// inputSensitivePaths retorna os campos sensíveis de todos os plugins, pois a entrada vem da saída dos plugins anteriores
+func (e *EventManager) inputSensitivePaths() (paths []string) {
+	for _, pluginPaths := range e.sensitivePaths {
+		paths = append(paths, pluginPaths...)
+	}
+
+	return paths
+}
+
+func (e *EventManager) redactSharedData(sharedData map[string]any) map[string]any {
+	if sharedData == nil {
+		return nil
+	}
+
+	redacted := make(map[string]any, len(sharedData))
+	for pluginID, value := range sharedData {
+		redacted[pluginID] = e.redactor.Redact(value, e.sensitivePaths[pluginID]...)
+	}
+
+	return redacted
+}
+
+// redactMetadata oculta cada valor registrado pelo plugin separadamente. Os
+// valores passam por JSON, então structs e mapas tipados também são ocultados,
+// e um valor que não pode ser convertido vira yredact.Placeholder em vez de
+// descartar os demais.
+func (e *EventManager) redactMetadata(metadata map[string]any) map[string]any {
+	if metadata == nil {
+		return nil
+	}
+
+	redacted := make(map[string]any, len(metadata))
+	for key, value := range metadata {
+		if e.redactor.IsSensitiveField(key) {
+			redacted[key] = yredact.Placeholder
+			continue
+		}
+
+		redacted[key] = e.redactor.Redact(value)
+	}
+
+	return redacted
+}
+
+func (e *EventManager) redactFieldErrors(fieldErrors []plugincore.FieldError) []plugincore.FieldError {
+	redacted := make([]plugincore.FieldError, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		fieldError.Message = e.redactor.RedactString(fieldError.Message)
+		redacted = append(redacted, fieldError)
+	}
+
+	return redacted
+}
+
 // Execute inicia a execução do fluxo de plugins
 func (e *EventManager) Execute(ctx *yctx.Context, firstPluginIdToExecute string, eventRequestData any) (any, error) {
 	var (
@@ -142,7 +281,7 @@ func (e *EventManager) Execute(ctx *yctx.Context, firstPluginIdToExecute string,
 	defer func() {
 		// Log das métricas finais
 		for pluginID, metrics := range e.metrics {
-			slog.Info("plugin execution metrics",
+			ctx.Logger().Info("plugin execution metrics",
 				slog.String("plugin_id", pluginID),
 				slog.Duration("execution_time", metrics.ExecutionTime),
 				slog.Uint64("memory_usage_bytes", metrics.MemoryAfter-metrics.MemoryBefore),
@@ -156,12 +295,14 @@ func (e *EventManager) Execute(ctx *yctx.Context, firstPluginIdToExecute string,
 	for slug, pluginInfo := range e.plugins {
 		pluginExecutor, err := e.pluginManager.GetBySlug(ctx, pluginInfo.Slug)
 		if err != nil {
-			slog.Error("failed to get plugin executor",
+			ctx.Logger().Error("failed to get plugin executor",
 				slog.String("plugin_slug", pluginInfo.Slug),
 				slog.Any("error", err))
 			return nil, fmt.Errorf("failed to get plugin executor for %s: %w", pluginInfo.Slug, err)
 		}
 
+		e.registerSensitivePaths(ctx, pluginInfo.Id, pluginExecutor)
+
 		pluginEventProducer.Store(
 			slug,
 			e.handler(
@@ -195,9 +336,9 @@ func (e *EventManager) Execute(ctx *yctx.Context, firstPluginIdToExecute string,
 		case result := <-processResult:
 			completed++
 			if result.Error != nil {
-				slog.Error("plugin execution failed",
+				ctx.Logger().Error("plugin execution failed",
 					slog.String("plugin_id", result.Id),
-					slog.Any("error", result.Error))
+					slog.String("error", e.redactor.RedactString(result.Error.Error())))
 				err = result.Error
 			}
 			finalResponse = result.Output
@@ -205,7 +346,9 @@ func (e *EventManager) Execute(ctx *yctx.Context, firstPluginIdToExecute string,
 	}
 
 	close(done)
-	return finalResponse, err
+	// o erro é registrado pelos gatilhos (webhook, agendamento, fila) e
+	// devolvido pela API, então os valores dos secrets são ocultados
+	return finalResponse, e.redactor.RedactError(err)
 }
 
 // handler gerencia a execução de um plugin específico
@@ -238,26 +381,32 @@ func (e *EventManager) handler(
 					err    error
 				)
 
+				// Cada execução do plugin tem seu próprio contexto, com logger e metadados. A
+				// tentativa é o número do evento recebido pelo plugin nesta execução do fluxo
+				pluginCtx := ctx.WithPlugin(pluginInfo.Id, parentPluginsExecuted).WithValues()
+
 				// Salva status inicial
 				metrics := PluginMetrics{
 					StartTime:    startTime,
 					MemoryBefore: memoryBefore,
 					CPUBefore:    cpuBefore,
 				}
-				_ = e.savePluginStatus(ctx, pluginInfo.Id, "started", body, nil, nil, metrics, syncMapToMap(responseSharedForAll))
+				_ = e.savePluginStatus(pluginCtx, pluginInfo.Id, "started", body, nil, nil, metrics, syncMapToMap(responseSharedForAll))
 
 				// Executa o plugin com tratamento de panic
 				func() {
 					defer func() {
 						if r := recover(); r != nil {
-							slog.Error("panic in plugin handler",
-								slog.String("plugin_id", pluginInfo.Id),
+							pluginCtx.Logger().Error("panic in plugin handler",
 								slog.Any("recover", r))
 							err = fmt.Errorf("panic recovered: %v", r)
 						}
 					}()
 
-					output, err = pluginExecutor.Do(ctx, pluginInfo.SchemaInput, body, syncMapToMap(responseSharedForAll))
+					output, err = pluginExecutor.Do(plugincore.WithTemplateMode(pluginCtx, pluginInfo.TemplateMode), pluginInfo.SchemaInput, body, syncMapToMap(responseSharedForAll))
+					if err == nil {
+						err = e.validatePluginOutput(pluginCtx, pluginExecutor, pluginInfo, output)
+					}
 				}()
 
 				// Finaliza coleta de métricas
@@ -280,7 +429,7 @@ func (e *EventManager) handler(
 				e.metrics[pluginInfo.Id] = metrics
 
 				// Salva status final
-				_ = e.savePluginStatus(ctx, pluginInfo.Id, "completed", body, output, err, metrics, syncMapToMap(responseSharedForAll))
+				_ = e.savePluginStatus(pluginCtx, pluginInfo.Id, "completed", body, output, err, metrics, syncMapToMap(responseSharedForAll))
 
 				result := EventManagerProcessResult{
 					Id:     pluginInfo.Id,
@@ -297,14 +446,14 @@ func (e *EventManager) handler(
 						if ch, ok := pluginEventProducer.Load(slugNextToBeExecuted); ok {
 							ch.(chan<- any) <- output
 						} else {
-							slog.Warn("next plugin not found",
+							pluginCtx.Logger().Warn("next plugin not found",
 								slog.String("current_plugin", pluginInfo.Id),
 								slog.String("next_plugin", slugNextToBeExecuted))
 						}
 					}
 				}
 			case <-done:
-				slog.Info("closing handler",
+				ctx.Logger().Info("closing handler",
 					slog.String("plugin_id", pluginInfo.Id),
 					slog.Int("executions", parentPluginsExecuted))
 				return
@@ -315,6 +464,31 @@ func (e *EventManager) handler(
 	return eventProducer
 }
 
+// validatePluginOutput valida a saída do plugin contra o seu schema de saída, quando existir.
+// No modo warn os erros são registrados no logger do plugin, com os dados sensíveis ocultados
+func (e *EventManager) validatePluginOutput(pluginCtx *yctx.Context, pluginExecutor PluginExecutor, pluginInfo FlowPlugin, output any) error {
+	schemaProvider, ok := pluginExecutor.(PluginOutputSchemaProvider)
+	if !ok || pluginInfo.OutputValidation == OutputValidationOff || len(schemaProvider.OutputSchema()) == 0 {
+		return nil
+	}
+
+	err := plugincore.ValidateOutput(schemaProvider.OutputSchema(), output)
+
+	var validationErr *plugincore.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	if pluginInfo.OutputValidation == OutputValidationStrict {
+		return &OutputValidationError{PluginID: pluginInfo.Id, Errors: validationErr.Errors}
+	}
+
+	pluginCtx.Logger().Warn("plugin output does not match its output schema",
+		slog.Any("errors", e.redactFieldErrors(validationErr.Errors)))
+
+	return nil
+}
+
 // syncMapToMap converte um sync.Map para map[string]any
 func syncMapToMap(m *sync.Map) map[string]any {
 	result := make(map[string]any)
@@ -1,14 +1,19 @@
 package flowmanager
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/plugincore"
 	"github.com/yrn-go/yrn/pkg/yctx"
+	"golang.org/x/exp/slog"
 )
 
 func TestEventManagerTestSuite(t *testing.T) {
@@ -151,6 +156,52 @@ func (s *EventManagerTestSuite) TestExecute_ShouldReturnSuccess() {
 	s.Equal(map[string]any{"success": true}, finalResponse)
 }
 
+func (s *EventManagerTestSuite) TestExecute_CountsAttemptsOfEachEvent() {
+	const pluginSlug = "plugin-http"
+
+	var (
+		mu           sync.Mutex
+		attempts     []int
+		executorMock = new(PluginExecutorMock)
+	)
+
+	_ = s.eventManager.Register(FlowPlugin{
+		Id:               "test1",
+		Slug:             pluginSlug,
+		SchemaInput:      `{"mock": true}`,
+		NextToBeExecuted: []string{"test2", "test2"},
+	})
+	_ = s.eventManager.Register(FlowPlugin{
+		Id:          "test2",
+		Slug:        pluginSlug,
+		SchemaInput: `{"mock": true}`,
+	})
+
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, pluginSlug).
+		Return(executorMock, nil)
+
+	executorMock.
+		On("Do", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(map[string]any{"success": true}, nil)
+
+	s.statusRepositoryMock.
+		On("Save", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			status := args.Get(1).(PluginStatus)
+			if status.PluginID == "test2" && status.Status == "completed" {
+				mu.Lock()
+				attempts = append(attempts, status.Attempt)
+				mu.Unlock()
+			}
+		}).
+		Return(nil)
+
+	_, err := s.eventManager.Execute(s.ctx, "test1", nil)
+	s.NoError(err)
+	s.Equal([]int{1, 2}, attempts)
+}
+
 func (s *EventManagerTestSuite) TestExecute_ShouldCollectMetrics() {
 	const pluginSlug = "plugin-http"
 	const pluginID = "test1"
@@ -321,3 +372,330 @@ func (s *EventManagerTestSuite) TestExecute_ShouldHandleLongRunningPlugin() {
 	s.True(exists)
 	s.GreaterOrEqual(metrics.ExecutionTime, 100*time.Millisecond)
 }
+
+func (s *EventManagerTestSuite) TestExecute_ShouldSaveValidationErrorsInStatus() {
+	const pluginSlug = "plugin-http"
+	const pluginID = "test1"
+
+	executorMock := new(PluginExecutorMock)
+
+	_ = s.eventManager.Register(FlowPlugin{
+		Id:          pluginID,
+		Slug:        pluginSlug,
+		SchemaInput: `{"mock": true}`,
+	})
+
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, pluginSlug).
+		Return(executorMock, nil)
+
+	validationErr := &plugincore.ValidationError{
+		Errors: []plugincore.FieldError{
+			{Field: "request.url", Rule: "required", Message: "url is required"},
+		},
+	}
+
+	executorMock.
+		On("Do", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, validationErr)
+
+	s.statusRepositoryMock.
+		On("Save", mock.Anything, mock.MatchedBy(func(status PluginStatus) bool {
+			return status.Status == "started"
+		})).
+		Return(nil).
+		Once()
+
+	s.statusRepositoryMock.
+		On("Save", mock.Anything, mock.MatchedBy(func(status PluginStatus) bool {
+			return status.Status == "completed" &&
+				status.ErrorMessage == validationErr.Error() &&
+				len(status.ValidationErrors) == 1 &&
+				status.ValidationErrors[0] == validationErr.Errors[0]
+		})).
+		Return(nil).
+		Once()
+
+	_, err := s.eventManager.Execute(s.ctx, pluginID, map[string]any{"input": "value"})
+	s.ErrorIs(err, validationErr)
+	s.statusRepositoryMock.AssertExpectations(s.T())
+}
+
+// pluginExecutorWithOutputSchemaMock é um PluginExecutorMock que expõe um schema de saída
+type pluginExecutorWithOutputSchemaMock struct {
+	*PluginExecutorMock
+	outputSchema []byte
+}
+
+func (m *pluginExecutorWithOutputSchemaMock) OutputSchema() []byte {
+	return m.outputSchema
+}
+
+func (s *EventManagerTestSuite) TestExecute_ShouldValidatePluginOutput() {
+	const pluginSlug = "plugin-http"
+
+	for mode, expectError := range map[OutputValidationMode]bool{
+		"":                     false,
+		OutputValidationWarn:   false,
+		OutputValidationOff:    false,
+		OutputValidationStrict: true,
+	} {
+		s.SetupTest()
+
+		executorMock := &pluginExecutorWithOutputSchemaMock{
+			PluginExecutorMock: new(PluginExecutorMock),
+			outputSchema:       []byte(`{"type": "object", "required": ["id"]}`),
+		}
+
+		_ = s.eventManager.Register(FlowPlugin{
+			Id:               "test1",
+			Slug:             pluginSlug,
+			SchemaInput:      `{"mock": true}`,
+			OutputValidation: mode,
+		})
+
+		s.pluginManagerMock.
+			On("GetBySlug", mock.Anything, pluginSlug).
+			Return(executorMock, nil)
+
+		executorMock.
+			On("Do", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(map[string]any{"success": true}, nil)
+
+		s.statusRepositoryMock.
+			On("Save", mock.Anything, mock.Anything).
+			Return(nil)
+
+		_, err := s.eventManager.Execute(s.ctx, "test1", map[string]any{"input": "value"})
+
+		if !expectError {
+			s.NoError(err, mode)
+			continue
+		}
+
+		var outputValidationErr *OutputValidationError
+		s.ErrorAs(err, &outputValidationErr, mode)
+		s.Equal("id", outputValidationErr.Errors[0].Field)
+	}
+}
+
+func (s *EventManagerTestSuite) TestExecute_WarnValidationLogsRedactedErrors() {
+	const pluginSlug = "plugin-http"
+
+	var (
+		logs         bytes.Buffer
+		executorMock = &pluginExecutorWithOutputSchemaMock{
+			PluginExecutorMock: new(PluginExecutorMock),
+			outputSchema:       []byte(`{"type": "object", "properties": {"token": {"enum": ["token-value"]}}}`),
+		}
+	)
+
+	_ = s.eventManager.Register(FlowPlugin{
+		Id:               "test1",
+		Slug:             pluginSlug,
+		SchemaInput:      `{"mock": true}`,
+		OutputValidation: OutputValidationWarn,
+	})
+	s.eventManager.AddSensitiveValues("token-value")
+
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, pluginSlug).
+		Return(executorMock, nil)
+
+	executorMock.
+		On("Do", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(map[string]any{"token": "other"}, nil)
+
+	s.statusRepositoryMock.
+		On("Save", mock.Anything, mock.Anything).
+		Return(nil)
+
+	ctx := s.ctx.WithExecution("flow-1", "execution-1").WithLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	_, err := s.eventManager.Execute(ctx, "test1", nil)
+	s.NoError(err)
+
+	s.Contains(logs.String(), "plugin output does not match its output schema")
+	s.Contains(logs.String(), "execution_id=execution-1")
+	s.Contains(logs.String(), "plugin_id=test1")
+	s.NotContains(logs.String(), "token-value")
+}
+
+func (s *EventManagerTestSuite) TestExecute_StrictValidationAcceptsUndeclaredFields() {
+	const pluginSlug = "plugin-http"
+
+	executorMock := &pluginExecutorWithOutputSchemaMock{
+		PluginExecutorMock: new(PluginExecutorMock),
+		outputSchema:       []byte(`{"type": "object", "properties": {"id": {"type": "string"}}, "required": ["id"]}`),
+	}
+
+	_ = s.eventManager.Register(FlowPlugin{
+		Id:               "test1",
+		Slug:             pluginSlug,
+		SchemaInput:      `{"mock": true}`,
+		OutputValidation: OutputValidationStrict,
+	})
+
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, pluginSlug).
+		Return(executorMock, nil)
+
+	executorMock.
+		On("Do", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(map[string]any{"id": "1", "extra": true}, nil)
+
+	s.statusRepositoryMock.
+		On("Save", mock.Anything, mock.Anything).
+		Return(nil)
+
+	_, err := s.eventManager.Execute(s.ctx, "test1", nil)
+	s.NoError(err)
+}
+
+func (s *EventManagerTestSuite) TestExecute_ShouldRedactSensitiveDataInStatus() {
+	const pluginSlug = "plugin-auth"
+
+	var (
+		savedStatuses []PluginStatus
+		executorMock  = &pluginExecutorWithOutputSchemaMock{
+			PluginExecutorMock: new(PluginExecutorMock),
+			outputSchema:       []byte(`{"type": "object", "properties": {"session": {"type": "string", "sensitive": true}}}`),
+		}
+	)
+
+	_ = s.eventManager.Register(FlowPlugin{
+		Id:                          "auth",
+		Slug:                        pluginSlug,
+		SchemaInput:                 `{"mock": true}`,
+		ShareResponseWithAllPlugins: true,
+	})
+
+	s.eventManager.AddSensitiveValues("resolved-secret")
+
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, pluginSlug).
+		Return(executorMock, nil)
+
+	executorMock.
+		On("Do", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(map[string]any{
+			"session":      "session-value",
+			"access_token": "token-value",
+			"url":          "https://api.example.com/?key=resolved-secret",
+			"name":         "john",
+		}, nil)
+
+	s.statusRepositoryMock.
+		On("Save", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			savedStatuses = append(savedStatuses, args.Get(1).(PluginStatus))
+		}).
+		Return(nil)
+
+	output, err := s.eventManager.Execute(s.ctx, "auth", map[string]any{"password": "123456"})
+	s.NoError(err)
+	s.Equal("session-value", output.(map[string]any)["session"])
+
+	s.Len(savedStatuses, 2)
+	s.Equal(map[string]any{"password": "[REDACTED]"}, savedStatuses[1].Input)
+	s.Equal(map[string]any{
+		"session":      "[REDACTED]",
+		"access_token": "[REDACTED]",
+		"url":          "https://api.example.com/?key=[REDACTED]",
+		"name":         "john",
+	}, savedStatuses[1].Output)
+}
+
+func (s *EventManagerTestSuite) TestExecute_ShouldRedactSecretsInReturnedError() {
+	const pluginSlug = "plugin-http"
+
+	var (
+		executorMock = new(PluginExecutorMock)
+		pluginErr    = errors.New("request to https://api.example.com/?key=resolved-secret failed")
+	)
+
+	_ = s.eventManager.Register(FlowPlugin{
+		Id:          "http",
+		Slug:        pluginSlug,
+		SchemaInput: `{"mock": true}`,
+	})
+
+	s.eventManager.AddSensitiveValues("resolved-secret")
+
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, pluginSlug).
+		Return(executorMock, nil)
+
+	executorMock.
+		On("Do", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, pluginErr)
+
+	s.statusRepositoryMock.
+		On("Save", mock.Anything, mock.Anything).
+		Return(nil)
+
+	_, err := s.eventManager.Execute(s.ctx, "http", map[string]any{})
+	s.ErrorIs(err, pluginErr)
+	s.NotContains(err.Error(), "resolved-secret")
+	s.Contains(err.Error(), "key=[REDACTED]")
+}
+
+func (s *EventManagerTestSuite) TestExecute_ShouldPassExecutionMetadataToPlugin() {
+	const pluginSlug = "plugin-http"
+
+	var (
+		pluginCtx     *yctx.Context
+		savedStatuses []PluginStatus
+		executorMock  = new(PluginExecutorMock)
+	)
+
+	_ = s.eventManager.Register(FlowPlugin{
+		Id:          "request",
+		Slug:        pluginSlug,
+		SchemaInput: `{"mock": true}`,
+	})
+
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, pluginSlug).
+		Return(executorMock, nil)
+
+	executorMock.
+		On("Do", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			pluginCtx = args.Get(0).(*yctx.Context)
+			pluginCtx.Values().Set("status_code", 200)
+			pluginCtx.Values().Set("api_key", "key-value")
+			pluginCtx.Values().Set("headers", map[string]string{"Authorization": "Bearer token"})
+			pluginCtx.Values().Set("done", make(chan struct{}))
+		}).
+		Return(map[string]any{"success": true}, nil)
+
+	s.statusRepositoryMock.
+		On("Save", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			savedStatuses = append(savedStatuses, args.Get(1).(PluginStatus))
+		}).
+		Return(nil)
+
+	ctx := s.ctx.WithTenant("tenant-a").WithExecution("flow-1", "execution-1")
+
+	_, err := s.eventManager.Execute(ctx, "request", nil)
+	s.NoError(err)
+
+	s.Require().NotNil(pluginCtx)
+	s.Equal("tenant-a", pluginCtx.Tenant())
+	s.Equal("flow-1", pluginCtx.FlowId())
+	s.Equal("execution-1", pluginCtx.ExecutionId())
+	s.Equal("request", pluginCtx.PluginId())
+	s.Equal(1, pluginCtx.Attempt())
+
+	s.Len(savedStatuses, 2)
+	s.Equal("execution-1", savedStatuses[1].ExecutionID)
+	s.Nil(savedStatuses[0].Metadata)
+	s.Equal(map[string]any{
+		"status_code": 200.0,
+		"api_key":     "[REDACTED]",
+		"headers":     map[string]any{"Authorization": "[REDACTED]"},
+		"done":        "[REDACTED]",
+	}, savedStatuses[1].Metadata)
+}
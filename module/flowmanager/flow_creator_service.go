@@ -1,19 +1,73 @@
 package flowmanager
 
 import (
+	"github.com/google/uuid"
 	"github.com/yrn-go/yrn/pkg/yctx"
 )
 
 type (
 	FlowWriteRepository interface {
 		Save(ctx *yctx.Context, flow *Flow) error
+		// Update replaces the definition of the stored flow, keeping its
+		// deployment, when the stored revision is still expectedRevision.
+		// Otherwise it returns ErrFlowVersionConflict.
+		Update(ctx *yctx.Context, flow *Flow, expectedRevision int) error
+		// Delete soft deletes the flow. It returns ErrFlowNotFound when the
+		// flow does not exist or is already deleted.
+		Delete(ctx *yctx.Context, id string) error
+		// Restore undoes Delete. It returns ErrFlowNotFound when there is
+		// no deleted flow with the id.
+		Restore(ctx *yctx.Context, id string) error
 	}
 )
 
 type FlowCreator struct {
 	flowWriteRepository FlowWriteRepository
+	flowVersioner       *FlowVersioner
+	flowValidator       *FlowValidator
 }
 
+func NewFlowCreator(flowWriteRepository FlowWriteRepository, flowVersioner *FlowVersioner, flowValidator *FlowValidator) *FlowCreator {
+	return &FlowCreator{
+		flowWriteRepository: flowWriteRepository,
+		flowVersioner:       flowVersioner,
+		flowValidator:       flowValidator,
+	}
+}
+
+// CreateFlow validates the flow and saves it as its version 1, with a new id
+// when none is given, in the tenant of the context. Only the contexts that
+// access every tenant keep the tenant of the flow. When problems are found, a
+// *FlowValidationError with the report is returned and nothing is saved.
 func (f *FlowCreator) CreateFlow(ctx *yctx.Context, flow *Flow) error {
-	return f.flowWriteRepository.Save(ctx, flow)
+	report, err := f.flowValidator.Validate(ctx, flow)
+	if err != nil {
+		return err
+	}
+
+	if !report.Valid() {
+		return &FlowValidationError{Report: report}
+	}
+
+	if flow.Id == "" {
+		flow.Id = uuid.NewString()
+	}
+
+	// only the contexts that access every tenant choose the tenant.
+	if !ctx.HasAllTenants() {
+		flow.Tenant = ctx.Tenant()
+	}
+
+	flow.Version = 1
+	flow.Revision = 1
+	flow.Deployment = nil
+	flow.DeletedAt = nil
+	flow.CreatedAt = f.flowVersioner.now().UTC()
+	flow.UpdatedAt = flow.CreatedAt
+
+	if err = f.flowWriteRepository.Save(ctx, flow); err != nil {
+		return err
+	}
+
+	return f.flowVersioner.saveVersion(ctx, flow)
 }
@@ -0,0 +1,74 @@
+package flowmanager
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+// ErrFlowDeployed is returned when deleting a flow whose triggers may be
+// running. The flow must be undeployed first.
+var ErrFlowDeployed = errors.New("flow is deployed")
+
+// runningDeployStatuses are the statuses in which the triggers of a flow may
+// be running.
+var runningDeployStatuses = []ybase.DeployStatus{
+	ybase.DeployStatusInProgress,
+	ybase.DeployStatusInOperation,
+	ybase.DeployStatusRollback,
+}
+
+type FlowDeleter struct {
+	flowReaderRepository FlowReaderRepository
+	flowWriteRepository  FlowWriteRepository
+}
+
+func NewFlowDeleter(flowReaderRepository FlowReaderRepository, flowWriteRepository FlowWriteRepository) *FlowDeleter {
+	return &FlowDeleter{
+		flowReaderRepository: flowReaderRepository,
+		flowWriteRepository:  flowWriteRepository,
+	}
+}
+
+// Delete soft deletes the flow. Its versions and deploy history are kept, so
+// Restore brings it back as it was.
+func (d *FlowDeleter) Delete(ctx *yctx.Context, id string) error {
+	flow, err := d.flowReaderRepository.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if flow == nil {
+		return ErrFlowNotFound
+	}
+
+	if status := flow.DeployStatus(); slices.Contains(runningDeployStatuses, status) {
+		return fmt.Errorf("%w: status is %s", ErrFlowDeployed, status)
+	}
+
+	return d.flowWriteRepository.Delete(ctx, id)
+}
+
+func (d *FlowDeleter) Restore(ctx *yctx.Context, id string) (*Flow, error) {
+	if err := d.flowWriteRepository.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	flow, err := d.flowReaderRepository.GetById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if flow == nil {
+		return nil, ErrFlowNotFound
+	}
+
+	return flow, nil
+}
+
+func (d *FlowDeleter) Deleted(ctx *yctx.Context, pagination *Pagination) ([]Flow, error) {
+	return d.flowReaderRepository.GetDeleted(ctx, pagination)
+}
@@ -0,0 +1,61 @@
+package flowmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestFlowDeleterTestSuite(t *testing.T) {
+	suite.Run(t, new(FlowDeleterTestSuite))
+}
+
+type FlowDeleterTestSuite struct {
+	suite.Suite
+	ctx         *yctx.Context
+	repository  *flowDeployRepositoryFake
+	flowDeleter *FlowDeleter
+}
+
+func (s *FlowDeleterTestSuite) SetupTest() {
+	s.ctx = yctx.NewContext(context.Background())
+	s.repository = &flowDeployRepositoryFake{flow: Flow{Id: "flow-1", Version: 3}}
+	s.flowDeleter = NewFlowDeleter(s.repository, s.repository)
+}
+
+func (s *FlowDeleterTestSuite) TestDelete_HidesFlowUntilRestored() {
+	s.Require().NoError(s.flowDeleter.Delete(s.ctx, "flow-1"))
+
+	flow, err := s.repository.GetById(s.ctx, "flow-1")
+	s.NoError(err)
+	s.Nil(flow)
+
+	deleted, err := s.flowDeleter.Deleted(s.ctx, &Pagination{Size: 10})
+	s.NoError(err)
+	s.Len(deleted, 1)
+
+	s.ErrorIs(s.flowDeleter.Delete(s.ctx, "flow-1"), ErrFlowNotFound)
+
+	flow, err = s.flowDeleter.Restore(s.ctx, "flow-1")
+	s.Require().NoError(err)
+	s.Equal(3, flow.Version)
+	s.Nil(flow.DeletedAt)
+
+	_, err = s.flowDeleter.Restore(s.ctx, "flow-1")
+	s.ErrorIs(err, ErrFlowNotFound)
+}
+
+func (s *FlowDeleterTestSuite) TestDelete_RefusesDeployedFlow() {
+	s.repository.flow.Deployment = &FlowDeployment{Status: ybase.DeployStatusInOperation}
+	s.ErrorIs(s.flowDeleter.Delete(s.ctx, "flow-1"), ErrFlowDeployed)
+
+	s.repository.flow.Deployment = &FlowDeployment{Status: ybase.DeployStatusCanceled}
+	s.NoError(s.flowDeleter.Delete(s.ctx, "flow-1"))
+}
+
+func (s *FlowDeleterTestSuite) TestDelete_FlowNotFound() {
+	s.ErrorIs(s.flowDeleter.Delete(s.ctx, "missing"), ErrFlowNotFound)
+}
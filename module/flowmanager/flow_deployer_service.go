@@ -0,0 +1,294 @@
+package flowmanager
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"golang.org/x/exp/slog"
+)
+
+var (
+	ErrInvalidDeployTransition = errors.New("invalid deploy transition")
+	// ErrDeployConflict is returned when the deploy status changed since the
+	// flow was read, usually by a concurrent deploy action.
+	ErrDeployConflict = errors.New("deploy status changed concurrently")
+)
+
+// deployTransitions lists the statuses each status can move to.
+// IN_PROGRESS and ROLLBACK are held while the triggers are started or stopped.
+var deployTransitions = map[ybase.DeployStatus][]ybase.DeployStatus{
+	ybase.DeployStatusPending:     {ybase.DeployStatusInProgress},
+	ybase.DeployStatusInProgress:  {ybase.DeployStatusInOperation, ybase.DeployStatusFailed, ybase.DeployStatusCanceled},
+	ybase.DeployStatusInOperation: {ybase.DeployStatusInProgress, ybase.DeployStatusRollback, ybase.DeployStatusCanceled},
+	ybase.DeployStatusFailed:      {ybase.DeployStatusInProgress, ybase.DeployStatusRollback, ybase.DeployStatusCanceled},
+	ybase.DeployStatusRollback:    {ybase.DeployStatusInOperation, ybase.DeployStatusFailed, ybase.DeployStatusCanceled},
+	ybase.DeployStatusCanceled:    {ybase.DeployStatusInProgress},
+}
+
+type (
+	// FlowDeployRepository stores the deploy state of flows.
+	FlowDeployRepository interface {
+		// SaveDeployment replaces the deployment of the flow when its current
+		// status is expected, and returns ErrDeployConflict otherwise. A flow
+		// without deployment has the status ybase.DeployStatusPending.
+		SaveDeployment(ctx *yctx.Context, flowId string, expected ybase.DeployStatus, deployment *FlowDeployment) error
+	}
+
+	DeployAuditRepository interface {
+		Save(ctx *yctx.Context, event *DeployEvent) error
+		// GetByFlowId returns the events of the flow, oldest first.
+		GetByFlowId(ctx *yctx.Context, flowId string) (items []DeployEvent, err error)
+	}
+
+	// TriggerDispatcher starts and stops the listeners of the triggers of a
+	// flow. It is implemented by trigger.Dispatcher.
+	TriggerDispatcher interface {
+		Start(ctx *yctx.Context, flow *Flow) error
+		Stop(ctx *yctx.Context, flow *Flow) error
+	}
+
+	FlowDeployer struct {
+		flowReaderRepository  FlowReaderRepository
+		flowDeployRepository  FlowDeployRepository
+		deployAuditRepository DeployAuditRepository
+		triggerDispatcher     TriggerDispatcher
+		flowVersioner         *FlowVersioner
+		now                   func() time.Time
+	}
+)
+
+func NewFlowDeployer(
+	flowReaderRepository FlowReaderRepository,
+	flowDeployRepository FlowDeployRepository,
+	deployAuditRepository DeployAuditRepository,
+	triggerDispatcher TriggerDispatcher,
+	flowVersioner *FlowVersioner,
+) *FlowDeployer {
+	return &FlowDeployer{
+		flowReaderRepository:  flowReaderRepository,
+		flowDeployRepository:  flowDeployRepository,
+		deployAuditRepository: deployAuditRepository,
+		triggerDispatcher:     triggerDispatcher,
+		flowVersioner:         flowVersioner,
+		now:                   time.Now,
+	}
+}
+
+// Deploy starts the triggers of the active version of the flow. The flow is
+// IN_PROGRESS while they start, then IN_OPERATION, or FAILED when a trigger
+// fails to start.
+func (d *FlowDeployer) Deploy(ctx *yctx.Context, flowId, actor string) (*FlowDeployment, error) {
+	flow, err := d.getFlow(ctx, flowId)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &FlowDeployment{Triggers: flow.Triggers, Version: flow.Version}
+
+	return d.run(ctx, flow, actor, DeployActionDeploy, ybase.DeployStatusInProgress, target, lastInOperation(flow))
+}
+
+// Undeploy stops the triggers of the flow and moves it to CANCELED.
+func (d *FlowDeployer) Undeploy(ctx *yctx.Context, flowId, actor string) (*FlowDeployment, error) {
+	flow, err := d.getFlow(ctx, flowId)
+	if err != nil {
+		return nil, err
+	}
+
+	from := flow.DeployStatus()
+
+	deployment := d.newDeployment(ybase.DeployStatusCanceled, actor, nil, lastInOperation(flow))
+	if err = d.transition(ctx, flow, from, deployment); err != nil {
+		return nil, err
+	}
+
+	if err = d.triggerDispatcher.Stop(ctx, flow); err != nil {
+		slog.Error("failed to stop the triggers of an undeployed flow",
+			slog.String("flow_id", flow.Id),
+			slog.Any("error", err))
+
+		deployment.Error = err.Error()
+	}
+
+	d.audit(ctx, flow, DeployActionUndeploy, from, deployment)
+
+	return deployment, nil
+}
+
+// Rollback activates the version of the last IN_OPERATION deployment before
+// the current one and restarts its triggers. The flow is in ROLLBACK while
+// they restart.
+func (d *FlowDeployer) Rollback(ctx *yctx.Context, flowId, actor string) (*FlowDeployment, error) {
+	flow, err := d.getFlow(ctx, flowId)
+	if err != nil {
+		return nil, err
+	}
+
+	if flow.Deployment == nil || flow.Deployment.Previous == nil {
+		return nil, fmt.Errorf("%w: flow %s has no previous deployment", ErrInvalidDeployTransition, flow.Id)
+	}
+
+	previous := flow.Deployment.Previous
+
+	return d.run(ctx, flow, actor, DeployActionRollback, ybase.DeployStatusRollback, previous, previous)
+}
+
+// History returns the audit of the deploy actions of the flow.
+func (d *FlowDeployer) History(ctx *yctx.Context, flowId string) ([]DeployEvent, error) {
+	if _, err := d.getFlow(ctx, flowId); err != nil {
+		return nil, err
+	}
+
+	return d.deployAuditRepository.GetByFlowId(ctx, flowId)
+}
+
+// run moves the flow to the transient status while the version of target is
+// activated and its triggers start, then to IN_OPERATION, or to FAILED when
+// they fail to start. A deploy keeps previous to allow a rollback; a failed
+// rollback keeps it to allow a retry.
+func (d *FlowDeployer) run(
+	ctx *yctx.Context,
+	flow *Flow,
+	actor string,
+	action DeployAction,
+	transient ybase.DeployStatus,
+	target *FlowDeployment,
+	previous *FlowDeployment,
+) (*FlowDeployment, error) {
+	from := flow.DeployStatus()
+
+	inProgress := d.newDeployment(transient, actor, nil, previous)
+	if flow.Deployment != nil {
+		inProgress.Triggers = flow.Deployment.Triggers
+		inProgress.Version = flow.Deployment.Version
+	}
+
+	if err := d.transition(ctx, flow, from, inProgress); err != nil {
+		return nil, err
+	}
+
+	deployed := *flow
+	deployed.Triggers = target.Triggers
+
+	deployment := d.newDeployment(ybase.DeployStatusInOperation, actor, target.Triggers, previous)
+	deployment.Version = target.Version
+	if action == DeployActionRollback {
+		deployment.Previous = nil
+	}
+
+	startErr := d.activate(ctx, flow, target.Version)
+	if startErr == nil {
+		startErr = d.triggerDispatcher.Start(ctx, &deployed)
+	}
+
+	if startErr != nil {
+		deployment = d.newDeployment(ybase.DeployStatusFailed, actor, nil, previous)
+		deployment.Error = startErr.Error()
+	}
+
+	if err := d.transition(ctx, flow, transient, deployment); err != nil {
+		return nil, err
+	}
+
+	d.audit(ctx, flow, action, from, deployment)
+
+	if startErr != nil {
+		return deployment, startErr
+	}
+
+	return deployment, nil
+}
+
+// activate makes version the active version of the flow when it is not.
+func (d *FlowDeployer) activate(ctx *yctx.Context, flow *Flow, version int) error {
+	if version == 0 || version == flow.Version || d.flowVersioner == nil {
+		return nil
+	}
+
+	_, err := d.flowVersioner.Rollback(ctx, flow.Id, version)
+
+	return err
+}
+
+// transition saves the deployment when the move from the current status to
+// the status of the deployment is allowed.
+func (d *FlowDeployer) transition(ctx *yctx.Context, flow *Flow, from ybase.DeployStatus, deployment *FlowDeployment) error {
+	if !slices.Contains(deployTransitions[from], deployment.Status) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidDeployTransition, from, deployment.Status)
+	}
+
+	if err := d.flowDeployRepository.SaveDeployment(ctx, flow.Id, from, deployment); err != nil {
+		return err
+	}
+
+	flow.Deployment = deployment
+
+	return nil
+}
+
+func (d *FlowDeployer) newDeployment(status ybase.DeployStatus, actor string, triggers []FlowTrigger, previous *FlowDeployment) *FlowDeployment {
+	return &FlowDeployment{
+		Status:    status,
+		Triggers:  triggers,
+		UpdatedBy: actor,
+		UpdatedAt: d.now().UTC(),
+		Previous:  previous,
+	}
+}
+
+// lastInOperation returns the deployment a rollback goes back to after the
+// current deployment of the flow is replaced.
+func lastInOperation(flow *Flow) *FlowDeployment {
+	if flow.Deployment == nil {
+		return nil
+	}
+
+	if flow.Deployment.Status != ybase.DeployStatusInOperation {
+		return flow.Deployment.Previous
+	}
+
+	current := *flow.Deployment
+	current.Previous = nil
+	current.Error = ""
+
+	return &current
+}
+
+func (d *FlowDeployer) audit(ctx *yctx.Context, flow *Flow, action DeployAction, from ybase.DeployStatus, deployment *FlowDeployment) {
+	event := &DeployEvent{
+		Id:     uuid.NewString(),
+		FlowId: flow.Id,
+		Tenant: flow.Tenant,
+		Action: action,
+		From:   from,
+		To:     deployment.Status,
+		Actor:  deployment.UpdatedBy,
+		Error:  deployment.Error,
+		At:     deployment.UpdatedAt,
+	}
+
+	if err := d.deployAuditRepository.Save(ctx, event); err != nil {
+		slog.Error("failed to save deploy audit",
+			slog.String("flow_id", flow.Id),
+			slog.String("action", string(action)),
+			slog.Any("error", err))
+	}
+}
+
+func (d *FlowDeployer) getFlow(ctx *yctx.Context, flowId string) (*Flow, error) {
+	flow, err := d.flowReaderRepository.GetById(ctx, flowId)
+	if err != nil {
+		return nil, err
+	}
+
+	if flow == nil {
+		return nil, ErrFlowNotFound
+	}
+
+	return flow, nil
+}
@@ -0,0 +1,292 @@
+package flowmanager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestFlowDeployerTestSuite(t *testing.T) {
+	suite.Run(t, new(FlowDeployerTestSuite))
+}
+
+// flowDeployRepositoryFake keeps one flow and checks the expected status and
+// version like the database implementations.
+type flowDeployRepositoryFake struct {
+	mu   sync.Mutex
+	flow Flow
+}
+
+func (r *flowDeployRepositoryFake) GetById(ctx *yctx.Context, id string) (*Flow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id != r.flow.Id || r.flow.DeletedAt != nil {
+		return nil, nil
+	}
+
+	flow := r.flow
+	return &flow, nil
+}
+
+func (r *flowDeployRepositoryFake) GetDeleted(ctx *yctx.Context, pagination *Pagination) ([]Flow, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.flow.DeletedAt == nil {
+		return nil, nil
+	}
+
+	return []Flow{r.flow}, nil
+}
+
+func (r *flowDeployRepositoryFake) GetAll(ctx *yctx.Context, pagination *Pagination) ([]Flow, error) {
+	return nil, nil
+}
+
+func (r *flowDeployRepositoryFake) Count(ctx *yctx.Context) (int, error) {
+	return 1, nil
+}
+
+func (r *flowDeployRepositoryFake) Save(ctx *yctx.Context, flow *Flow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.flow = *flow
+	return nil
+}
+
+func (r *flowDeployRepositoryFake) Update(ctx *yctx.Context, flow *Flow, expectedRevision int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if flow.Id != r.flow.Id || r.flow.DeletedAt != nil {
+		return ErrFlowNotFound
+	}
+
+	if r.flow.Revision != expectedRevision {
+		return ErrFlowVersionConflict
+	}
+
+	deployment := r.flow.Deployment
+	r.flow = *flow
+	r.flow.Revision = expectedRevision + 1
+	r.flow.Deployment = deployment
+	return nil
+}
+
+func (r *flowDeployRepositoryFake) Delete(ctx *yctx.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id != r.flow.Id || r.flow.DeletedAt != nil {
+		return ErrFlowNotFound
+	}
+
+	deletedAt := time.Now()
+	r.flow.DeletedAt = &deletedAt
+	r.flow.Revision++
+	return nil
+}
+
+func (r *flowDeployRepositoryFake) Restore(ctx *yctx.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id != r.flow.Id || r.flow.DeletedAt == nil {
+		return ErrFlowNotFound
+	}
+
+	r.flow.DeletedAt = nil
+	r.flow.Revision++
+	return nil
+}
+
+func (r *flowDeployRepositoryFake) SaveDeployment(ctx *yctx.Context, flowId string, expected ybase.DeployStatus, deployment *FlowDeployment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.flow.DeployStatus() != expected {
+		return ErrDeployConflict
+	}
+
+	r.flow.Deployment = deployment
+	r.flow.Revision++
+	return nil
+}
+
+type triggerDispatcherStub struct {
+	started [][]FlowTrigger
+	stopped int
+	err     error
+	onStart func()
+}
+
+func (d *triggerDispatcherStub) Start(ctx *yctx.Context, flow *Flow) error {
+	if d.onStart != nil {
+		d.onStart()
+	}
+
+	d.started = append(d.started, flow.Triggers)
+
+	return d.err
+}
+
+func (d *triggerDispatcherStub) Stop(ctx *yctx.Context, flow *Flow) error {
+	d.stopped++
+	return nil
+}
+
+type FlowDeployerTestSuite struct {
+	suite.Suite
+	ctx               *yctx.Context
+	repository        *flowDeployRepositoryFake
+	auditRepository   *InMemoryDeployAuditRepository
+	triggerDispatcher *triggerDispatcherStub
+	flowVersioner     *FlowVersioner
+	flowDeployer      *FlowDeployer
+}
+
+func (s *FlowDeployerTestSuite) SetupTest() {
+	s.ctx = yctx.NewContext(context.Background())
+	s.repository = &flowDeployRepositoryFake{flow: Flow{
+		Id:       "flow-1",
+		Tenant:   "tenant-a",
+		Version:  1,
+		Triggers: []FlowTrigger{{Id: "v1", Type: TriggerTypeManual}},
+	}}
+	s.auditRepository = NewInMemoryDeployAuditRepository()
+	s.triggerDispatcher = &triggerDispatcherStub{}
+	s.flowVersioner = NewFlowVersioner(s.repository, s.repository, NewInMemoryFlowVersionRepository(), nil)
+	s.flowDeployer = NewFlowDeployer(s.repository, s.repository, s.auditRepository, s.triggerDispatcher, s.flowVersioner)
+}
+
+func (s *FlowDeployerTestSuite) TestDeploy_MovesToInOperationAndAudits() {
+	deployment, err := s.flowDeployer.Deploy(s.ctx, "flow-1", "alice")
+	s.Require().NoError(err)
+	s.Equal(ybase.DeployStatusInOperation, deployment.Status)
+	s.Equal("alice", deployment.UpdatedBy)
+	s.Equal([]FlowTrigger{{Id: "v1", Type: TriggerTypeManual}}, deployment.Triggers)
+	s.Equal(ybase.DeployStatusInOperation, s.repository.flow.DeployStatus())
+
+	events, err := s.flowDeployer.History(s.ctx, "flow-1")
+	s.Require().NoError(err)
+	s.Require().Len(events, 1)
+	s.Equal(DeployActionDeploy, events[0].Action)
+	s.Equal(ybase.DeployStatusPending, events[0].From)
+	s.Equal(ybase.DeployStatusInOperation, events[0].To)
+	s.Equal("alice", events[0].Actor)
+	s.Equal("tenant-a", events[0].Tenant)
+}
+
+func (s *FlowDeployerTestSuite) TestDeploy_FailedTriggersMoveToFailed() {
+	s.triggerDispatcher.err = errors.New("listener down")
+
+	deployment, err := s.flowDeployer.Deploy(s.ctx, "flow-1", "alice")
+	s.EqualError(err, "listener down")
+	s.Equal(ybase.DeployStatusFailed, deployment.Status)
+	s.Equal("listener down", deployment.Error)
+	s.Equal(ybase.DeployStatusFailed, s.repository.flow.DeployStatus())
+
+	_, err = s.flowDeployer.Rollback(s.ctx, "flow-1", "alice")
+	s.ErrorIs(err, ErrInvalidDeployTransition)
+
+	s.triggerDispatcher.err = nil
+
+	_, err = s.flowDeployer.Deploy(s.ctx, "flow-1", "alice")
+	s.NoError(err)
+
+	events, _ := s.auditRepository.GetByFlowId(s.ctx, "flow-1")
+	s.Require().Len(events, 2)
+	s.Equal("listener down", events[0].Error)
+	s.Equal(ybase.DeployStatusFailed, events[1].From)
+}
+
+func (s *FlowDeployerTestSuite) TestRollback_RestartsPreviousTriggers() {
+	_, err := s.flowDeployer.Deploy(s.ctx, "flow-1", "alice")
+	s.Require().NoError(err)
+
+	s.repository.flow.Triggers = []FlowTrigger{{Id: "v2", Type: TriggerTypeManual}}
+
+	deployment, err := s.flowDeployer.Deploy(s.ctx, "flow-1", "bob")
+	s.Require().NoError(err)
+	s.Require().NotNil(deployment.Previous)
+	s.Equal("v1", deployment.Previous.Triggers[0].Id)
+
+	deployment, err = s.flowDeployer.Rollback(s.ctx, "flow-1", "carol")
+	s.Require().NoError(err)
+	s.Equal(ybase.DeployStatusInOperation, deployment.Status)
+	s.Equal("v1", deployment.Triggers[0].Id)
+	s.Nil(deployment.Previous)
+	s.Equal("v1", s.triggerDispatcher.started[2][0].Id)
+
+	events, _ := s.auditRepository.GetByFlowId(s.ctx, "flow-1")
+	s.Require().Len(events, 3)
+	s.Equal(DeployActionRollback, events[2].Action)
+	s.Equal("carol", events[2].Actor)
+}
+
+func (s *FlowDeployerTestSuite) TestUndeploy_StopsTriggers() {
+	_, err := s.flowDeployer.Undeploy(s.ctx, "flow-1", "alice")
+	s.ErrorIs(err, ErrInvalidDeployTransition)
+
+	_, err = s.flowDeployer.Deploy(s.ctx, "flow-1", "alice")
+	s.Require().NoError(err)
+
+	deployment, err := s.flowDeployer.Undeploy(s.ctx, "flow-1", "alice")
+	s.Require().NoError(err)
+	s.Equal(ybase.DeployStatusCanceled, deployment.Status)
+	s.Empty(deployment.Triggers)
+	s.Equal(1, s.triggerDispatcher.stopped)
+
+	_, err = s.flowDeployer.Undeploy(s.ctx, "flow-1", "alice")
+	s.ErrorIs(err, ErrInvalidDeployTransition)
+}
+
+func (s *FlowDeployerTestSuite) TestDeploy_RejectsConcurrentDeploy() {
+	var concurrentErr error
+
+	s.triggerDispatcher.onStart = func() {
+		s.triggerDispatcher.onStart = nil
+		_, concurrentErr = s.flowDeployer.Deploy(s.ctx, "flow-1", "bob")
+	}
+
+	_, err := s.flowDeployer.Deploy(s.ctx, "flow-1", "alice")
+	s.NoError(err)
+	s.ErrorIs(concurrentErr, ErrInvalidDeployTransition)
+	s.Len(s.triggerDispatcher.started, 1)
+}
+
+func (s *FlowDeployerTestSuite) TestDeploy_FlowNotFound() {
+	_, err := s.flowDeployer.Deploy(s.ctx, "missing", "alice")
+	s.ErrorIs(err, ErrFlowNotFound)
+}
+
+func (s *FlowDeployerTestSuite) TestRollback_ActivatesPreviousVersion() {
+	s.Require().NoError(s.flowVersioner.saveVersion(s.ctx, &s.repository.flow))
+
+	_, err := s.flowDeployer.Deploy(s.ctx, "flow-1", "alice")
+	s.Require().NoError(err)
+
+	next := Flow{Id: "flow-1", Version: 2, Triggers: []FlowTrigger{{Id: "v2", Type: TriggerTypeManual}}}
+	s.Require().NoError(s.flowVersioner.saveVersion(s.ctx, &next))
+	s.Require().NoError(s.repository.Update(s.ctx, &next, s.repository.flow.Revision))
+
+	deployment, err := s.flowDeployer.Deploy(s.ctx, "flow-1", "bob")
+	s.Require().NoError(err)
+	s.Equal(2, deployment.Version)
+	s.Equal(1, deployment.Previous.Version)
+
+	deployment, err = s.flowDeployer.Rollback(s.ctx, "flow-1", "carol")
+	s.Require().NoError(err)
+	s.Equal(1, deployment.Version)
+	s.Equal(1, s.repository.flow.Version)
+	s.Equal("v1", s.repository.flow.Triggers[0].Id)
+	s.Equal(ybase.DeployStatusInOperation, s.repository.flow.DeployStatus())
+}
@@ -0,0 +1,167 @@
+package flowmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DiffFlows lists the changes of the definition of the flow from one version
+// to another. Version, Revision, Deployment and the timestamps are not part
+// of the definition.
+func DiffFlows(from, to *Flow) ([]FlowChange, error) {
+	fromValue, err := definitionValue(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toValue, err := definitionValue(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]FlowChange, 0)
+	diffValues("", fromValue, toValue, &changes)
+
+	return changes, nil
+}
+
+func definitionValue(flow *Flow) (value any, err error) {
+	definition := *flow
+	definition.Version = 0
+	definition.Revision = 0
+	definition.Deployment = nil
+	definition.CreatedAt = time.Time{}
+	definition.UpdatedAt = time.Time{}
+
+	data, err := json.Marshal(definition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal flow: %w", err)
+	}
+
+	err = json.Unmarshal(data, &value)
+
+	return value, err
+}
+
+func diffValues(path string, from, to any, changes *[]FlowChange) {
+	switch fromValue := from.(type) {
+	case map[string]any:
+		if toValue, ok := to.(map[string]any); ok {
+			diffObjects(path, fromValue, toValue, changes)
+			return
+		}
+	case []any:
+		if toValue, ok := to.([]any); ok {
+			diffArrays(path, fromValue, toValue, changes)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, FlowChange{Path: path, Change: ChangeTypeChanged, From: from, To: to})
+	}
+}
+
+func diffObjects(path string, from, to map[string]any, changes *[]FlowChange) {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		diffEntry(joinPath(path, key), from, to, key, changes)
+	}
+}
+
+// diffArrays matches the elements by id when all of them have one, like
+// plugins and triggers, and by position otherwise.
+func diffArrays(path string, from, to []any, changes *[]FlowChange) {
+	fromById, fromIds, fromOk := elementsById(from)
+	toById, toIds, toOk := elementsById(to)
+
+	if fromOk && toOk {
+		ids := fromIds
+		for _, id := range toIds {
+			if _, ok := fromById[id]; !ok {
+				ids = append(ids, id)
+			}
+		}
+
+		for _, id := range ids {
+			diffEntry(path+"["+id+"]", fromById, toById, id, changes)
+		}
+
+		return
+	}
+
+	fromByIndex := make(map[string]any, len(from))
+	toByIndex := make(map[string]any, len(to))
+	for i, element := range from {
+		fromByIndex[strconv.Itoa(i)] = element
+	}
+	for i, element := range to {
+		toByIndex[strconv.Itoa(i)] = element
+	}
+
+	for i := 0; i < max(len(from), len(to)); i++ {
+		index := strconv.Itoa(i)
+		diffEntry(path+"["+index+"]", fromByIndex, toByIndex, index, changes)
+	}
+}
+
+func diffEntry(path string, from, to map[string]any, key string, changes *[]FlowChange) {
+	fromValue, inFrom := from[key]
+	toValue, inTo := to[key]
+
+	switch {
+	case !inTo:
+		*changes = append(*changes, FlowChange{Path: path, Change: ChangeTypeRemoved, From: fromValue})
+	case !inFrom:
+		*changes = append(*changes, FlowChange{Path: path, Change: ChangeTypeAdded, To: toValue})
+	default:
+		diffValues(path, fromValue, toValue, changes)
+	}
+}
+
+func elementsById(elements []any) (byId map[string]any, ids []string, ok bool) {
+	byId = make(map[string]any, len(elements))
+
+	for _, element := range elements {
+		object, isObject := element.(map[string]any)
+		if !isObject {
+			return nil, nil, false
+		}
+
+		id, isString := object["id"].(string)
+		if !isString || id == "" {
+			return nil, nil, false
+		}
+
+		if _, duplicated := byId[id]; duplicated {
+			return nil, nil, false
+		}
+
+		byId[id] = element
+		ids = append(ids, id)
+	}
+
+	return byId, ids, true
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -1,32 +1,71 @@
 package flowmanager
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/yrn-go/yrn/pkg/plugincore"
+	"github.com/yrn-go/yrn/pkg/ybase"
 	"github.com/yrn-go/yrn/pkg/yctx"
 )
 
+var (
+	ErrFlowNotFound = errors.New("flow not found")
+	// ErrFlowNotDeployed is returned when a flow that is not IN_OPERATION
+	// runs outside of test mode.
+	ErrFlowNotDeployed = errors.New("flow is not deployed")
+)
+
+type testModeKey struct{}
+
 type (
 	PluginExecutor interface {
 		Do(ctx *yctx.Context, schemaInputs string, previousPluginResponse any, responseSharedForAll map[string]any) (output any, err error)
 	}
+	// PluginSchemaProvider is implemented by plugin executors that expose
+	// their input JSON schema for design-time validation.
+	PluginSchemaProvider interface {
+		InputSchema() []byte
+	}
+	// PluginOutputSchemaProvider is implemented by plugin executors that
+	// expose the JSON schema of their output.
+	PluginOutputSchemaProvider interface {
+		OutputSchema() []byte
+	}
 	PluginManager interface {
 		GetBySlug(ctx *yctx.Context, slug string) (plugin PluginExecutor, err error)
 	}
+	// SecretResolver returns the value of a secret of a tenant. It is used to
+	// render {{ secret "name" }} references while a flow runs.
+	SecretResolver interface {
+		Resolve(ctx *yctx.Context, tenant, name string) (value string, err error)
+	}
 	FlowExecutor struct {
-		flowReaderRepository FlowReaderRepository
-		pluginManager        PluginManager
-		statusRepo           PluginStatusRepository
+		flowReaderRepository  FlowReaderRepository
+		flowVersionRepository FlowVersionRepository
+		pluginManager         PluginManager
+		statusRepo            PluginStatusRepository
+		secretResolver        SecretResolver
 	}
 )
 
+// NewFlowExecutor creates a FlowExecutor. secretResolver may be nil, in which
+// case flows referencing secrets fail to run.
 func NewFlowExecutor(
 	flowReaderRepository FlowReaderRepository,
+	flowVersionRepository FlowVersionRepository,
 	pluginManager PluginManager,
 	statusRepo PluginStatusRepository,
+	secretResolver SecretResolver,
 ) *FlowExecutor {
 	return &FlowExecutor{
 		flowReaderRepository,
+		flowVersionRepository,
 		pluginManager,
 		statusRepo,
+		secretResolver,
 	}
 }
 
@@ -42,11 +81,76 @@ func (f *FlowExecutor) Do(ctx *yctx.Context, flowId string, eventRequestData any
 		return
 	}
 
+	// a context with tenant does not run the flows of other tenants.
+	if flow == nil || !ctx.CanAccess(flow.Tenant) {
+		return nil, ErrFlowNotFound
+	}
+
+	// test mode runs the active version, which may not be deployed yet.
+	if !IsTestMode(ctx) {
+		if status := flow.DeployStatus(); status != ybase.DeployStatusInOperation {
+			return nil, fmt.Errorf("%w: flow %s is %s", ErrFlowNotDeployed, flow.Id, status)
+		}
+
+		if flow, err = f.deployedFlow(ctx, flow); err != nil {
+			return
+		}
+	}
+
+	// the plugins of the flow, its statuses and secrets are scoped to the
+	// tenant of the flow, and each run has its own execution id.
+	ctx = ctx.WithTenant(flow.Tenant).WithExecution(flow.Id, uuid.NewString())
+
+	eventManager.SetFlow(flow.Id, flow.Version, flow.Tenant)
+
 	for _, pluginInfo := range flow.Plugins {
 		if err = eventManager.Register(pluginInfo); err != nil {
 			return
 		}
 	}
 
+	if f.secretResolver != nil {
+		ctx = plugincore.WithSecretResolver(ctx, func(ctx *yctx.Context, name string) (string, error) {
+			return f.secretResolver.Resolve(ctx, flow.Tenant, name)
+		})
+		ctx = plugincore.WithSensitiveValuesHandler(ctx, eventManager.AddSensitiveValues)
+	}
+
 	return eventManager.Execute(ctx, flow.FirstPluginToRun, eventRequestData)
 }
+
+// deployedFlow returns the deployed version of the flow, which is not the
+// active one when the flow was changed after it was deployed.
+func (f *FlowExecutor) deployedFlow(ctx *yctx.Context, flow *Flow) (*Flow, error) {
+	version := flow.Deployment.Version
+	if version == 0 || version == flow.Version {
+		return flow, nil
+	}
+
+	flowVersion, err := f.flowVersionRepository.GetByVersion(ctx, flow.Id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if flowVersion == nil {
+		return nil, fmt.Errorf("%w: %s version %d", ErrFlowVersionNotFound, flow.Id, version)
+	}
+
+	deployed := flowVersion.Flow
+	deployed.Tenant = flow.Tenant
+	deployed.Deployment = flow.Deployment
+
+	return &deployed, nil
+}
+
+// WithTestMode returns a context that lets FlowExecutor run flows that are
+// not deployed.
+func WithTestMode(ctx *yctx.Context) *yctx.Context {
+	return yctx.NewContext(context.WithValue(ctx.Context(), testModeKey{}, true))
+}
+
+func IsTestMode(ctx *yctx.Context) bool {
+	testMode, _ := ctx.Context().Value(testModeKey{}).(bool)
+
+	return testMode
+}
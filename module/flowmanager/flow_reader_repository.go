@@ -2,8 +2,12 @@ package flowmanager
 
 import "github.com/yrn-go/yrn/pkg/yctx"
 
+// FlowReaderRepository reads the flows that are not deleted, except for
+// GetDeleted.
 type FlowReaderRepository interface {
 	GetById(ctx *yctx.Context, id string) (item *Flow, err error)
 	GetAll(ctx *yctx.Context, pagination *Pagination) (items []Flow, err error)
 	Count(ctx *yctx.Context) (total int, err error)
+	// GetDeleted returns the soft deleted flows, which can be restored.
+	GetDeleted(ctx *yctx.Context, pagination *Pagination) (items []Flow, err error)
 }
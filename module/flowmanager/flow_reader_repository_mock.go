@@ -60,3 +60,19 @@ func (m *FlowReaderRepositoryMock) Count(ctx *yctx.Context) (total int, err erro
 
 	return
 }
+
+func (m *FlowReaderRepositoryMock) GetDeleted(ctx *yctx.Context, pagination *Pagination) (items []Flow, err error) {
+	returns := m.MethodCalled("GetDeleted", ctx, pagination)
+
+	if index := 0; len(returns) > index {
+		if itemIndex := returns.Get(index); itemIndex != nil {
+			items = itemIndex.([]Flow)
+		}
+	}
+
+	if index := 1; len(returns) > index {
+		err = returns.Error(index)
+	}
+
+	return
+}
@@ -0,0 +1,36 @@
+package flowmanager
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var (
+	_ FlowSearchRepository = (*FlowSearchRepositoryMock)(nil)
+)
+
+type FlowSearchRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlowSearchRepositoryMock) Search(ctx *yctx.Context, query *FlowQuery) (items []Flow, total int, err error) {
+	returns := m.MethodCalled("Search", ctx, query)
+
+	if index := 0; len(returns) > index {
+		if itemIndex := returns.Get(index); itemIndex != nil {
+			items = itemIndex.([]Flow)
+		}
+	}
+
+	if index := 1; len(returns) > index {
+		if itemIndex := returns.Get(index); itemIndex != nil {
+			total = itemIndex.(int)
+		}
+	}
+
+	if index := 2; len(returns) > index {
+		err = returns.Error(index)
+	}
+
+	return
+}
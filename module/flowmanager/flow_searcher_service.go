@@ -1,6 +1,29 @@
 package flowmanager
 
-import "github.com/yrn-go/yrn/pkg/yctx"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var ErrInvalidFlowQuery = errors.New("invalid flow query")
+
+type FlowSort string
+
+const (
+	FlowSortName        FlowSort = "name"
+	FlowSortNameDesc    FlowSort = "-name"
+	FlowSortUpdated     FlowSort = "updated_at"
+	FlowSortUpdatedDesc FlowSort = "-updated_at"
+)
 
 type (
 	Pagination struct {
@@ -12,28 +35,81 @@ type (
 		Items      []T `json:"items"`
 		TotalItems int `json:"total_items"`
 		TotalPages int `json:"total_pages"`
+		// NextCursor is set when paginating by cursor and there are more
+		// items after this page.
+		NextCursor string `json:"next_cursor,omitempty"`
+	}
+
+	// FlowQuery filters the flows that are not deleted. Empty filters match
+	// every flow.
+	FlowQuery struct {
+		Pagination
+		Tenant       string
+		NamePrefix   string
+		PluginSlug   string
+		DeployStatus ybase.DeployStatus
+		// Tags matches the flows having all the tags.
+		Tags []string
+		// Text matches the flows having each of its words in the name, in the
+		// description or in a tag. Words match case insensitive substrings, so
+		// "INV" matches "invoices". Every repository implements the same
+		// semantics.
+		Text string
+		// Sort is FlowSortName by default. Flows with the same value are
+		// sorted by id.
+		Sort FlowSort
+		// Cursor is the NextCursor of the previous page. It can not be used
+		// with Page.
+		Cursor string
+		// After is the decoded Cursor, used by the repositories.
+		After *FlowCursor
+	}
+
+	// FlowCursor has the sort values of the last flow of a page.
+	FlowCursor struct {
+		Id        string    `json:"id"`
+		Name      string    `json:"name,omitempty"`
+		UpdatedAt time.Time `json:"updated_at,omitempty"`
+	}
+
+	FlowSearchRepository interface {
+		// Search returns the page of the query and the total of flows that
+		// match the filters of the query, ignoring the pagination.
+		Search(ctx *yctx.Context, query *FlowQuery) (items []Flow, total int, err error)
 	}
 
 	GetAllResponseFlow struct {
-		Id          string `json:"id"`
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Version     int    `json:"version"`
+		Id           string             `json:"id"`
+		Name         string             `json:"name"`
+		Description  string             `json:"description"`
+		Tenant       string             `json:"tenant"`
+		Tags         []string           `json:"tags,omitempty"`
+		Version      int                `json:"version"`
+		DeployStatus ybase.DeployStatus `json:"deploy_status"`
+		UpdatedAt    time.Time          `json:"updated_at"`
 	}
 
 	FlowSearcher struct {
 		flowReaderRepository FlowReaderRepository
+		flowSearchRepository FlowSearchRepository
 	}
 )
 
+func NewFlowSearcher(flowReaderRepository FlowReaderRepository, flowSearchRepository FlowSearchRepository) *FlowSearcher {
+	return &FlowSearcher{
+		flowReaderRepository: flowReaderRepository,
+		flowSearchRepository: flowSearchRepository,
+	}
+}
+
 func (f *FlowSearcher) GetById(ctx *yctx.Context, id string) (*Flow, error) {
 	return f.flowReaderRepository.GetById(ctx, id)
 }
 
 func (f *FlowSearcher) GetAll(ctx *yctx.Context, pagination *Pagination) (items *Page[GetAllResponseFlow], err error) {
 	var (
-		flows             []Flow
-		total, totalPages int
+		flows []Flow
+		total int
 	)
 
 	flows, err = f.flowReaderRepository.GetAll(ctx, pagination)
@@ -46,28 +122,234 @@ func (f *FlowSearcher) GetAll(ctx *yctx.Context, pagination *Pagination) (items
 		return nil, err
 	}
 
-	totalPages = total / pagination.Size
-
 	return &Page[GetAllResponseFlow]{
 		Items:      mapperFlowsToGetAllResponses(flows),
 		TotalItems: total,
-		TotalPages: totalPages,
+		TotalPages: totalPages(total, pagination.Size),
 	}, nil
 }
 
-func mapperFlowsToGetAllResponses(flows []Flow) (response []GetAllResponseFlow) {
+// Search returns a page of the flows matching the query. The first page,
+// and the pages requested by cursor, have the NextCursor of the next page.
+func (f *FlowSearcher) Search(ctx *yctx.Context, query *FlowQuery) (*Page[GetAllResponseFlow], error) {
+	if err := query.prepare(); err != nil {
+		return nil, err
+	}
+
+	// only the contexts that access every tenant search other tenants.
+	if !ctx.HasAllTenants() {
+		tenant := ctx.Tenant()
+		if query.Tenant != "" && query.Tenant != tenant {
+			return &Page[GetAllResponseFlow]{Items: []GetAllResponseFlow{}}, nil
+		}
+
+		query.Tenant = tenant
+	}
+
+	// one more flow is read to know if there is a next page.
+	byCursor := query.Page == 0
+	pageQuery := *query
+	if byCursor {
+		pageQuery.Size++
+	}
+
+	flows, total, err := f.flowSearchRepository.Search(ctx, &pageQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page[GetAllResponseFlow]{
+		TotalItems: total,
+		TotalPages: totalPages(total, query.Size),
+	}
+
+	if byCursor && len(flows) > query.Size {
+		flows = flows[:query.Size]
+		page.NextCursor = encodeFlowCursor(&flows[len(flows)-1])
+	}
+
+	page.Items = mapperFlowsToGetAllResponses(flows)
+
+	return page, nil
+}
+
+// SearchFlows applies the query to flows, for the repositories that can not
+// filter and sort the flows themselves.
+func SearchFlows(flows []Flow, query *FlowQuery) (items []Flow, total int) {
+	items = make([]Flow, 0, len(flows))
 	for _, flow := range flows {
-		response = append(response, *mapperFlowToGetAllResponse(&flow))
+		if query.match(&flow) {
+			items = append(items, flow)
+		}
+	}
+
+	total = len(items)
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return query.compare(&items[i], &items[j]) < 0
+	})
+
+	if query.After != nil {
+		after := Flow{Id: query.After.Id, Name: query.After.Name, UpdatedAt: query.After.UpdatedAt}
+		start := sort.Search(len(items), func(i int) bool {
+			return query.compare(&items[i], &after) > 0
+		})
+		items = items[start:]
+	}
+
+	start := min(query.Page*query.Size, len(items))
+	end := min(start+query.Size, len(items))
+
+	return items[start:end], total
+}
+
+// SortField returns the field of the sort, name or updated_at, and whether
+// it is descending.
+func (q *FlowQuery) SortField() (field string, descending bool) {
+	field, descending = strings.CutPrefix(string(q.Sort), "-")
+	if field == "" {
+		field = string(FlowSortName)
 	}
 
 	return
 }
 
+// TextWords returns the lower case words of Text.
+func (q *FlowQuery) TextWords() []string {
+	return strings.Fields(strings.ToLower(q.Text))
+}
+
+// prepare validates the query and decodes its cursor.
+func (q *FlowQuery) prepare() error {
+	switch q.Sort {
+	case "":
+		q.Sort = FlowSortName
+	case FlowSortName, FlowSortNameDesc, FlowSortUpdated, FlowSortUpdatedDesc:
+	default:
+		return fmt.Errorf("%w: unknown sort %q", ErrInvalidFlowQuery, q.Sort)
+	}
+
+	switch q.DeployStatus {
+	case "", ybase.DeployStatusPending, ybase.DeployStatusInProgress, ybase.DeployStatusInOperation,
+		ybase.DeployStatusFailed, ybase.DeployStatusRollback, ybase.DeployStatusCanceled:
+	default:
+		return fmt.Errorf("%w: unknown deploy status %q", ErrInvalidFlowQuery, q.DeployStatus)
+	}
+
+	if q.Size < 1 {
+		return fmt.Errorf("%w: size must be positive", ErrInvalidFlowQuery)
+	}
+
+	if q.Cursor == "" {
+		q.After = nil
+		return nil
+	}
+
+	if q.Page > 0 {
+		return fmt.Errorf("%w: cursor can not be used with page", ErrInvalidFlowQuery)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err == nil {
+		q.After = new(FlowCursor)
+		err = json.Unmarshal(data, q.After)
+	}
+
+	if err != nil || q.After.Id == "" {
+		return fmt.Errorf("%w: invalid cursor", ErrInvalidFlowQuery)
+	}
+
+	return nil
+}
+
+func (q *FlowQuery) match(flow *Flow) bool {
+	if q.Tenant != "" && flow.Tenant != q.Tenant {
+		return false
+	}
+
+	if !strings.HasPrefix(flow.Name, q.NamePrefix) {
+		return false
+	}
+
+	if q.PluginSlug != "" && !slices.ContainsFunc(flow.Plugins, func(plugin FlowPlugin) bool {
+		return plugin.Slug == q.PluginSlug
+	}) {
+		return false
+	}
+
+	if q.DeployStatus != "" && flow.DeployStatus() != q.DeployStatus {
+		return false
+	}
+
+	for _, tag := range q.Tags {
+		if !slices.Contains(flow.Tags, tag) {
+			return false
+		}
+	}
+
+	text := strings.ToLower(flow.Name + "\n" + flow.Description + "\n" + strings.Join(flow.Tags, "\n"))
+	for _, word := range q.TextWords() {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (q *FlowQuery) compare(a, b *Flow) (result int) {
+	field, descending := q.SortField()
+
+	if field == string(FlowSortUpdated) {
+		result = a.UpdatedAt.Compare(b.UpdatedAt)
+	} else {
+		result = strings.Compare(a.Name, b.Name)
+	}
+
+	if result == 0 {
+		result = strings.Compare(a.Id, b.Id)
+	}
+
+	if descending {
+		return -result
+	}
+
+	return result
+}
+
+func encodeFlowCursor(flow *Flow) string {
+	data, _ := json.Marshal(FlowCursor{Id: flow.Id, Name: flow.Name, UpdatedAt: flow.UpdatedAt})
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// totalPages counts the last partial page.
+func totalPages(total, size int) int {
+	if size < 1 {
+		return 0
+	}
+
+	return (total + size - 1) / size
+}
+
+func mapperFlowsToGetAllResponses(flows []Flow) []GetAllResponseFlow {
+	response := make([]GetAllResponseFlow, 0, len(flows))
+	for _, flow := range flows {
+		response = append(response, *mapperFlowToGetAllResponse(&flow))
+	}
+
+	return response
+}
+
 func mapperFlowToGetAllResponse(flow *Flow) *GetAllResponseFlow {
 	return &GetAllResponseFlow{
-		Id:          flow.Id,
-		Name:        flow.Name,
-		Description: flow.Description,
-		Version:     flow.Version,
+		Id:           flow.Id,
+		Name:         flow.Name,
+		Description:  flow.Description,
+		Tenant:       flow.Tenant,
+		Tags:         flow.Tags,
+		Version:      flow.Version,
+		DeployStatus: flow.DeployStatus(),
+		UpdatedAt:    flow.UpdatedAt,
 	}
 }
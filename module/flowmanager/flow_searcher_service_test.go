@@ -0,0 +1,157 @@
+package flowmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestFlowSearcherTestSuite(t *testing.T) {
+	suite.Run(t, new(FlowSearcherTestSuite))
+}
+
+// flowSearchRepositoryFake searches flows kept in memory.
+type flowSearchRepositoryFake struct {
+	flows []Flow
+}
+
+func (r *flowSearchRepositoryFake) Search(ctx *yctx.Context, query *FlowQuery) ([]Flow, int, error) {
+	items, total := SearchFlows(r.flows, query)
+
+	return items, total, nil
+}
+
+type FlowSearcherTestSuite struct {
+	suite.Suite
+	ctx          *yctx.Context
+	flowSearcher *FlowSearcher
+}
+
+func (s *FlowSearcherTestSuite) SetupTest() {
+	s.ctx = yctx.NewContext(context.Background()).WithAllTenants()
+
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repository := &flowSearchRepositoryFake{flows: []Flow{
+		{
+			Id: "flow-1", Name: "Orders sync", Description: "Copies orders to the ERP", Tenant: "tenant-a",
+			Tags: []string{"erp", "orders"}, UpdatedAt: updatedAt,
+			Plugins:    []FlowPlugin{{Id: "fetch", Slug: "plugin-http"}},
+			Deployment: &FlowDeployment{Status: ybase.DeployStatusInOperation},
+		},
+		{
+			Id: "flow-2", Name: "Orders report", Description: "Daily report", Tenant: "tenant-a",
+			Tags: []string{"orders"}, UpdatedAt: updatedAt.Add(2 * time.Hour),
+			Plugins: []FlowPlugin{{Id: "upload", Slug: "plugin-gdrive"}},
+		},
+		{
+			Id: "flow-3", Name: "Invoices", Description: "Sends invoices to the ERP", Tenant: "tenant-a",
+			Tags: []string{"erp"}, UpdatedAt: updatedAt.Add(time.Hour),
+			Plugins: []FlowPlugin{{Id: "fetch", Slug: "plugin-http"}},
+		},
+		{
+			Id: "flow-4", Name: "Orders sync", Description: "Copies orders to the ERP", Tenant: "tenant-b",
+			Tags: []string{"erp", "orders"}, UpdatedAt: updatedAt,
+		},
+	}}
+
+	s.flowSearcher = NewFlowSearcher(nil, repository)
+}
+
+func (s *FlowSearcherTestSuite) ids(page *Page[GetAllResponseFlow]) (ids []string) {
+	for _, item := range page.Items {
+		ids = append(ids, item.Id)
+	}
+
+	return
+}
+
+func (s *FlowSearcherTestSuite) TestSearch_Filters() {
+	tests := []struct {
+		name  string
+		query FlowQuery
+		ids   []string
+	}{
+		{name: "tenant", query: FlowQuery{Tenant: "tenant-b"}, ids: []string{"flow-4"}},
+		{name: "name prefix", query: FlowQuery{Tenant: "tenant-a", NamePrefix: "Orders"}, ids: []string{"flow-2", "flow-1"}},
+		{name: "plugin slug", query: FlowQuery{PluginSlug: "plugin-gdrive"}, ids: []string{"flow-2"}},
+		{name: "deploy status", query: FlowQuery{Tenant: "tenant-a", DeployStatus: ybase.DeployStatusPending}, ids: []string{"flow-3", "flow-2"}},
+		{name: "tags", query: FlowQuery{Tags: []string{"erp", "orders"}}, ids: []string{"flow-1", "flow-4"}},
+		{name: "text", query: FlowQuery{Tenant: "tenant-a", Text: "ERP invoices"}, ids: []string{"flow-3"}},
+		{name: "text substring", query: FlowQuery{Tenant: "tenant-a", Text: "REPO"}, ids: []string{"flow-2"}},
+		{name: "text tag", query: FlowQuery{Tenant: "tenant-a", Text: "orders daily"}, ids: []string{"flow-2"}},
+	}
+
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			test.query.Size = 10
+
+			page, err := s.flowSearcher.Search(s.ctx, &test.query)
+			s.Require().NoError(err)
+			s.Equal(test.ids, s.ids(page))
+			s.Equal(len(test.ids), page.TotalItems)
+			s.Empty(page.NextCursor)
+		})
+	}
+}
+
+func (s *FlowSearcherTestSuite) TestSearch_SortsByUpdatedDescending() {
+	page, err := s.flowSearcher.Search(s.ctx, &FlowQuery{
+		Pagination: Pagination{Size: 10},
+		Tenant:     "tenant-a",
+		Sort:       FlowSortUpdatedDesc,
+	})
+	s.Require().NoError(err)
+	s.Equal([]string{"flow-2", "flow-3", "flow-1"}, s.ids(page))
+}
+
+func (s *FlowSearcherTestSuite) TestSearch_PaginatesByPageAndCursor() {
+	page, err := s.flowSearcher.Search(s.ctx, &FlowQuery{Pagination: Pagination{Page: 1, Size: 3}})
+	s.Require().NoError(err)
+	s.Equal([]string{"flow-4"}, s.ids(page))
+	s.Equal(4, page.TotalItems)
+	s.Equal(2, page.TotalPages)
+	s.Empty(page.NextCursor)
+
+	var ids []string
+	query := &FlowQuery{Pagination: Pagination{Size: 3}}
+
+	for {
+		page, err = s.flowSearcher.Search(s.ctx, query)
+		s.Require().NoError(err)
+		s.Equal(2, page.TotalPages)
+
+		ids = append(ids, s.ids(page)...)
+		if page.NextCursor == "" {
+			break
+		}
+
+		query.Cursor = page.NextCursor
+	}
+
+	s.Equal([]string{"flow-3", "flow-2", "flow-1", "flow-4"}, ids)
+}
+
+func (s *FlowSearcherTestSuite) TestSearch_InvalidQuery() {
+	queries := []FlowQuery{
+		{Pagination: Pagination{Size: 10}, Sort: "created_at"},
+		{Pagination: Pagination{Size: 10}, DeployStatus: "RUNNING"},
+		{Pagination: Pagination{Size: 10}, Cursor: "not a cursor"},
+		{Pagination: Pagination{Page: 1, Size: 10}, Cursor: encodeFlowCursor(&Flow{Id: "flow-1"})},
+		{},
+	}
+
+	for _, query := range queries {
+		_, err := s.flowSearcher.Search(s.ctx, &query)
+		s.ErrorIs(err, ErrInvalidFlowQuery)
+	}
+}
+
+func (s *FlowSearcherTestSuite) TestTotalPages_CountsPartialPage() {
+	s.Equal(0, totalPages(0, 10))
+	s.Equal(1, totalPages(10, 10))
+	s.Equal(2, totalPages(11, 10))
+}
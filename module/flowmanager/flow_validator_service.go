@@ -0,0 +1,403 @@
+package flowmanager
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/yrn-go/yrn/module/scheduler"
+	"github.com/yrn-go/yrn/pkg/plugincore"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+const (
+	ValidationRuleDuplicatedPlugin  = "duplicated_plugin"
+	ValidationRuleFirstPlugin       = "first_plugin"
+	ValidationRuleNextPlugin        = "next_plugin"
+	ValidationRuleUnknownPlugin     = "unknown_plugin"
+	ValidationRuleTemplateSyntax    = "template_syntax"
+	ValidationRuleUnknownReference  = "unknown_reference"
+	ValidationRulePluginSchema      = "plugin_schema"
+	ValidationRuleUnknownField      = "unknown_field"
+	ValidationRuleOutputValidation  = "output_validation"
+	ValidationRuleDuplicatedTrigger = "duplicated_trigger"
+	ValidationRuleTrigger           = "trigger"
+)
+
+type (
+	// FlowValidationReport lists the problems found in a flow definition.
+	// Problems of the flow structure are in Flow and problems of each plugin
+	// are in Plugins and Triggers, keyed by plugin and trigger id.
+	FlowValidationReport struct {
+		Flow     []plugincore.FieldError            `json:"flow,omitempty"`
+		Plugins  map[string][]plugincore.FieldError `json:"plugins,omitempty"`
+		Triggers map[string][]plugincore.FieldError `json:"triggers,omitempty"`
+	}
+
+	FlowValidationError struct {
+		Report *FlowValidationReport
+	}
+
+	FlowValidator struct {
+		pluginManager PluginManager
+	}
+
+	// pluginGraph holds what a plugin validation needs to know about the
+	// other plugins of the flow.
+	pluginGraph struct {
+		parents       map[string][]string
+		upstream      map[string]map[string]bool
+		outputSchemas map[string][]byte
+	}
+)
+
+func (r *FlowValidationReport) Valid() bool {
+	return len(r.Flow) == 0 && len(r.Plugins) == 0 && len(r.Triggers) == 0
+}
+
+func (r *FlowValidationReport) addPluginProblems(pluginId string, problems ...plugincore.FieldError) {
+	if len(problems) == 0 {
+		return
+	}
+
+	if r.Plugins == nil {
+		r.Plugins = make(map[string][]plugincore.FieldError)
+	}
+
+	r.Plugins[pluginId] = append(r.Plugins[pluginId], problems...)
+}
+
+func (r *FlowValidationReport) addTriggerProblems(triggerId string, problems ...plugincore.FieldError) {
+	if len(problems) == 0 {
+		return
+	}
+
+	if r.Triggers == nil {
+		r.Triggers = make(map[string][]plugincore.FieldError)
+	}
+
+	r.Triggers[triggerId] = append(r.Triggers[triggerId], problems...)
+}
+
+func (e *FlowValidationError) Error() string {
+	total := len(e.Report.Flow)
+	for _, problems := range e.Report.Plugins {
+		total += len(problems)
+	}
+	for _, problems := range e.Report.Triggers {
+		total += len(problems)
+	}
+
+	return fmt.Sprintf("flow validation failed: %d problem(s)", total)
+}
+
+func NewFlowValidator(pluginManager PluginManager) *FlowValidator {
+	return &FlowValidator{
+		pluginManager: pluginManager,
+	}
+}
+
+// Validate checks the flow structure and, for each plugin, the template
+// syntax of its SchemaInput, the template references to .data and
+// .sharedForAll against the upstream plugins and their output schemas, and
+// the static parts of the input against the plugin schema. Triggers are
+// checked by validateTrigger.
+func (v *FlowValidator) Validate(ctx *yctx.Context, flow *Flow) (report *FlowValidationReport, err error) {
+	report = &FlowValidationReport{}
+
+	plugins := make(map[string]FlowPlugin, len(flow.Plugins))
+
+	for _, pluginInfo := range flow.Plugins {
+		if _, exists := plugins[pluginInfo.Id]; exists {
+			report.Flow = append(report.Flow, plugincore.FieldError{
+				Field:   "plugins",
+				Rule:    ValidationRuleDuplicatedPlugin,
+				Message: fmt.Sprintf("plugin id %q is duplicated", pluginInfo.Id),
+			})
+		}
+
+		plugins[pluginInfo.Id] = pluginInfo
+	}
+
+	if _, exists := plugins[flow.FirstPluginToRun]; !exists {
+		report.Flow = append(report.Flow, plugincore.FieldError{
+			Field:   "first_plugin_to_run",
+			Rule:    ValidationRuleFirstPlugin,
+			Message: fmt.Sprintf("first plugin %q not found", flow.FirstPluginToRun),
+		})
+	}
+
+	graph := &pluginGraph{
+		parents:       parentPlugins(flow.Plugins),
+		outputSchemas: make(map[string][]byte),
+	}
+	graph.upstream = upstreamPlugins(flow.Plugins, graph.parents)
+
+	executors := make(map[string]PluginExecutor, len(flow.Plugins))
+
+	for _, pluginInfo := range flow.Plugins {
+		pluginExecutor, err := v.pluginManager.GetBySlug(ctx, pluginInfo.Slug)
+		if err != nil {
+			report.addPluginProblems(pluginInfo.Id, plugincore.FieldError{
+				Field:   "slug",
+				Rule:    ValidationRuleUnknownPlugin,
+				Message: fmt.Sprintf("plugin %q not found: %v", pluginInfo.Slug, err),
+			})
+
+			continue
+		}
+
+		executors[pluginInfo.Id] = pluginExecutor
+
+		if schemaProvider, ok := pluginExecutor.(PluginOutputSchemaProvider); ok && len(schemaProvider.OutputSchema()) > 0 {
+			graph.outputSchemas[pluginInfo.Id] = schemaProvider.OutputSchema()
+		}
+	}
+
+	for _, pluginInfo := range flow.Plugins {
+		for _, nextId := range pluginInfo.NextToBeExecuted {
+			if _, exists := plugins[nextId]; !exists {
+				report.addPluginProblems(pluginInfo.Id, plugincore.FieldError{
+					Field:   "next_to_be_executed",
+					Rule:    ValidationRuleNextPlugin,
+					Message: fmt.Sprintf("next plugin %q not found", nextId),
+				})
+			}
+		}
+
+		switch pluginInfo.OutputValidation {
+		case "", OutputValidationWarn, OutputValidationStrict, OutputValidationOff:
+		default:
+			report.addPluginProblems(pluginInfo.Id, plugincore.FieldError{
+				Field:   "output_validation",
+				Rule:    ValidationRuleOutputValidation,
+				Message: fmt.Sprintf("unknown output validation mode %q", pluginInfo.OutputValidation),
+			})
+		}
+
+		if pluginExecutor, ok := executors[pluginInfo.Id]; ok {
+			report.addPluginProblems(pluginInfo.Id, validatePlugin(pluginExecutor, pluginInfo, graph)...)
+		}
+	}
+
+	triggers := make(map[string]bool, len(flow.Triggers))
+
+	for _, trigger := range flow.Triggers {
+		if trigger.Id == "" || triggers[trigger.Id] {
+			report.Flow = append(report.Flow, plugincore.FieldError{
+				Field:   "triggers",
+				Rule:    ValidationRuleDuplicatedTrigger,
+				Message: fmt.Sprintf("trigger id %q is empty or duplicated", trigger.Id),
+			})
+
+			continue
+		}
+
+		triggers[trigger.Id] = true
+
+		report.addTriggerProblems(trigger.Id, validateTrigger(trigger)...)
+	}
+
+	return report, nil
+}
+
+// validateTrigger checks that the trigger has the configuration of its type,
+// and only it.
+func validateTrigger(trigger FlowTrigger) (problems []plugincore.FieldError) {
+	problem := func(field, format string, args ...any) {
+		problems = append(problems, plugincore.FieldError{
+			Field:   field,
+			Rule:    ValidationRuleTrigger,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	switch trigger.Type {
+	case TriggerTypeManual, TriggerTypeWebhook, TriggerTypeSchedule, TriggerTypeQueue:
+	default:
+		problem("type", "unknown trigger type %q", trigger.Type)
+		return
+	}
+
+	configs := []struct {
+		triggerType TriggerType
+		configured  bool
+	}{
+		{TriggerTypeWebhook, trigger.Webhook != nil},
+		{TriggerTypeSchedule, trigger.Schedule != nil},
+		{TriggerTypeQueue, trigger.Queue != nil},
+	}
+
+	for _, config := range configs {
+		if config.triggerType == trigger.Type && !config.configured {
+			problem(string(config.triggerType), "%s configuration is required", config.triggerType)
+		}
+
+		if config.triggerType != trigger.Type && config.configured {
+			problem(string(config.triggerType), "%s configuration is not allowed on a %s trigger", config.triggerType, trigger.Type)
+		}
+	}
+
+	switch {
+	case trigger.Type == TriggerTypeWebhook && trigger.Webhook != nil:
+		if err := trigger.Webhook.ResponseMode.Validate(); err != nil {
+			problem("webhook.response_mode", "%v", err)
+		}
+
+		if trigger.Webhook.Signature != nil {
+			if err := trigger.Webhook.Signature.Validate(); err != nil {
+				problem("webhook.signature", "%v", err)
+			}
+		}
+	case trigger.Type == TriggerTypeSchedule && trigger.Schedule != nil:
+		if err := scheduler.ValidateCron(trigger.Schedule.Cron, trigger.Schedule.Timezone); err != nil {
+			problem("schedule.cron", "%v", err)
+		}
+	case trigger.Type == TriggerTypeQueue && trigger.Queue != nil:
+		if trigger.Queue.Stream == "" {
+			problem("queue.stream", "queue stream is required")
+		}
+	}
+
+	return problems
+}
+
+func validatePlugin(pluginExecutor PluginExecutor, pluginInfo FlowPlugin, graph *pluginGraph) (problems []plugincore.FieldError) {
+	references, err := plugincore.TemplateReferences(pluginInfo.TemplateMode, pluginInfo.SchemaInput)
+	if err != nil {
+		return []plugincore.FieldError{{
+			Field:   "schema_input",
+			Rule:    ValidationRuleTemplateSyntax,
+			Message: err.Error(),
+		}}
+	}
+
+	for _, reference := range references {
+		if problem, ok := graph.checkReference(pluginInfo.Id, reference); !ok {
+			problems = append(problems, problem)
+		}
+	}
+
+	if schemaProvider, ok := pluginExecutor.(PluginSchemaProvider); ok {
+		fieldErrors, err := plugincore.ValidateStatic(schemaProvider.InputSchema(), pluginInfo.SchemaInput)
+		if err != nil {
+			return append(problems, plugincore.FieldError{
+				Field:   "schema_input",
+				Rule:    ValidationRulePluginSchema,
+				Message: fmt.Sprintf("invalid schema for plugin %q: %v", pluginInfo.Slug, err),
+			})
+		}
+
+		problems = append(problems, fieldErrors...)
+	}
+
+	return problems
+}
+
+// checkReference accepts .data references to fields declared by the output
+// schema of at least one parent plugin, and .sharedForAll references to
+// fields of plugins that run before the current one. Plugins without an
+// output schema accept any field.
+func (g *pluginGraph) checkReference(pluginId string, reference plugincore.TemplateReference) (plugincore.FieldError, bool) {
+	switch reference.Root {
+	case plugincore.ReferenceRootData:
+		parents := g.parents[pluginId]
+		if len(parents) == 0 {
+			return plugincore.FieldError{}, true
+		}
+
+		for _, parentId := range parents {
+			if g.outputHasPath(parentId, reference.Path) {
+				return plugincore.FieldError{}, true
+			}
+		}
+
+		return plugincore.FieldError{
+			Field:   reference.Expression,
+			Rule:    ValidationRuleUnknownField,
+			Message: fmt.Sprintf("field is not declared in the output schema of plugins %v", parents),
+		}, false
+	case plugincore.ReferenceRootSharedForAll:
+		if len(reference.Path) == 0 {
+			return plugincore.FieldError{}, true
+		}
+
+		upstreamId := reference.Path[0]
+		if !g.upstream[pluginId][upstreamId] {
+			return plugincore.FieldError{
+				Field:   reference.Expression,
+				Rule:    ValidationRuleUnknownReference,
+				Message: fmt.Sprintf("plugin %q is not executed before this plugin", upstreamId),
+			}, false
+		}
+
+		if !g.outputHasPath(upstreamId, reference.Path[1:]) {
+			return plugincore.FieldError{
+				Field:   reference.Expression,
+				Rule:    ValidationRuleUnknownField,
+				Message: fmt.Sprintf("field is not declared in the output schema of plugin %q", upstreamId),
+			}, false
+		}
+
+		return plugincore.FieldError{}, true
+	default:
+		return plugincore.FieldError{
+			Field:   reference.Expression,
+			Rule:    ValidationRuleUnknownReference,
+			Message: fmt.Sprintf("unknown template data %q, use .data or .sharedForAll", reference.Root),
+		}, false
+	}
+}
+
+func (g *pluginGraph) outputHasPath(pluginId string, path []string) bool {
+	schema, ok := g.outputSchemas[pluginId]
+	if !ok {
+		return true
+	}
+
+	hasPath, err := plugincore.SchemaHasPath(schema, path)
+
+	return err != nil || hasPath
+}
+
+// parentPlugins returns, for each plugin id, the ids of the plugins that
+// send their output to it.
+func parentPlugins(plugins []FlowPlugin) map[string][]string {
+	parents := make(map[string][]string)
+
+	for _, pluginInfo := range plugins {
+		for _, nextId := range pluginInfo.NextToBeExecuted {
+			if !slices.Contains(parents[nextId], pluginInfo.Id) {
+				parents[nextId] = append(parents[nextId], pluginInfo.Id)
+			}
+		}
+	}
+
+	return parents
+}
+
+// upstreamPlugins returns, for each plugin id, the ids of every plugin that
+// can run before it.
+func upstreamPlugins(plugins []FlowPlugin, parents map[string][]string) map[string]map[string]bool {
+	upstream := make(map[string]map[string]bool, len(plugins))
+
+	for _, pluginInfo := range plugins {
+		visited := make(map[string]bool)
+		pending := append([]string(nil), parents[pluginInfo.Id]...)
+
+		for len(pending) > 0 {
+			current := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+
+			if visited[current] {
+				continue
+			}
+
+			visited[current] = true
+			pending = append(pending, parents[current]...)
+		}
+
+		upstream[pluginInfo.Id] = visited
+	}
+
+	return upstream
+}
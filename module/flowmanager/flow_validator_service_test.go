@@ -0,0 +1,214 @@
+package flowmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/module/webhook"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestFlowValidatorTestSuite(t *testing.T) {
+	suite.Run(t, new(FlowValidatorTestSuite))
+}
+
+type FlowValidatorTestSuite struct {
+	suite.Suite
+	pluginManagerMock *PluginManagerMock
+	flowValidator     *FlowValidator
+	ctx               *yctx.Context
+}
+
+func (s *FlowValidatorTestSuite) SetupTest() {
+	s.ctx = yctx.NewContext(context.Background())
+	s.pluginManagerMock = new(PluginManagerMock)
+	s.flowValidator = NewFlowValidator(s.pluginManagerMock)
+}
+
+func (s *FlowValidatorTestSuite) TestValidate_ReportsProblemsPerPlugin() {
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, "plugin-http").
+		Return(new(PluginExecutorMock), nil)
+
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, "unknown").
+		Return((*PluginExecutorMock)(nil), errors.New("plugin not found"))
+
+	report, err := s.flowValidator.Validate(s.ctx, &Flow{
+		FirstPluginToRun: "missing",
+		Plugins: []FlowPlugin{
+			{
+				Id:               "first",
+				Slug:             "plugin-http",
+				SchemaInput:      `{"a": "{{ .data.x }}", "b": "{{ .env.HOME }}"}`,
+				NextToBeExecuted: []string{"second", "ghost"},
+			},
+			{
+				Id:          "second",
+				Slug:        "plugin-http",
+				SchemaInput: `{"a": "{{ .sharedForAll.first.x }}", "b": "{{ with .data }}{{ .anything }}{{ end }}", "c": "{{ $.sharedForAll.third }}"}`,
+			},
+			{
+				Id:          "third",
+				Slug:        "plugin-http",
+				SchemaInput: `{"a": "{{ .data.x "}`,
+			},
+			{
+				Id:   "fourth",
+				Slug: "unknown",
+			},
+		},
+	})
+	s.NoError(err)
+	s.False(report.Valid())
+
+	s.Len(report.Flow, 1)
+	s.Equal(ValidationRuleFirstPlugin, report.Flow[0].Rule)
+
+	s.Len(report.Plugins["first"], 2)
+	s.Equal(ValidationRuleNextPlugin, report.Plugins["first"][0].Rule)
+	s.Equal(ValidationRuleUnknownReference, report.Plugins["first"][1].Rule)
+	s.Equal(".env.HOME", report.Plugins["first"][1].Field)
+
+	s.Len(report.Plugins["second"], 1)
+	s.Equal(".sharedForAll.third", report.Plugins["second"][0].Field)
+
+	s.Len(report.Plugins["third"], 1)
+	s.Equal(ValidationRuleTemplateSyntax, report.Plugins["third"][0].Rule)
+
+	s.Len(report.Plugins["fourth"], 1)
+	s.Equal(ValidationRuleUnknownPlugin, report.Plugins["fourth"][0].Rule)
+}
+
+func (s *FlowValidatorTestSuite) TestCreateFlow_DoesNotSaveInvalidFlow() {
+	flowWriteRepositoryMock := new(FlowWriteRepositoryMock)
+	flowVersionRepository := NewInMemoryFlowVersionRepository()
+	flowCreator := NewFlowCreator(
+		flowWriteRepositoryMock,
+		NewFlowVersioner(new(FlowReaderRepositoryMock), flowWriteRepositoryMock, flowVersionRepository, s.flowValidator),
+		s.flowValidator,
+	)
+
+	err := flowCreator.CreateFlow(s.ctx, &Flow{FirstPluginToRun: "missing"})
+
+	var flowValidationErr *FlowValidationError
+	s.ErrorAs(err, &flowValidationErr)
+	flowWriteRepositoryMock.AssertNotCalled(s.T(), "Save", mock.Anything, mock.Anything)
+
+	versions, _ := flowVersionRepository.GetByFlowId(s.ctx, "")
+	s.Empty(versions)
+}
+
+func (s *FlowValidatorTestSuite) TestValidate_ChecksReferencesAgainstOutputSchemas() {
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, "auth").
+		Return(&pluginExecutorWithOutputSchemaMock{
+			PluginExecutorMock: new(PluginExecutorMock),
+			outputSchema:       []byte(`{"type": "object", "properties": {"access_token": {"type": "string"}}, "additionalProperties": false}`),
+		}, nil)
+
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, "plugin-http").
+		Return(new(PluginExecutorMock), nil)
+
+	report, err := s.flowValidator.Validate(s.ctx, &Flow{
+		FirstPluginToRun: "auth",
+		Plugins: []FlowPlugin{
+			{
+				Id:               "auth",
+				Slug:             "auth",
+				SchemaInput:      `{"code": "{{ .data.code }}"}`,
+				NextToBeExecuted: []string{"fetch"},
+			},
+			{
+				Id:               "fetch",
+				Slug:             "plugin-http",
+				SchemaInput:      `{"token": "{{ .data.access_token }}", "other": "{{ .data.acess_token }}"}`,
+				NextToBeExecuted: []string{"notify"},
+			},
+			{
+				Id:               "notify",
+				Slug:             "plugin-http",
+				SchemaInput:      `{"token": "{{ .sharedForAll.auth.access_token }}", "any": "{{ .data.whatever }}", "typo": "{{ .sharedForAll.auth.token }}"}`,
+				OutputValidation: "always",
+			},
+		},
+	})
+	s.NoError(err)
+
+	s.Len(report.Plugins["fetch"], 1)
+	s.Equal(ValidationRuleUnknownField, report.Plugins["fetch"][0].Rule)
+	s.Equal(".data.acess_token", report.Plugins["fetch"][0].Field)
+
+	s.Len(report.Plugins["notify"], 2)
+	s.Equal(ValidationRuleOutputValidation, report.Plugins["notify"][0].Rule)
+	s.Equal(".sharedForAll.auth.token", report.Plugins["notify"][1].Field)
+}
+
+func (s *FlowValidatorTestSuite) TestValidate_OpenOutputSchemasAcceptUndeclaredFields() {
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, "auth").
+		Return(&pluginExecutorWithOutputSchemaMock{
+			PluginExecutorMock: new(PluginExecutorMock),
+			outputSchema:       []byte(`{"type": "object", "properties": {"access_token": {"type": "string"}}}`),
+		}, nil)
+
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, "plugin-http").
+		Return(new(PluginExecutorMock), nil)
+
+	report, err := s.flowValidator.Validate(s.ctx, &Flow{
+		FirstPluginToRun: "auth",
+		Plugins: []FlowPlugin{
+			{Id: "auth", Slug: "auth", SchemaInput: `{}`, NextToBeExecuted: []string{"fetch"}},
+			{Id: "fetch", Slug: "plugin-http", SchemaInput: `{"token": "{{ .data.refresh_token }}"}`},
+		},
+	})
+	s.NoError(err)
+	s.Empty(report.Plugins["fetch"])
+}
+
+func (s *FlowValidatorTestSuite) TestValidate_Triggers() {
+	s.pluginManagerMock.
+		On("GetBySlug", mock.Anything, "plugin-http").
+		Return(new(PluginExecutorMock), nil)
+
+	report, err := s.flowValidator.Validate(s.ctx, &Flow{
+		FirstPluginToRun: "first",
+		Plugins:          []FlowPlugin{{Id: "first", Slug: "plugin-http", SchemaInput: `{}`}},
+		Triggers: []FlowTrigger{
+			{Id: "manual", Type: TriggerTypeManual},
+			{Id: "nightly", Type: TriggerTypeSchedule, Schedule: &ScheduleTrigger{Cron: "0 3 * * *", Timezone: "America/Sao_Paulo"}},
+			{Id: "hook", Type: TriggerTypeWebhook, Webhook: &WebhookTrigger{ResponseMode: "later", Signature: &webhook.Signature{Scheme: "md5"}}},
+			{Id: "queue", Type: TriggerTypeQueue, Queue: &QueueTrigger{}, Schedule: &ScheduleTrigger{Cron: "@daily"}},
+			{Id: "broken", Type: TriggerTypeSchedule, Schedule: &ScheduleTrigger{Cron: "every day"}},
+			{Id: "unknown", Type: "email"},
+			{Id: "manual", Type: TriggerTypeManual},
+		},
+	})
+	s.NoError(err)
+	s.False(report.Valid())
+
+	s.Len(report.Flow, 1)
+	s.Equal(ValidationRuleDuplicatedTrigger, report.Flow[0].Rule)
+
+	s.NotContains(report.Triggers, "manual")
+	s.NotContains(report.Triggers, "nightly")
+
+	s.Len(report.Triggers["hook"], 2)
+	s.Equal("webhook.response_mode", report.Triggers["hook"][0].Field)
+	s.Equal("webhook.signature", report.Triggers["hook"][1].Field)
+
+	s.Len(report.Triggers["queue"], 2)
+	s.Equal("schedule", report.Triggers["queue"][0].Field)
+	s.Equal("queue.stream", report.Triggers["queue"][1].Field)
+
+	s.Len(report.Triggers["broken"], 1)
+	s.Equal("schedule.cron", report.Triggers["broken"][0].Field)
+
+	s.Len(report.Triggers["unknown"], 1)
+	s.Equal("type", report.Triggers["unknown"][0].Field)
+}
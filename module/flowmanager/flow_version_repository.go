@@ -0,0 +1,66 @@
+package flowmanager
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var _ FlowVersionRepository = (*InMemoryFlowVersionRepository)(nil)
+
+// InMemoryFlowVersionRepository implementa FlowVersionRepository usando memória
+type InMemoryFlowVersionRepository struct {
+	versions map[string]FlowVersion
+	mu       sync.RWMutex
+}
+
+// NewInMemoryFlowVersionRepository cria uma nova instância do repositório em memória
+func NewInMemoryFlowVersionRepository() *InMemoryFlowVersionRepository {
+	return &InMemoryFlowVersionRepository{
+		versions: make(map[string]FlowVersion),
+	}
+}
+
+func (r *InMemoryFlowVersionRepository) Save(ctx *yctx.Context, version *FlowVersion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.versions[version.Id]; exists {
+		return ErrFlowVersionExists
+	}
+
+	r.versions[version.Id] = *version
+	return nil
+}
+
+func (r *InMemoryFlowVersionRepository) GetByVersion(ctx *yctx.Context, flowId string, version int) (*FlowVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, flowVersion := range r.versions {
+		if flowVersion.FlowId == flowId && flowVersion.Version == version {
+			return &flowVersion, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *InMemoryFlowVersionRepository) GetByFlowId(ctx *yctx.Context, flowId string) ([]FlowVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := make([]FlowVersion, 0)
+	for _, flowVersion := range r.versions {
+		if flowVersion.FlowId == flowId {
+			versions = append(versions, flowVersion)
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+
+	return versions, nil
+}
@@ -0,0 +1,203 @@
+package flowmanager
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var (
+	ErrFlowVersionNotFound = errors.New("flow version not found")
+	// ErrFlowVersionExists is returned when two saves of the same flow
+	// create the same version concurrently.
+	ErrFlowVersionExists = errors.New("flow version already exists")
+	// ErrFlowVersionConflict is returned when the flow was changed since
+	// the version the caller edited.
+	ErrFlowVersionConflict = errors.New("flow was changed by another update")
+)
+
+type (
+	FlowVersionRepository interface {
+		// Save returns ErrFlowVersionExists when the version of the flow
+		// already exists. Versions are never replaced.
+		Save(ctx *yctx.Context, version *FlowVersion) error
+		// GetByVersion returns nil when the version does not exist.
+		GetByVersion(ctx *yctx.Context, flowId string, version int) (item *FlowVersion, err error)
+		// GetByFlowId returns the versions of the flow, oldest first.
+		GetByFlowId(ctx *yctx.Context, flowId string) (items []FlowVersion, err error)
+	}
+
+	FlowVersioner struct {
+		flowReaderRepository  FlowReaderRepository
+		flowWriteRepository   FlowWriteRepository
+		flowVersionRepository FlowVersionRepository
+		flowValidator         *FlowValidator
+		now                   func() time.Time
+	}
+)
+
+func NewFlowVersioner(
+	flowReaderRepository FlowReaderRepository,
+	flowWriteRepository FlowWriteRepository,
+	flowVersionRepository FlowVersionRepository,
+	flowValidator *FlowValidator,
+) *FlowVersioner {
+	return &FlowVersioner{
+		flowReaderRepository:  flowReaderRepository,
+		flowWriteRepository:   flowWriteRepository,
+		flowVersionRepository: flowVersionRepository,
+		flowValidator:         flowValidator,
+		now:                   time.Now,
+	}
+}
+
+// Update validates the flow and saves it as a new version, which becomes the
+// active one. flow.Revision must be the revision the caller edited, otherwise
+// ErrFlowVersionConflict is returned. The tenant and the deployment of the
+// stored flow are kept.
+func (v *FlowVersioner) Update(ctx *yctx.Context, flow *Flow) error {
+	current, err := v.getFlow(ctx, flow.Id)
+	if err != nil {
+		return err
+	}
+
+	if flow.Revision != current.Revision {
+		return fmt.Errorf("%w: editing revision %d, current revision is %d", ErrFlowVersionConflict, flow.Revision, current.Revision)
+	}
+
+	flow.Tenant = current.Tenant
+	flow.DeletedAt = nil
+	flow.CreatedAt = current.CreatedAt
+	flow.UpdatedAt = v.now().UTC()
+
+	report, err := v.flowValidator.Validate(ctx, flow)
+	if err != nil {
+		return err
+	}
+
+	if !report.Valid() {
+		return &FlowValidationError{Report: report}
+	}
+
+	versions, err := v.flowVersionRepository.GetByFlowId(ctx, flow.Id)
+	if err != nil {
+		return err
+	}
+
+	flow.Version = current.Version + 1
+	for _, version := range versions {
+		flow.Version = max(flow.Version, version.Version+1)
+	}
+
+	flow.Revision = current.Revision + 1
+	flow.Deployment = current.Deployment
+
+	// the version is saved before the flow, so the active version always
+	// has a snapshot. An update that then loses the optimistic lock leaves
+	// its version unused; it is never active and the next update skips it.
+	if err = v.saveVersion(ctx, flow); err != nil {
+		return err
+	}
+
+	return v.flowWriteRepository.Update(ctx, flow, current.Revision)
+}
+
+// Versions returns the versions of the flow, oldest first.
+func (v *FlowVersioner) Versions(ctx *yctx.Context, flowId string) ([]FlowVersion, error) {
+	if _, err := v.getFlow(ctx, flowId); err != nil {
+		return nil, err
+	}
+
+	return v.flowVersionRepository.GetByFlowId(ctx, flowId)
+}
+
+// GetVersion returns ErrFlowNotFound when the flow is deleted or of another
+// tenant.
+func (v *FlowVersioner) GetVersion(ctx *yctx.Context, flowId string, version int) (*FlowVersion, error) {
+	if _, err := v.getFlow(ctx, flowId); err != nil {
+		return nil, err
+	}
+
+	flowVersion, err := v.flowVersionRepository.GetByVersion(ctx, flowId, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if flowVersion == nil {
+		return nil, fmt.Errorf("%w: %s version %d", ErrFlowVersionNotFound, flowId, version)
+	}
+
+	return flowVersion, nil
+}
+
+// Diff lists the changes of the flow definition from one version to another.
+func (v *FlowVersioner) Diff(ctx *yctx.Context, flowId string, from, to int) ([]FlowChange, error) {
+	fromVersion, err := v.GetVersion(ctx, flowId, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toVersion, err := v.GetVersion(ctx, flowId, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiffFlows(&fromVersion.Flow, &toVersion.Flow)
+}
+
+// Rollback makes a previous version the active one. The history is kept, so
+// the next Update creates a version after the latest one.
+func (v *FlowVersioner) Rollback(ctx *yctx.Context, flowId string, version int) (*Flow, error) {
+	current, err := v.getFlow(ctx, flowId)
+	if err != nil {
+		return nil, err
+	}
+
+	flowVersion, err := v.GetVersion(ctx, flowId, version)
+	if err != nil {
+		return nil, err
+	}
+
+	flow := flowVersion.Flow
+	flow.Revision = current.Revision + 1
+	flow.Deployment = current.Deployment
+	flow.CreatedAt = current.CreatedAt
+	flow.UpdatedAt = v.now().UTC()
+
+	if err = v.flowWriteRepository.Update(ctx, &flow, current.Revision); err != nil {
+		return nil, err
+	}
+
+	return &flow, nil
+}
+
+// saveVersion stores the definition of the flow as its version Version.
+func (v *FlowVersioner) saveVersion(ctx *yctx.Context, flow *Flow) error {
+	definition := *flow
+	definition.Revision = 0
+	definition.Deployment = nil
+	definition.DeletedAt = nil
+
+	return v.flowVersionRepository.Save(ctx, &FlowVersion{
+		Id:        fmt.Sprintf("%s@%d", flow.Id, flow.Version),
+		FlowId:    flow.Id,
+		Version:   flow.Version,
+		Flow:      definition,
+		CreatedAt: v.now().UTC(),
+	})
+}
+
+func (v *FlowVersioner) getFlow(ctx *yctx.Context, flowId string) (*Flow, error) {
+	flow, err := v.flowReaderRepository.GetById(ctx, flowId)
+	if err != nil {
+		return nil, err
+	}
+
+	if flow == nil {
+		return nil, ErrFlowNotFound
+	}
+
+	return flow, nil
+}
@@ -0,0 +1,226 @@
+package flowmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/ybase"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestFlowVersionerTestSuite(t *testing.T) {
+	suite.Run(t, new(FlowVersionerTestSuite))
+}
+
+type FlowVersionerTestSuite struct {
+	suite.Suite
+	ctx                   *yctx.Context
+	repository            *flowDeployRepositoryFake
+	flowVersionRepository *InMemoryFlowVersionRepository
+	flowVersioner         *FlowVersioner
+	flowCreator           *FlowCreator
+}
+
+func (s *FlowVersionerTestSuite) SetupTest() {
+	s.ctx = yctx.NewContext(context.Background()).WithTenant("tenant-a")
+
+	pluginManagerMock := new(PluginManagerMock)
+	pluginManagerMock.
+		On("GetBySlug", mock.Anything, "plugin-http").
+		Return(new(PluginExecutorMock), nil)
+
+	flowValidator := NewFlowValidator(pluginManagerMock)
+
+	s.repository = &flowDeployRepositoryFake{}
+	s.flowVersionRepository = NewInMemoryFlowVersionRepository()
+	s.flowVersioner = NewFlowVersioner(s.repository, s.repository, s.flowVersionRepository, flowValidator)
+	s.flowCreator = NewFlowCreator(s.repository, s.flowVersioner, flowValidator)
+
+	s.Require().NoError(s.flowCreator.CreateFlow(s.ctx, s.newFlow(`{"request": {"method": "GET"}}`)))
+}
+
+func (s *FlowVersionerTestSuite) newFlow(schemaInput string) *Flow {
+	return &Flow{
+		Id:               "flow-1",
+		Tenant:           "tenant-a",
+		FirstPluginToRun: "fetch",
+		Version:          1,
+		Revision:         1,
+		Plugins: []FlowPlugin{
+			{Id: "fetch", Slug: "plugin-http", SchemaInput: schemaInput},
+		},
+	}
+}
+
+func (s *FlowVersionerTestSuite) TestCreateFlow_SavesFirstVersion() {
+	s.Equal(1, s.repository.flow.Version)
+
+	version, err := s.flowVersioner.GetVersion(s.ctx, "flow-1", 1)
+	s.Require().NoError(err)
+	s.Equal("flow-1@1", version.Id)
+	s.Equal(`{"request": {"method": "GET"}}`, version.Flow.Plugins[0].SchemaInput)
+}
+
+func (s *FlowVersionerTestSuite) TestCreateFlow_GeneratesIdAndTimestamps() {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.flowVersioner.now = func() time.Time { return createdAt }
+
+	flow := s.newFlow(`{"request": {"method": "GET"}}`)
+	flow.Id = ""
+
+	s.Require().NoError(s.flowCreator.CreateFlow(s.ctx, flow))
+	s.NotEmpty(flow.Id)
+	s.NotEqual("flow-1", flow.Id)
+	s.Equal(createdAt, flow.CreatedAt)
+	s.Equal(createdAt, flow.UpdatedAt)
+
+	updatedAt := createdAt.Add(time.Hour)
+	s.flowVersioner.now = func() time.Time { return updatedAt }
+
+	update := s.newFlow(`{"request": {"method": "POST"}}`)
+	update.Id = flow.Id
+
+	s.Require().NoError(s.flowVersioner.Update(s.ctx, update))
+	s.Equal(createdAt, s.repository.flow.CreatedAt)
+	s.Equal(updatedAt, s.repository.flow.UpdatedAt)
+}
+
+func (s *FlowVersionerTestSuite) TestUpdate_CreatesNewVersionKeepingDeployment() {
+	s.repository.flow.Deployment = &FlowDeployment{Status: ybase.DeployStatusInOperation, Version: 1}
+
+	flow := s.newFlow(`{"request": {"method": "POST"}}`)
+	flow.Tenant = "tenant-b"
+
+	s.Require().NoError(s.flowVersioner.Update(s.ctx, flow))
+	s.Equal(2, flow.Version)
+	s.Equal("tenant-a", s.repository.flow.Tenant)
+	s.Equal(2, s.repository.flow.Version)
+	s.Equal(ybase.DeployStatusInOperation, s.repository.flow.DeployStatus())
+
+	versions, err := s.flowVersioner.Versions(s.ctx, "flow-1")
+	s.Require().NoError(err)
+	s.Require().Len(versions, 2)
+	s.Equal(1, versions[0].Version)
+	s.Equal(2, versions[1].Version)
+	s.Nil(versions[1].Flow.Deployment)
+}
+
+func (s *FlowVersionerTestSuite) TestUpdate_RejectsStaleVersion() {
+	first := s.newFlow(`{"request": {"method": "POST"}}`)
+	second := s.newFlow(`{"request": {"method": "PUT"}}`)
+
+	s.Require().NoError(s.flowVersioner.Update(s.ctx, first))
+	s.ErrorIs(s.flowVersioner.Update(s.ctx, second), ErrFlowVersionConflict)
+	s.Equal(`{"request": {"method": "POST"}}`, s.repository.flow.Plugins[0].SchemaInput)
+
+	versions, _ := s.flowVersioner.Versions(s.ctx, "flow-1")
+	s.Len(versions, 2)
+}
+
+func (s *FlowVersionerTestSuite) TestUpdate_RejectsEditOfRolledBackVersion() {
+	s.Require().NoError(s.flowVersioner.Update(s.ctx, s.newFlow(`{"request": {"method": "POST"}}`)))
+
+	_, err := s.flowVersioner.Rollback(s.ctx, "flow-1", 1)
+	s.Require().NoError(err)
+	s.Equal(1, s.repository.flow.Version)
+	s.Equal(3, s.repository.flow.Revision)
+
+	// edited before the update, at version 1 like the rolled back flow
+	stale := s.newFlow(`{"request": {"method": "PUT"}}`)
+	s.ErrorIs(s.flowVersioner.Update(s.ctx, stale), ErrFlowVersionConflict)
+	s.Equal(`{"request": {"method": "GET"}}`, s.repository.flow.Plugins[0].SchemaInput)
+}
+
+// conflictingUpdateRepository loses the optimistic lock of every update.
+type conflictingUpdateRepository struct {
+	*flowDeployRepositoryFake
+}
+
+func (r conflictingUpdateRepository) Update(ctx *yctx.Context, flow *Flow, expectedRevision int) error {
+	return ErrFlowVersionConflict
+}
+
+func (s *FlowVersionerTestSuite) TestUpdate_FailedWriteLeavesUnusedVersion() {
+	s.flowVersioner.flowWriteRepository = conflictingUpdateRepository{s.repository}
+
+	s.ErrorIs(s.flowVersioner.Update(s.ctx, s.newFlow(`{"request": {"method": "POST"}}`)), ErrFlowVersionConflict)
+	s.Equal(1, s.repository.flow.Version)
+
+	// the unused version 2 is skipped by the next update.
+	s.flowVersioner.flowWriteRepository = s.repository
+	s.Require().NoError(s.flowVersioner.Update(s.ctx, s.newFlow(`{"request": {"method": "PUT"}}`)))
+	s.Equal(3, s.repository.flow.Version)
+
+	version, err := s.flowVersioner.GetVersion(s.ctx, "flow-1", 3)
+	s.Require().NoError(err)
+	s.Equal(`{"request": {"method": "PUT"}}`, version.Flow.Plugins[0].SchemaInput)
+}
+
+// failingFlowVersionRepository fails to save every version.
+type failingFlowVersionRepository struct {
+	*InMemoryFlowVersionRepository
+}
+
+func (r failingFlowVersionRepository) Save(ctx *yctx.Context, version *FlowVersion) error {
+	return ErrFlowVersionExists
+}
+
+func (s *FlowVersionerTestSuite) TestUpdate_FailedVersionKeepsFlow() {
+	s.flowVersioner.flowVersionRepository = failingFlowVersionRepository{s.flowVersionRepository}
+
+	s.ErrorIs(s.flowVersioner.Update(s.ctx, s.newFlow(`{"request": {"method": "POST"}}`)), ErrFlowVersionExists)
+	s.Equal(1, s.repository.flow.Version)
+	s.Equal(1, s.repository.flow.Revision)
+	s.Equal(`{"request": {"method": "GET"}}`, s.repository.flow.Plugins[0].SchemaInput)
+
+	// the active version still has its snapshot.
+	_, err := s.flowVersioner.GetVersion(s.ctx, "flow-1", s.repository.flow.Version)
+	s.NoError(err)
+}
+
+func (s *FlowVersionerTestSuite) TestUpdate_InvalidFlowIsNotVersioned() {
+	flow := s.newFlow(`{}`)
+	flow.FirstPluginToRun = "missing"
+
+	var flowValidationErr *FlowValidationError
+	s.ErrorAs(s.flowVersioner.Update(s.ctx, flow), &flowValidationErr)
+
+	versions, _ := s.flowVersioner.Versions(s.ctx, "flow-1")
+	s.Len(versions, 1)
+}
+
+func (s *FlowVersionerTestSuite) TestDiff_ListsChangedFields() {
+	s.Require().NoError(s.flowVersioner.Update(s.ctx, s.newFlow(`{"request": {"method": "POST"}}`)))
+
+	changes, err := s.flowVersioner.Diff(s.ctx, "flow-1", 1, 2)
+	s.Require().NoError(err)
+	s.Equal([]FlowChange{{
+		Path:   "plugins[fetch].schema_input",
+		Change: ChangeTypeChanged,
+		From:   `{"request": {"method": "GET"}}`,
+		To:     `{"request": {"method": "POST"}}`,
+	}}, changes)
+
+	_, err = s.flowVersioner.Diff(s.ctx, "flow-1", 1, 3)
+	s.ErrorIs(err, ErrFlowVersionNotFound)
+}
+
+func (s *FlowVersionerTestSuite) TestRollback_ActivatesVersionAndKeepsHistory() {
+	s.Require().NoError(s.flowVersioner.Update(s.ctx, s.newFlow(`{"request": {"method": "POST"}}`)))
+
+	flow, err := s.flowVersioner.Rollback(s.ctx, "flow-1", 1)
+	s.Require().NoError(err)
+	s.Equal(1, flow.Version)
+	s.Equal(`{"request": {"method": "GET"}}`, s.repository.flow.Plugins[0].SchemaInput)
+
+	next := s.newFlow(`{"request": {"method": "PUT"}}`)
+	next.Revision = flow.Revision
+	s.Require().NoError(s.flowVersioner.Update(s.ctx, next))
+	s.Equal(3, next.Version)
+
+	_, err = s.flowVersioner.Rollback(s.ctx, "missing", 1)
+	s.ErrorIs(err, ErrFlowNotFound)
+}
@@ -0,0 +1,54 @@
+package flowmanager
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var (
+	_ FlowWriteRepository = (*FlowWriteRepositoryMock)(nil)
+)
+
+type FlowWriteRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlowWriteRepositoryMock) Save(ctx *yctx.Context, flow *Flow) (err error) {
+	returns := m.MethodCalled("Save", ctx, flow)
+
+	if index := 0; len(returns) > index {
+		err = returns.Error(index)
+	}
+
+	return
+}
+
+func (m *FlowWriteRepositoryMock) Update(ctx *yctx.Context, flow *Flow, expectedRevision int) (err error) {
+	returns := m.MethodCalled("Update", ctx, flow, expectedRevision)
+
+	if index := 0; len(returns) > index {
+		err = returns.Error(index)
+	}
+
+	return
+}
+
+func (m *FlowWriteRepositoryMock) Delete(ctx *yctx.Context, id string) (err error) {
+	returns := m.MethodCalled("Delete", ctx, id)
+
+	if index := 0; len(returns) > index {
+		err = returns.Error(index)
+	}
+
+	return
+}
+
+func (m *FlowWriteRepositoryMock) Restore(ctx *yctx.Context, id string) (err error) {
+	returns := m.MethodCalled("Restore", ctx, id)
+
+	if index := 0; len(returns) > index {
+		err = returns.Error(index)
+	}
+
+	return
+}
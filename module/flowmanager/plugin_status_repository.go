@@ -10,6 +10,28 @@ import (
 	"github.com/yrn-go/yrn/pkg/yctx"
 )
 
+// PluginStatusKey identifica o status de um plugin em uma execução do fluxo,
+// no seu tenant, para que uma execução não sobrescreva o status das
+// anteriores. Sem tenant a chave começa pelo ID do fluxo.
+func PluginStatusKey(tenant, flowID, executionID, pluginID string) string {
+	key := flowID + "/" + executionID + "/" + pluginID
+	if tenant == "" {
+		return key
+	}
+
+	return tenant + "/" + key
+}
+
+// pluginStatusKey é a chave do status de pluginID na execução do contexto.
+func pluginStatusKey(ctx *yctx.Context, pluginID string) string {
+	return PluginStatusKey(ctx.Tenant(), ctx.FlowId(), ctx.ExecutionId(), pluginID)
+}
+
+// Key é a chave do status, criada por PluginStatusKey.
+func (s PluginStatus) Key() string {
+	return PluginStatusKey(s.Tenant, s.FlowID, s.ExecutionID, s.PluginID)
+}
+
 // RedisPluginStatusRepository implementa PluginStatusRepository usando Redis
 type RedisPluginStatusRepository struct {
 	client *redis.Client
@@ -33,7 +55,7 @@ func (r *RedisPluginStatusRepository) Save(ctx *yctx.Context, status PluginStatu
 	}
 
 	// Cria a chave para o plugin
-	key := fmt.Sprintf("plugin:status:%s", status.PluginID)
+	key := fmt.Sprintf("plugin:status:%s", status.Key())
 
 	// Salva no Redis com TTL
 	err = r.client.Set(ctx.Context(), key, data, r.ttl).Err()
@@ -46,7 +68,7 @@ func (r *RedisPluginStatusRepository) Save(ctx *yctx.Context, status PluginStatu
 
 // GetByPluginID recupera o status de um plugin específico
 func (r *RedisPluginStatusRepository) GetByPluginID(ctx *yctx.Context, pluginID string) (PluginStatus, error) {
-	key := fmt.Sprintf("plugin:status:%s", pluginID)
+	key := fmt.Sprintf("plugin:status:%s", pluginStatusKey(ctx, pluginID))
 
 	data, err := r.client.Get(ctx.Context(), key).Bytes()
 	if err != nil {
@@ -86,6 +108,10 @@ func (r *RedisPluginStatusRepository) GetAll(ctx *yctx.Context) ([]PluginStatus,
 			continue // Ignora erros de unmarshal
 		}
 
+		if !ctx.CanAccess(status.Tenant) {
+			continue
+		}
+
 		statuses = append(statuses, status)
 	}
 
@@ -110,7 +136,7 @@ func (r *InMemoryPluginStatusRepository) Save(ctx *yctx.Context, status PluginSt
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.statuses[status.PluginID] = status
+	r.statuses[status.Key()] = status
 	return nil
 }
 
@@ -119,7 +145,7 @@ func (r *InMemoryPluginStatusRepository) GetByPluginID(ctx *yctx.Context, plugin
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	status, exists := r.statuses[pluginID]
+	status, exists := r.statuses[pluginStatusKey(ctx, pluginID)]
 	if !exists {
 		return PluginStatus{}, fmt.Errorf("plugin status not found for ID: %s", pluginID)
 	}
@@ -134,7 +160,9 @@ func (r *InMemoryPluginStatusRepository) GetAll(ctx *yctx.Context) ([]PluginStat
 
 	statuses := make([]PluginStatus, 0, len(r.statuses))
 	for _, status := range r.statuses {
-		statuses = append(statuses, status)
+		if ctx.CanAccess(status.Tenant) {
+			statuses = append(statuses, status)
+		}
 	}
 
 	return statuses, nil
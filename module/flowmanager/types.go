@@ -1,25 +1,167 @@
 package flowmanager
 
+import (
+	"time"
+
+	"github.com/yrn-go/yrn/module/webhook"
+	"github.com/yrn-go/yrn/pkg/plugincore"
+	"github.com/yrn-go/yrn/pkg/ybase"
+)
+
+type (
+	OutputValidationMode string
+	TriggerType          string
+	DeployAction         string
+	ChangeType           string
+)
+
+const (
+	// OutputValidationWarn logs outputs that do not match the plugin output
+	// schema. It is the default mode.
+	OutputValidationWarn OutputValidationMode = "warn"
+	// OutputValidationStrict fails the plugin when its output does not match
+	// the plugin output schema.
+	OutputValidationStrict OutputValidationMode = "strict"
+	// OutputValidationOff skips the output validation.
+	OutputValidationOff OutputValidationMode = "off"
+
+	// TriggerTypeManual runs the flow through POST /flows/:id/execute.
+	TriggerTypeManual TriggerType = "manual"
+	// TriggerTypeWebhook exposes the flow on /hooks/:webhookId.
+	TriggerTypeWebhook TriggerType = "webhook"
+	// TriggerTypeSchedule runs the flow at the times of a cron expression.
+	TriggerTypeSchedule TriggerType = "schedule"
+	// TriggerTypeQueue runs the flow for each message of a queue.
+	TriggerTypeQueue TriggerType = "queue"
+
+	DeployActionDeploy   DeployAction = "deploy"
+	DeployActionUndeploy DeployAction = "undeploy"
+	DeployActionRollback DeployAction = "rollback"
+
+	ChangeTypeAdded   ChangeType = "added"
+	ChangeTypeRemoved ChangeType = "removed"
+	ChangeTypeChanged ChangeType = "changed"
+)
+
 type (
 	Flow struct {
-		Id               string       `json:"id"`
-		Name             string       `json:"name"`
-		Description      string       `json:"description"`
-		Tenant           string       `json:"tenant"`
-		FirstPluginToRun string       `json:"first_plugin_to_run"`
-		Plugins          []FlowPlugin `json:"plugins"`
-		Version          int          `json:"version"`
+		Id               string        `json:"id" bson:"_id"`
+		Name             string        `json:"name" bson:"name"`
+		Description      string        `json:"description" bson:"description"`
+		Tenant           string        `json:"tenant" bson:"tenant"`
+		Tags             []string      `json:"tags,omitempty" bson:"tags,omitempty"`
+		FirstPluginToRun string        `json:"first_plugin_to_run" bson:"first_plugin_to_run"`
+		Plugins          []FlowPlugin  `json:"plugins" bson:"plugins"`
+		Triggers         []FlowTrigger `json:"triggers,omitempty" bson:"triggers,omitempty"`
+		Version          int           `json:"version" bson:"version"`
+		// Revision is incremented by every write of the flow, rollbacks
+		// included, so unlike Version it never repeats. Updates must send the
+		// revision they edited.
+		Revision int `json:"revision" bson:"revision"`
+		// Deployment is nil until the flow is deployed for the first time.
+		Deployment *FlowDeployment `json:"deployment,omitempty" bson:"deployment,omitempty"`
+		// DeletedAt is set while the flow is soft deleted.
+		DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+		CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+		UpdatedAt time.Time  `json:"updated_at" bson:"updated_at"`
+	}
+
+	// FlowDeployment is the deploy state of a flow.
+	FlowDeployment struct {
+		Status ybase.DeployStatus `json:"status" bson:"status"`
+		// Version is the active version of the flow when it was deployed.
+		Version int `json:"version,omitempty" bson:"version,omitempty"`
+		// Triggers are the triggers started by the deployment.
+		Triggers  []FlowTrigger `json:"triggers,omitempty" bson:"triggers,omitempty"`
+		Error     string        `json:"error,omitempty" bson:"error,omitempty"`
+		UpdatedBy string        `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+		UpdatedAt time.Time     `json:"updated_at" bson:"updated_at"`
+		// Previous is the last deployment that was IN_OPERATION before this
+		// one, used by rollback.
+		Previous *FlowDeployment `json:"previous,omitempty" bson:"previous,omitempty"`
+	}
+
+	// DeployEvent is the audit record of a deploy action.
+	DeployEvent struct {
+		Id     string             `json:"id" bson:"_id"`
+		FlowId string             `json:"flow_id" bson:"flow_id"`
+		Tenant string             `json:"tenant" bson:"tenant"`
+		Action DeployAction       `json:"action" bson:"action"`
+		From   ybase.DeployStatus `json:"from" bson:"from"`
+		To     ybase.DeployStatus `json:"to" bson:"to"`
+		Actor  string             `json:"actor,omitempty" bson:"actor,omitempty"`
+		Error  string             `json:"error,omitempty" bson:"error,omitempty"`
+		At     time.Time          `json:"at" bson:"at"`
+	}
+
+	// FlowVersion is an immutable snapshot of a saved flow. The stored flow
+	// holds a copy of its active version.
+	FlowVersion struct {
+		Id        string    `json:"id" bson:"_id"`
+		FlowId    string    `json:"flow_id" bson:"flow_id"`
+		Version   int       `json:"version" bson:"version"`
+		Flow      Flow      `json:"flow" bson:"flow"`
+		CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	}
+
+	// FlowChange is a difference between two flow versions. Path uses the
+	// JSON names, with the id of plugins and triggers: plugins[fetch].slug.
+	FlowChange struct {
+		Path   string     `json:"path"`
+		Change ChangeType `json:"change"`
+		From   any        `json:"from,omitempty"`
+		To     any        `json:"to,omitempty"`
+	}
+
+	// FlowTrigger is an event that starts the flow while it is deployed.
+	// Only the configuration of its Type is set.
+	FlowTrigger struct {
+		Id       string           `json:"id" bson:"id"`
+		Type     TriggerType      `json:"type" bson:"type"`
+		Webhook  *WebhookTrigger  `json:"webhook,omitempty" bson:"webhook,omitempty"`
+		Schedule *ScheduleTrigger `json:"schedule,omitempty" bson:"schedule,omitempty"`
+		Queue    *QueueTrigger    `json:"queue,omitempty" bson:"queue,omitempty"`
+	}
+
+	WebhookTrigger struct {
+		ResponseMode webhook.ResponseMode `json:"response_mode,omitempty" bson:"response_mode,omitempty"`
+		Signature    *webhook.Signature   `json:"signature,omitempty" bson:"signature,omitempty"`
+	}
+
+	ScheduleTrigger struct {
+		Cron     string `json:"cron" bson:"cron"`
+		Timezone string `json:"timezone,omitempty" bson:"timezone,omitempty"`
+		Input    any    `json:"input,omitempty" bson:"input,omitempty"`
+	}
+
+	// QueueTrigger consumes the messages of Stream with the consumer group
+	// Group. Both are names in the namespace of the tenant of the flow, see
+	// trigger.TenantQueueKey.
+	QueueTrigger struct {
+		Stream string `json:"stream" bson:"stream"`
+		Group  string `json:"group,omitempty" bson:"group,omitempty"`
 	}
 
 	FlowPlugin struct {
-		Id                          string   `json:"id"`
-		Slug                        string   `json:"slug"`
-		Name                        string   `json:"name"`
-		Description                 string   `json:"description"`
-		Version                     int      `json:"version"`
-		SchemaInput                 string   `json:"schema_input"`
-		ContinueEvenWithError       bool     `json:"continue_even_with_error"`
-		ShareResponseWithAllPlugins bool     `json:"share_response_with_all_plugins"`
-		NextToBeExecuted            []string `json:"next_to_be_executed"`
+		Id                          string                  `json:"id" bson:"id"`
+		Slug                        string                  `json:"slug" bson:"slug"`
+		Name                        string                  `json:"name" bson:"name"`
+		Description                 string                  `json:"description" bson:"description"`
+		Version                     int                     `json:"version" bson:"version"`
+		SchemaInput                 string                  `json:"schema_input" bson:"schema_input"`
+		TemplateMode                plugincore.TemplateMode `json:"template_mode,omitempty" bson:"template_mode,omitempty"`
+		OutputValidation            OutputValidationMode    `json:"output_validation,omitempty" bson:"output_validation,omitempty"`
+		ContinueEvenWithError       bool                    `json:"continue_even_with_error" bson:"continue_even_with_error"`
+		ShareResponseWithAllPlugins bool                    `json:"share_response_with_all_plugins" bson:"share_response_with_all_plugins"`
+		NextToBeExecuted            []string                `json:"next_to_be_executed" bson:"next_to_be_executed"`
 	}
 )
+
+// DeployStatus returns ybase.DeployStatusPending when the flow was never deployed.
+func (f *Flow) DeployStatus() ybase.DeployStatus {
+	if f.Deployment == nil {
+		return ybase.DeployStatusPending
+	}
+
+	return f.Deployment.Status
+}
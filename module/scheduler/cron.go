@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// everySchedule fires at the multiples of delay since the zero time, instead
+// of delay after the time the schedule was loaded, so every replica computes
+// the same times for @every and shares the lock of each one.
+type everySchedule struct {
+	delay time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Truncate(s.delay).Add(s.delay)
+}
+
+// parseCron parses a standard 5 fields cron expression, or a descriptor like
+// @hourly and @every 5m, in the given timezone.
+func parseCron(expression, timezone string) (cron.Schedule, error) {
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidSchedule, timezone)
+		}
+
+		expression = "CRON_TZ=" + timezone + " " + expression
+	}
+
+	cronSchedule, err := cronParser.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+
+	if every, ok := cronSchedule.(cron.ConstantDelaySchedule); ok {
+		return everySchedule{delay: every.Delay}, nil
+	}
+
+	return cronSchedule, nil
+}
+
+// ValidateCron returns ErrInvalidSchedule when the expression or the
+// timezone is invalid.
+func ValidateCron(expression, timezone string) error {
+	_, err := parseCron(expression, timezone)
+
+	return err
+}
+
+// NextRun returns the first time after the given one the schedule fires.
+func (s *Schedule) NextRun(after time.Time) (time.Time, error) {
+	cronSchedule, err := parseCron(s.Cron, s.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return cronSchedule.Next(after), nil
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var (
+	_ Locker = (*RedisLocker)(nil)
+	_ Locker = (*InMemoryLocker)(nil)
+)
+
+// RedisLocker implementa Locker com SET NX no Redis, compartilhado entre as réplicas
+type RedisLocker struct {
+	client *redis.Client
+}
+
+func NewRedisLocker(client *redis.Client) *RedisLocker {
+	return &RedisLocker{
+		client: client,
+	}
+}
+
+func (l *RedisLocker) Acquire(ctx *yctx.Context, key string, ttl time.Duration) (bool, error) {
+	acquired, err := l.client.SetNX(ctx.Context(), key, time.Now().UTC().Format(time.RFC3339), ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock %s on Redis: %w", key, err)
+	}
+
+	return acquired, nil
+}
+
+// InMemoryLocker implementa Locker em memória, para uma única réplica
+type InMemoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]time.Time
+}
+
+func NewInMemoryLocker() *InMemoryLocker {
+	return &InMemoryLocker{
+		locks: make(map[string]time.Time),
+	}
+}
+
+func (l *InMemoryLocker) Acquire(ctx *yctx.Context, key string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	for lockKey, expiresAt := range l.locks {
+		if now.After(expiresAt) {
+			delete(l.locks, lockKey)
+		}
+	}
+
+	if _, locked := l.locks[key]; locked {
+		return false, nil
+	}
+
+	l.locks[key] = now.Add(ttl)
+	return true, nil
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var _ ScheduleRepository = (*InMemoryScheduleRepository)(nil)
+
+// InMemoryScheduleRepository implementa ScheduleRepository usando memória
+type InMemoryScheduleRepository struct {
+	schedules map[string]Schedule
+	mu        sync.RWMutex
+}
+
+// NewInMemoryScheduleRepository cria uma nova instância do repositório em memória
+func NewInMemoryScheduleRepository() *InMemoryScheduleRepository {
+	return &InMemoryScheduleRepository{
+		schedules: make(map[string]Schedule),
+	}
+}
+
+func (r *InMemoryScheduleRepository) Save(ctx *yctx.Context, schedule *Schedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.schedules[schedule.Id] = *schedule
+	return nil
+}
+
+func (r *InMemoryScheduleRepository) GetById(ctx *yctx.Context, id string) (*Schedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedule, exists := r.schedules[id]
+	if !exists {
+		return nil, nil
+	}
+
+	return &schedule, nil
+}
+
+func (r *InMemoryScheduleRepository) GetAll(ctx *yctx.Context) ([]Schedule, error) {
+	return r.filter(func(Schedule) bool { return true }), nil
+}
+
+func (r *InMemoryScheduleRepository) GetByFlowId(ctx *yctx.Context, flowId string) ([]Schedule, error) {
+	return r.filter(func(schedule Schedule) bool { return schedule.FlowId == flowId }), nil
+}
+
+func (r *InMemoryScheduleRepository) Delete(ctx *yctx.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.schedules[id]; !exists {
+		return ErrScheduleNotFound
+	}
+
+	delete(r.schedules, id)
+	return nil
+}
+
+func (r *InMemoryScheduleRepository) SaveRun(ctx *yctx.Context, id string, run ScheduleRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schedule, exists := r.schedules[id]
+	if !exists {
+		return ErrScheduleNotFound
+	}
+
+	schedule.LastRun = &run
+	r.schedules[id] = schedule
+	return nil
+}
+
+func (r *InMemoryScheduleRepository) filter(match func(Schedule) bool) []Schedule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedules := make([]Schedule, 0, len(r.schedules))
+	for _, schedule := range r.schedules {
+		if match(schedule) {
+			schedules = append(schedules, schedule)
+		}
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+	})
+
+	return schedules
+}
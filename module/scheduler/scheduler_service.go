@@ -0,0 +1,289 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// reloadInterval is how often the schedules saved by other replicas are loaded.
+	reloadInterval = 30 * time.Second
+	// lockTTL must be longer than the clock difference between replicas.
+	lockTTL = time.Hour
+)
+
+type (
+	Scheduler struct {
+		scheduleRepository ScheduleRepository
+		flowRunner         FlowRunner
+		locker             Locker
+
+		mu      sync.Mutex
+		entries map[string]*entry
+		wake    chan struct{}
+		now     func() time.Time
+	}
+
+	entry struct {
+		schedule Schedule
+		cron     cron.Schedule
+		next     time.Time
+	}
+)
+
+func NewScheduler(scheduleRepository ScheduleRepository, flowRunner FlowRunner, locker Locker) *Scheduler {
+	return &Scheduler{
+		scheduleRepository: scheduleRepository,
+		flowRunner:         flowRunner,
+		locker:             locker,
+		entries:            make(map[string]*entry),
+		wake:               make(chan struct{}, 1),
+		now:                time.Now,
+	}
+}
+
+// Create validates and saves a new schedule for the flow, in the tenant of
+// the context when it has one.
+func (s *Scheduler) Create(ctx *yctx.Context, schedule *Schedule) (err error) {
+	if _, err = parseCron(schedule.Cron, schedule.Timezone); err != nil {
+		return
+	}
+
+	if schedule.FlowId == "" {
+		return fmt.Errorf("%w: flow id is required", ErrInvalidSchedule)
+	}
+
+	// only the contexts that access every tenant choose the tenant.
+	if !ctx.HasAllTenants() {
+		schedule.Tenant = ctx.Tenant()
+	}
+
+	now := s.now().UTC()
+
+	schedule.Id = uuid.NewString()
+	schedule.LastRun = nil
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	if err = s.scheduleRepository.Save(ctx, schedule); err != nil {
+		return
+	}
+
+	s.withNextRun(schedule)
+	s.notify()
+
+	return nil
+}
+
+// Get returns ErrScheduleNotFound for the schedules of other tenants.
+func (s *Scheduler) Get(ctx *yctx.Context, id string) (schedule *Schedule, err error) {
+	schedule, err = s.scheduleRepository.GetById(ctx, id)
+	if err != nil {
+		return
+	}
+
+	if schedule == nil || !ctx.CanAccess(schedule.Tenant) {
+		return nil, ErrScheduleNotFound
+	}
+
+	s.withNextRun(schedule)
+
+	return schedule, nil
+}
+
+func (s *Scheduler) ListByFlow(ctx *yctx.Context, flowId string) (schedules []Schedule, err error) {
+	items, err := s.scheduleRepository.GetByFlowId(ctx, flowId)
+	if err != nil {
+		return
+	}
+
+	schedules = make([]Schedule, 0, len(items))
+	for _, schedule := range items {
+		if ctx.CanAccess(schedule.Tenant) {
+			s.withNextRun(&schedule)
+			schedules = append(schedules, schedule)
+		}
+	}
+
+	return schedules, nil
+}
+
+func (s *Scheduler) Delete(ctx *yctx.Context, id string) (err error) {
+	if _, err = s.Get(ctx, id); err != nil {
+		return
+	}
+
+	if err = s.scheduleRepository.Delete(ctx, id); err != nil {
+		return
+	}
+
+	s.notify()
+
+	return nil
+}
+
+// Run fires the enabled schedules until ctx is done. Every replica may run
+// it: the Locker makes sure each time of a schedule fires only once. Times
+// missed while no replica was running are skipped.
+func (s *Scheduler) Run(ctx *yctx.Context) {
+	var reloadAt time.Time
+
+	for {
+		now := s.now()
+
+		if !now.Before(reloadAt) {
+			if err := s.reload(ctx, now); err != nil {
+				slog.Error("failed to load schedules", slog.Any("error", err))
+			}
+
+			reloadAt = now.Add(reloadInterval)
+		}
+
+		wakeAt := reloadAt
+
+		s.mu.Lock()
+		for _, scheduled := range s.entries {
+			if !scheduled.next.After(now) {
+				go s.fire(ctx, scheduled.schedule, scheduled.next)
+				scheduled.next = scheduled.cron.Next(now)
+			}
+
+			if scheduled.next.Before(wakeAt) {
+				wakeAt = scheduled.next
+			}
+		}
+		s.mu.Unlock()
+
+		timer := time.NewTimer(wakeAt.Sub(now))
+
+		select {
+		case <-ctx.Context().Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+			reloadAt = time.Time{}
+		case <-timer.C:
+		}
+	}
+}
+
+// reload loads the enabled schedules, keeping the next run of the ones whose
+// cron expression did not change.
+func (s *Scheduler) reload(ctx *yctx.Context, now time.Time) error {
+	schedules, err := s.scheduleRepository.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	entries := make(map[string]*entry, len(schedules))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, schedule := range schedules {
+		if !schedule.Enabled {
+			continue
+		}
+
+		if current, ok := s.entries[schedule.Id]; ok && current.schedule.Cron == schedule.Cron && current.schedule.Timezone == schedule.Timezone {
+			current.schedule = schedule
+			entries[schedule.Id] = current
+			continue
+		}
+
+		cronSchedule, err := parseCron(schedule.Cron, schedule.Timezone)
+		if err != nil {
+			slog.Warn("ignoring invalid schedule",
+				slog.String("schedule_id", schedule.Id),
+				slog.Any("error", err))
+			continue
+		}
+
+		entries[schedule.Id] = &entry{
+			schedule: schedule,
+			cron:     cronSchedule,
+			next:     cronSchedule.Next(now),
+		}
+	}
+
+	s.entries = entries
+
+	return nil
+}
+
+// fire runs the flow of the schedule for the scheduled time, unless another
+// replica already did it.
+func (s *Scheduler) fire(ctx *yctx.Context, schedule Schedule, scheduledAt time.Time) {
+	lockKey := fmt.Sprintf("scheduler:lock:%s:%d", schedule.Id, scheduledAt.Unix())
+
+	acquired, err := s.locker.Acquire(ctx, lockKey, lockTTL)
+	if err != nil {
+		slog.Error("failed to lock schedule",
+			slog.String("schedule_id", schedule.Id),
+			slog.Any("error", err))
+		return
+	}
+
+	if !acquired {
+		return
+	}
+
+	run := ScheduleRun{
+		ScheduledAt: scheduledAt.UTC(),
+		StartedAt:   s.now().UTC(),
+	}
+
+	runCtx := yctx.NewContext(context.WithoutCancel(ctx.Context())).WithTenant(schedule.Tenant)
+
+	_, err = s.flowRunner.Do(runCtx, schedule.FlowId, map[string]any{
+		"schedule_id":  schedule.Id,
+		"scheduled_at": run.ScheduledAt.Format(time.RFC3339),
+		"input":        schedule.Input,
+	})
+
+	run.FinishedAt = s.now().UTC()
+
+	if err != nil {
+		run.Error = err.Error()
+
+		slog.Error("scheduled flow execution failed",
+			slog.String("schedule_id", schedule.Id),
+			slog.String("flow_id", schedule.FlowId),
+			slog.Any("error", err))
+	}
+
+	if err = s.scheduleRepository.SaveRun(ctx, schedule.Id, run); err != nil {
+		slog.Error("failed to save schedule run",
+			slog.String("schedule_id", schedule.Id),
+			slog.Any("error", err))
+	}
+}
+
+func (s *Scheduler) withNextRun(schedule *Schedule) {
+	schedule.NextRunAt = nil
+
+	if !schedule.Enabled {
+		return
+	}
+
+	if next, err := schedule.NextRun(s.now()); err == nil {
+		next = next.UTC()
+		schedule.NextRunAt = &next
+	}
+}
+
+// notify makes Run reload the schedules.
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
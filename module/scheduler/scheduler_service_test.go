@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestScheduler(t *testing.T) {
+	suite.Run(t, new(SchedulerTestSuite))
+}
+
+type flowRunnerStub struct {
+	mu      sync.Mutex
+	events  []map[string]any
+	tenants []string
+	err     error
+}
+
+func (f *flowRunnerStub) Do(ctx *yctx.Context, flowId string, eventRequestData any) (any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, eventRequestData.(map[string]any))
+	f.tenants = append(f.tenants, ctx.Tenant())
+	return nil, f.err
+}
+
+func (f *flowRunnerStub) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.events)
+}
+
+type SchedulerTestSuite struct {
+	suite.Suite
+	ctx                *yctx.Context
+	scheduleRepository *InMemoryScheduleRepository
+	flowRunner         *flowRunnerStub
+	locker             *InMemoryLocker
+	scheduler          *Scheduler
+}
+
+func (s *SchedulerTestSuite) SetupTest() {
+	s.ctx = yctx.NewContext(context.Background())
+	s.scheduleRepository = NewInMemoryScheduleRepository()
+	s.flowRunner = &flowRunnerStub{}
+	s.locker = NewInMemoryLocker()
+	s.scheduler = NewScheduler(s.scheduleRepository, s.flowRunner, s.locker)
+}
+
+func (s *SchedulerTestSuite) TestCreate_ComputesNextRunInTimezone() {
+	s.scheduler.now = func() time.Time {
+		return time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+	}
+
+	schedule := &Schedule{FlowId: "flow-1", Cron: "0 9 * * *", Timezone: "America/Sao_Paulo", Enabled: true}
+	s.NoError(s.scheduler.Create(s.ctx, schedule))
+	s.NotEmpty(schedule.Id)
+
+	stored, err := s.scheduler.Get(s.ctx, schedule.Id)
+	s.NoError(err)
+	s.Equal(time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC), *stored.NextRunAt)
+	s.Nil(stored.LastRun)
+}
+
+func (s *SchedulerTestSuite) TestCreate_RejectsInvalidSchedules() {
+	for _, schedule := range []*Schedule{
+		{FlowId: "flow-1", Cron: "not a cron"},
+		{FlowId: "flow-1", Cron: "* * * * *", Timezone: "Mars/Olympus"},
+		{Cron: "* * * * *"},
+	} {
+		s.ErrorIs(s.scheduler.Create(s.ctx, schedule), ErrInvalidSchedule)
+	}
+}
+
+func (s *SchedulerTestSuite) TestFire_OnlyOnceAcrossReplicas() {
+	var (
+		scheduledAt = time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+		schedule    = &Schedule{FlowId: "flow-1", Cron: "30 12 * * *", Input: map[string]any{"a": 1}, Enabled: true}
+		replica     = NewScheduler(s.scheduleRepository, s.flowRunner, s.locker)
+	)
+
+	s.NoError(s.scheduler.Create(s.ctx, schedule))
+
+	s.scheduler.fire(s.ctx, *schedule, scheduledAt)
+	replica.fire(s.ctx, *schedule, scheduledAt)
+
+	s.Equal(1, s.flowRunner.calls())
+	s.Equal(map[string]any{
+		"schedule_id":  schedule.Id,
+		"scheduled_at": "2025-03-10T12:30:00Z",
+		"input":        map[string]any{"a": 1},
+	}, s.flowRunner.events[0])
+
+	stored, err := s.scheduler.Get(s.ctx, schedule.Id)
+	s.NoError(err)
+	s.Equal(scheduledAt, stored.LastRun.ScheduledAt)
+	s.Empty(stored.LastRun.Error)
+}
+
+func (s *SchedulerTestSuite) TestFire_RecordsError() {
+	s.flowRunner.err = errors.New("flow not found")
+
+	schedule := &Schedule{FlowId: "flow-1", Cron: "@hourly", Enabled: true}
+	s.NoError(s.scheduler.Create(s.ctx, schedule))
+
+	s.scheduler.fire(s.ctx, *schedule, time.Now())
+
+	stored, err := s.scheduler.Get(s.ctx, schedule.Id)
+	s.NoError(err)
+	s.Equal("flow not found", stored.LastRun.Error)
+}
+
+func (s *SchedulerTestSuite) TestRun_FiresEnabledSchedules() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.NoError(s.scheduler.Create(s.ctx, &Schedule{FlowId: "flow-1", Cron: "@every 1s", Enabled: true}))
+	s.NoError(s.scheduler.Create(s.ctx, &Schedule{FlowId: "flow-2", Cron: "@every 1s", Enabled: false}))
+
+	go s.scheduler.Run(yctx.NewContext(ctx))
+	go NewScheduler(s.scheduleRepository, s.flowRunner, s.locker).Run(yctx.NewContext(ctx))
+
+	s.Eventually(func() bool {
+		return s.flowRunner.calls() >= 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+
+	s.flowRunner.mu.Lock()
+	defer s.flowRunner.mu.Unlock()
+
+	seen := make(map[any]bool)
+	for _, event := range s.flowRunner.events {
+		s.False(seen[event["scheduled_at"]], "fired twice at %v", event["scheduled_at"])
+		seen[event["scheduled_at"]] = true
+	}
+}
+
+func (s *SchedulerTestSuite) TestReload_EveryFiresAtTheSameTimesOnEveryReplica() {
+	var (
+		loadedAt = time.Date(2025, 3, 10, 12, 30, 17, 0, time.UTC)
+		replica  = NewScheduler(s.scheduleRepository, s.flowRunner, s.locker)
+		schedule = &Schedule{FlowId: "flow-1", Cron: "@every 10m", Enabled: true}
+	)
+
+	s.NoError(s.scheduler.Create(s.ctx, schedule))
+
+	s.NoError(s.scheduler.reload(s.ctx, loadedAt))
+	s.NoError(replica.reload(s.ctx, loadedAt.Add(4*time.Minute+23*time.Second)))
+
+	next := time.Date(2025, 3, 10, 12, 40, 0, 0, time.UTC)
+	s.Equal(next, s.scheduler.entries[schedule.Id].next)
+	s.Equal(next, replica.entries[schedule.Id].next)
+
+	s.scheduler.fire(s.ctx, *schedule, s.scheduler.entries[schedule.Id].next)
+	replica.fire(s.ctx, *schedule, replica.entries[schedule.Id].next)
+
+	s.Equal(1, s.flowRunner.calls())
+}
+
+func (s *SchedulerTestSuite) TestDelete() {
+	schedule := &Schedule{FlowId: "flow-1", Cron: "@daily", Enabled: true}
+	s.NoError(s.scheduler.Create(s.ctx, schedule))
+
+	schedules, err := s.scheduler.ListByFlow(s.ctx, "flow-1")
+	s.NoError(err)
+	s.Len(schedules, 1)
+	s.NotNil(schedules[0].NextRunAt)
+
+	s.NoError(s.scheduler.Delete(s.ctx, schedule.Id))
+	s.ErrorIs(s.scheduler.Delete(s.ctx, schedule.Id), ErrScheduleNotFound)
+
+	_, err = s.scheduler.Get(s.ctx, schedule.Id)
+	s.ErrorIs(err, ErrScheduleNotFound)
+}
+
+func (s *SchedulerTestSuite) TestContextTenant_ScopesSchedules() {
+	ctxA, ctxB := s.ctx.WithTenant("tenant-a"), s.ctx.WithTenant("tenant-b")
+
+	schedule := &Schedule{FlowId: "flow-1", Cron: "@daily", Enabled: true}
+	s.NoError(s.scheduler.Create(ctxA, schedule))
+	s.Equal("tenant-a", schedule.Tenant)
+
+	_, err := s.scheduler.Get(ctxB, schedule.Id)
+	s.ErrorIs(err, ErrScheduleNotFound)
+
+	schedules, err := s.scheduler.ListByFlow(ctxB, "flow-1")
+	s.NoError(err)
+	s.Empty(schedules)
+
+	s.ErrorIs(s.scheduler.Delete(ctxB, schedule.Id), ErrScheduleNotFound)
+
+	s.scheduler.fire(s.ctx, *schedule, time.Now())
+	s.Equal([]string{"tenant-a"}, s.flowRunner.tenants)
+
+	_, err = s.scheduler.Get(ctxA, schedule.Id)
+	s.NoError(err)
+}
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var (
+	ErrScheduleNotFound = errors.New("schedule not found")
+	ErrInvalidSchedule  = errors.New("invalid schedule")
+)
+
+type (
+	// Schedule runs a flow at the times of a cron expression, evaluated in
+	// Timezone (UTC when empty).
+	Schedule struct {
+		Id       string `json:"id" bson:"_id"`
+		FlowId   string `json:"flow_id" bson:"flow_id"`
+		Tenant   string `json:"tenant" bson:"tenant"`
+		Cron     string `json:"cron" bson:"cron"`
+		Timezone string `json:"timezone,omitempty" bson:"timezone,omitempty"`
+		Input    any    `json:"input,omitempty" bson:"input,omitempty"`
+		Enabled  bool   `json:"enabled" bson:"enabled"`
+		// TriggerId is set when the schedule belongs to a trigger of the flow.
+		TriggerId string       `json:"trigger_id,omitempty" bson:"trigger_id,omitempty"`
+		LastRun   *ScheduleRun `json:"last_run,omitempty" bson:"last_run,omitempty"`
+		NextRunAt *time.Time   `json:"next_run_at,omitempty" bson:"-"`
+		CreatedAt time.Time    `json:"created_at" bson:"created_at"`
+		UpdatedAt time.Time    `json:"updated_at" bson:"updated_at"`
+	}
+
+	// ScheduleRun is one firing of a schedule.
+	ScheduleRun struct {
+		ScheduledAt time.Time `json:"scheduled_at" bson:"scheduled_at"`
+		StartedAt   time.Time `json:"started_at" bson:"started_at"`
+		FinishedAt  time.Time `json:"finished_at" bson:"finished_at"`
+		Error       string    `json:"error,omitempty" bson:"error,omitempty"`
+	}
+
+	ScheduleRepository interface {
+		// Save creates the schedule or replaces the one with the same id.
+		Save(ctx *yctx.Context, schedule *Schedule) (err error)
+		// GetById returns nil when the schedule does not exist.
+		GetById(ctx *yctx.Context, id string) (item *Schedule, err error)
+		GetAll(ctx *yctx.Context) (items []Schedule, err error)
+		GetByFlowId(ctx *yctx.Context, flowId string) (items []Schedule, err error)
+		// Delete returns ErrScheduleNotFound when the schedule does not exist.
+		Delete(ctx *yctx.Context, id string) (err error)
+		// SaveRun records the last run without touching the other fields.
+		SaveRun(ctx *yctx.Context, id string, run ScheduleRun) (err error)
+	}
+
+	// Locker guarantees that only one replica fires a schedule at a given time.
+	Locker interface {
+		// Acquire returns false when the key is already locked.
+		Acquire(ctx *yctx.Context, key string, ttl time.Duration) (acquired bool, err error)
+	}
+
+	// FlowRunner is implemented by flowmanager.FlowExecutor.
+	FlowRunner interface {
+		Do(ctx *yctx.Context, flowId string, eventRequestData any) (response any, err error)
+	}
+)
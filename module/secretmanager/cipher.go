@@ -0,0 +1,88 @@
+package secretmanager
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	// EnvSecretsKey holds the base64 encoded 32 bytes key used to encrypt secrets.
+	EnvSecretsKey = "SECRETS_KEY"
+
+	keySize = 32
+)
+
+var ErrSecretsKeyMissing = errors.New("missing environment variable: " + EnvSecretsKey)
+
+// Cipher encrypts secret values with AES-256-GCM. The tenant and the name of
+// the secret are authenticated with the value, so an encrypted value cannot
+// be moved to another secret.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("secrets key must have %d bytes, got %d", keySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// NewCipherFromEnv builds a Cipher with the key in SECRETS_KEY.
+func NewCipherFromEnv() (*Cipher, error) {
+	encodedKey := os.Getenv(EnvSecretsKey)
+	if encodedKey == "" {
+		return nil, ErrSecretsKeyMissing
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", EnvSecretsKey, err)
+	}
+
+	return NewCipher(key)
+}
+
+func (c *Cipher) Encrypt(tenant, name string, value []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, value, additionalData(tenant, name)), nil
+}
+
+func (c *Cipher) Decrypt(tenant, name string, encrypted []byte) ([]byte, error) {
+	if len(encrypted) < c.aead.NonceSize() {
+		return nil, errors.New("encrypted secret is too short")
+	}
+
+	nonce, sealed := encrypted[:c.aead.NonceSize()], encrypted[c.aead.NonceSize():]
+
+	value, err := c.aead.Open(nil, nonce, sealed, additionalData(tenant, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret %q: %w", name, err)
+	}
+
+	return value, nil
+}
+
+func additionalData(tenant, name string) []byte {
+	return []byte(tenant + "\x00" + name)
+}
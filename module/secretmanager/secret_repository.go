@@ -0,0 +1,75 @@
+package secretmanager
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var _ SecretRepository = (*InMemorySecretRepository)(nil)
+
+// InMemorySecretRepository implementa SecretRepository usando memória
+type InMemorySecretRepository struct {
+	secrets map[string]map[string]Secret
+	mu      sync.RWMutex
+}
+
+// NewInMemorySecretRepository cria uma nova instância do repositório em memória
+func NewInMemorySecretRepository() *InMemorySecretRepository {
+	return &InMemorySecretRepository{
+		secrets: make(map[string]map[string]Secret),
+	}
+}
+
+func (r *InMemorySecretRepository) Save(ctx *yctx.Context, secret *Secret) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.secrets[secret.Tenant] == nil {
+		r.secrets[secret.Tenant] = make(map[string]Secret)
+	}
+
+	r.secrets[secret.Tenant][secret.Name] = *secret
+	return nil
+}
+
+func (r *InMemorySecretRepository) GetByName(ctx *yctx.Context, tenant, name string) (*Secret, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	secret, exists := r.secrets[tenant][name]
+	if !exists {
+		return nil, nil
+	}
+
+	return &secret, nil
+}
+
+func (r *InMemorySecretRepository) GetAll(ctx *yctx.Context, tenant string) ([]Secret, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	secrets := make([]Secret, 0, len(r.secrets[tenant]))
+	for _, secret := range r.secrets[tenant] {
+		secrets = append(secrets, secret)
+	}
+
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Name < secrets[j].Name
+	})
+
+	return secrets, nil
+}
+
+func (r *InMemorySecretRepository) Delete(ctx *yctx.Context, tenant, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.secrets[tenant][name]; !exists {
+		return ErrSecretNotFound
+	}
+
+	delete(r.secrets[tenant], name)
+	return nil
+}
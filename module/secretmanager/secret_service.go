@@ -0,0 +1,115 @@
+package secretmanager
+
+import (
+	"time"
+
+	"github.com/yrn-go/yrn/pkg/plugincore"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+type SecretService struct {
+	secretRepository SecretRepository
+	cipher           *Cipher
+}
+
+func NewSecretService(secretRepository SecretRepository, cipher *Cipher) *SecretService {
+	return &SecretService{
+		secretRepository: secretRepository,
+		cipher:           cipher,
+	}
+}
+
+// Put creates or replaces the secret name of tenant with value. A context
+// with another tenant gets ErrSecretNotFound, as if the tenant had no secrets.
+func (s *SecretService) Put(ctx *yctx.Context, tenant, name, value string) (secret *Secret, err error) {
+	if !ctx.CanAccess(tenant) {
+		return nil, ErrSecretNotFound
+	}
+
+	if !plugincore.ValidSecretName(name) {
+		return nil, ErrInvalidSecretName
+	}
+
+	if value == "" {
+		return nil, ErrEmptySecretValue
+	}
+
+	encryptedValue, err := s.cipher.Encrypt(tenant, name, []byte(value))
+	if err != nil {
+		return
+	}
+
+	secret, err = s.secretRepository.GetByName(ctx, tenant, name)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	if secret == nil {
+		secret = &Secret{
+			Tenant:    tenant,
+			Name:      name,
+			CreatedAt: now,
+		}
+	}
+
+	secret.EncryptedValue = encryptedValue
+	secret.UpdatedAt = now
+
+	if err = s.secretRepository.Save(ctx, secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// Get returns the secret without decrypting its value.
+func (s *SecretService) Get(ctx *yctx.Context, tenant, name string) (secret *Secret, err error) {
+	if !ctx.CanAccess(tenant) {
+		return nil, ErrSecretNotFound
+	}
+
+	secret, err = s.secretRepository.GetByName(ctx, tenant, name)
+	if err != nil {
+		return
+	}
+
+	if secret == nil {
+		return nil, ErrSecretNotFound
+	}
+
+	return secret, nil
+}
+
+func (s *SecretService) List(ctx *yctx.Context, tenant string) (secrets []Secret, err error) {
+	if !ctx.CanAccess(tenant) {
+		return []Secret{}, nil
+	}
+
+	return s.secretRepository.GetAll(ctx, tenant)
+}
+
+func (s *SecretService) Delete(ctx *yctx.Context, tenant, name string) (err error) {
+	if !ctx.CanAccess(tenant) {
+		return ErrSecretNotFound
+	}
+
+	return s.secretRepository.Delete(ctx, tenant, name)
+}
+
+// Resolve returns the decrypted value of a secret. It is used only while
+// a flow runs, to render {{ secret "name" }} references.
+func (s *SecretService) Resolve(ctx *yctx.Context, tenant, name string) (value string, err error) {
+	secret, err := s.Get(ctx, tenant, name)
+	if err != nil {
+		return
+	}
+
+	decrypted, err := s.cipher.Decrypt(tenant, name, secret.EncryptedValue)
+	if err != nil {
+		return
+	}
+
+	return string(decrypted), nil
+}
@@ -0,0 +1,144 @@
+package secretmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestSecretService(t *testing.T) {
+	suite.Run(t, new(SecretServiceTestSuite))
+}
+
+type SecretServiceTestSuite struct {
+	suite.Suite
+	ctx              *yctx.Context
+	secretRepository *InMemorySecretRepository
+	secretService    *SecretService
+}
+
+func (s *SecretServiceTestSuite) SetupTest() {
+	cipher, err := NewCipher([]byte("0123456789abcdef0123456789abcdef"))
+	s.Require().NoError(err)
+
+	s.ctx = yctx.NewContext(context.Background()).WithAllTenants()
+	s.secretRepository = NewInMemorySecretRepository()
+	s.secretService = NewSecretService(s.secretRepository, cipher)
+}
+
+func (s *SecretServiceTestSuite) TestPut_StoresEncryptedValue() {
+	secret, err := s.secretService.Put(s.ctx, "tenant-a", "api-token", "my-token")
+	s.NoError(err)
+	s.Equal("api-token", secret.Name)
+
+	stored, err := s.secretRepository.GetByName(s.ctx, "tenant-a", "api-token")
+	s.NoError(err)
+	s.NotContains(string(stored.EncryptedValue), "my-token")
+
+	value, err := s.secretService.Resolve(s.ctx, "tenant-a", "api-token")
+	s.NoError(err)
+	s.Equal("my-token", value)
+}
+
+func (s *SecretServiceTestSuite) TestPut_KeepsCreationDate() {
+	first, err := s.secretService.Put(s.ctx, "tenant-a", "api-token", "v1")
+	s.NoError(err)
+	createdAt := first.CreatedAt
+
+	second, err := s.secretService.Put(s.ctx, "tenant-a", "api-token", "v2")
+	s.NoError(err)
+	s.Equal(createdAt, second.CreatedAt)
+
+	value, err := s.secretService.Resolve(s.ctx, "tenant-a", "api-token")
+	s.NoError(err)
+	s.Equal("v2", value)
+}
+
+func (s *SecretServiceTestSuite) TestPut_ValidatesInput() {
+	_, err := s.secretService.Put(s.ctx, "tenant-a", "bad name", "value")
+	s.ErrorIs(err, ErrInvalidSecretName)
+
+	_, err = s.secretService.Put(s.ctx, "tenant-a", "name", "")
+	s.ErrorIs(err, ErrEmptySecretValue)
+}
+
+func (s *SecretServiceTestSuite) TestResolve_IsScopedByTenant() {
+	_, err := s.secretService.Put(s.ctx, "tenant-a", "api-token", "token-a")
+	s.NoError(err)
+
+	_, err = s.secretService.Resolve(s.ctx, "tenant-b", "api-token")
+	s.ErrorIs(err, ErrSecretNotFound)
+
+	secrets, err := s.secretService.List(s.ctx, "tenant-b")
+	s.NoError(err)
+	s.Empty(secrets)
+
+	s.ErrorIs(s.secretService.Delete(s.ctx, "tenant-b", "api-token"), ErrSecretNotFound)
+}
+
+func (s *SecretServiceTestSuite) TestResolve_RejectsValueMovedToAnotherSecret() {
+	_, err := s.secretService.Put(s.ctx, "tenant-a", "api-token", "token-a")
+	s.NoError(err)
+
+	stored, err := s.secretRepository.GetByName(s.ctx, "tenant-a", "api-token")
+	s.NoError(err)
+
+	s.NoError(s.secretRepository.Save(s.ctx, &Secret{
+		Tenant:         "tenant-b",
+		Name:           "api-token",
+		EncryptedValue: stored.EncryptedValue,
+	}))
+
+	_, err = s.secretService.Resolve(s.ctx, "tenant-b", "api-token")
+	s.ErrorContains(err, "failed to decrypt")
+}
+
+func (s *SecretServiceTestSuite) TestDelete() {
+	_, err := s.secretService.Put(s.ctx, "tenant-a", "api-token", "token-a")
+	s.NoError(err)
+
+	s.NoError(s.secretService.Delete(s.ctx, "tenant-a", "api-token"))
+
+	_, err = s.secretService.Get(s.ctx, "tenant-a", "api-token")
+	s.ErrorIs(err, ErrSecretNotFound)
+}
+
+func (s *SecretServiceTestSuite) TestNewCipherFromEnv() {
+	s.T().Setenv(EnvSecretsKey, "")
+	_, err := NewCipherFromEnv()
+	s.ErrorIs(err, ErrSecretsKeyMissing)
+
+	s.T().Setenv(EnvSecretsKey, base64.StdEncoding.EncodeToString([]byte("short")))
+	_, err = NewCipherFromEnv()
+	s.ErrorContains(err, "32 bytes")
+
+	s.T().Setenv(EnvSecretsKey, base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	_, err = NewCipherFromEnv()
+	s.NoError(err)
+}
+
+func (s *SecretServiceTestSuite) TestContextTenant_DeniesOtherTenants() {
+	_, err := s.secretService.Put(s.ctx, "tenant-a", "api-token", "token-a")
+	s.NoError(err)
+
+	ctx := s.ctx.WithTenant("tenant-b")
+
+	_, err = s.secretService.Resolve(ctx, "tenant-a", "api-token")
+	s.ErrorIs(err, ErrSecretNotFound)
+
+	_, err = s.secretService.Put(ctx, "tenant-a", "api-token", "token-b")
+	s.ErrorIs(err, ErrSecretNotFound)
+
+	secrets, err := s.secretService.List(ctx, "tenant-a")
+	s.NoError(err)
+	s.Empty(secrets)
+
+	s.ErrorIs(s.secretService.Delete(ctx, "tenant-a", "api-token"), ErrSecretNotFound)
+
+	value, err := s.secretService.Resolve(s.ctx.WithTenant("tenant-a"), "tenant-a", "api-token")
+	s.NoError(err)
+	s.Equal("token-a", value)
+}
@@ -0,0 +1,36 @@
+package secretmanager
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var (
+	ErrSecretNotFound    = errors.New("secret not found")
+	ErrInvalidSecretName = errors.New("invalid secret name: use letters, numbers, '_', '.' and '-' (max 128)")
+	ErrEmptySecretValue  = errors.New("secret value cannot be empty")
+)
+
+type (
+	// Secret is a named value of a tenant. Only the encrypted value is
+	// stored and it is never serialized to JSON.
+	Secret struct {
+		Tenant         string    `json:"tenant" bson:"tenant"`
+		Name           string    `json:"name" bson:"name"`
+		EncryptedValue []byte    `json:"-" bson:"encrypted_value"`
+		CreatedAt      time.Time `json:"created_at" bson:"created_at"`
+		UpdatedAt      time.Time `json:"updated_at" bson:"updated_at"`
+	}
+
+	SecretRepository interface {
+		// Save creates the secret or replaces the one with the same tenant and name.
+		Save(ctx *yctx.Context, secret *Secret) (err error)
+		// GetByName returns nil when the secret does not exist.
+		GetByName(ctx *yctx.Context, tenant, name string) (item *Secret, err error)
+		GetAll(ctx *yctx.Context, tenant string) (items []Secret, err error)
+		// Delete returns ErrSecretNotFound when the secret does not exist.
+		Delete(ctx *yctx.Context, tenant, name string) (err error)
+	}
+)
@@ -0,0 +1,84 @@
+package trigger
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var _ flowmanager.TriggerDispatcher = (*Dispatcher)(nil)
+
+type Dispatcher struct {
+	listeners map[flowmanager.TriggerType]Listener
+}
+
+// NewDispatcher creates a Dispatcher with a listener per trigger type. Manual
+// triggers need no listener, the flow is run through the API.
+func NewDispatcher(listeners map[flowmanager.TriggerType]Listener) *Dispatcher {
+	return &Dispatcher{
+		listeners: listeners,
+	}
+}
+
+// Start replaces the running listeners of the flow by the ones of its
+// triggers. When a trigger fails to start, the flow is left with no listener.
+// The listeners run in the tenant of the flow.
+func (d *Dispatcher) Start(ctx *yctx.Context, flow *flowmanager.Flow) error {
+	ctx = ctx.WithTenant(flow.Tenant)
+
+	for _, trigger := range flow.Triggers {
+		if _, err := d.listener(trigger.Type); err != nil {
+			return err
+		}
+	}
+
+	if err := d.Stop(ctx, flow); err != nil {
+		return err
+	}
+
+	for _, trigger := range flow.Triggers {
+		listener, _ := d.listener(trigger.Type)
+		if listener == nil {
+			continue
+		}
+
+		if err := listener.Start(ctx, flow, trigger); err != nil {
+			err = fmt.Errorf("failed to start trigger %q: %w", trigger.Id, err)
+
+			return errors.Join(err, d.Stop(ctx, flow))
+		}
+	}
+
+	return nil
+}
+
+// Stop stops all the listeners of the flow.
+func (d *Dispatcher) Stop(ctx *yctx.Context, flow *flowmanager.Flow) error {
+	ctx = ctx.WithTenant(flow.Tenant)
+
+	var errs []error
+
+	for triggerType, listener := range d.listeners {
+		if err := listener.Stop(ctx, flow); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s triggers: %w", triggerType, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// listener returns a nil listener for manual triggers.
+func (d *Dispatcher) listener(triggerType flowmanager.TriggerType) (Listener, error) {
+	if triggerType == flowmanager.TriggerTypeManual {
+		return nil, nil
+	}
+
+	listener, ok := d.listeners[triggerType]
+	if !ok || listener == nil {
+		return nil, fmt.Errorf("%w %q", ErrListenerMissing, triggerType)
+	}
+
+	return listener, nil
+}
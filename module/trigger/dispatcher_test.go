@@ -0,0 +1,135 @@
+package trigger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/module/scheduler"
+	"github.com/yrn-go/yrn/module/webhook"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestDispatcher(t *testing.T) {
+	suite.Run(t, new(DispatcherTestSuite))
+}
+
+type flowRunnerStub struct{}
+
+func (f *flowRunnerStub) Do(ctx *yctx.Context, flowId string, eventRequestData any) (any, error) {
+	return nil, nil
+}
+
+type DispatcherTestSuite struct {
+	suite.Suite
+	ctx            *yctx.Context
+	scheduler      *scheduler.Scheduler
+	webhookService *webhook.WebhookService
+	dispatcher     *Dispatcher
+	flow           *flowmanager.Flow
+}
+
+func (s *DispatcherTestSuite) SetupTest() {
+	s.ctx = yctx.NewContext(context.Background()).WithTenant("tenant-a")
+	s.scheduler = scheduler.NewScheduler(scheduler.NewInMemoryScheduleRepository(), &flowRunnerStub{}, scheduler.NewInMemoryLocker())
+	s.webhookService = webhook.NewWebhookService(webhook.NewInMemoryWebhookRepository(), &flowRunnerStub{}, nil)
+	s.dispatcher = NewDispatcher(map[flowmanager.TriggerType]Listener{
+		flowmanager.TriggerTypeSchedule: NewScheduleListener(s.scheduler),
+		flowmanager.TriggerTypeWebhook:  NewWebhookListener(s.webhookService),
+	})
+
+	s.flow = &flowmanager.Flow{
+		Id:     "flow-1",
+		Tenant: "tenant-a",
+		Triggers: []flowmanager.FlowTrigger{
+			{Id: "manual", Type: flowmanager.TriggerTypeManual},
+			{Id: "nightly", Type: flowmanager.TriggerTypeSchedule, Schedule: &flowmanager.ScheduleTrigger{Cron: "0 3 * * *"}},
+			{Id: "hook", Type: flowmanager.TriggerTypeWebhook, Webhook: &flowmanager.WebhookTrigger{}},
+		},
+	}
+}
+
+func (s *DispatcherTestSuite) TestStart_StartsAndStopsListeners() {
+	s.Require().NoError(s.scheduler.Create(s.ctx, &scheduler.Schedule{FlowId: "flow-1", Cron: "@hourly", Enabled: true}))
+
+	s.Require().NoError(s.dispatcher.Start(s.ctx, s.flow))
+
+	schedules, err := s.scheduler.ListByFlow(s.ctx, "flow-1")
+	s.NoError(err)
+	s.Len(schedules, 2)
+
+	webhooks, err := s.webhookService.ListByFlow(s.ctx, "flow-1")
+	s.NoError(err)
+	s.Require().Len(webhooks, 1)
+	s.Equal("hook", webhooks[0].TriggerId)
+	s.Equal("tenant-a", webhooks[0].Tenant)
+	s.True(webhooks[0].Enabled)
+
+	webhookId := webhooks[0].Id
+
+	s.flow.Triggers[2].Webhook.ResponseMode = webhook.ResponseModeSync
+	s.Require().NoError(s.dispatcher.Start(s.ctx, s.flow))
+
+	schedules, _ = s.scheduler.ListByFlow(s.ctx, "flow-1")
+	s.Len(schedules, 2)
+
+	webhooks, _ = s.webhookService.ListByFlow(s.ctx, "flow-1")
+	s.Require().Len(webhooks, 1)
+	s.Equal(webhookId, webhooks[0].Id)
+	s.Equal(webhook.ResponseModeSync, webhooks[0].ResponseMode)
+
+	s.Require().NoError(s.dispatcher.Stop(s.ctx, s.flow))
+
+	schedules, _ = s.scheduler.ListByFlow(s.ctx, "flow-1")
+	s.Require().Len(schedules, 1)
+	s.Empty(schedules[0].TriggerId)
+
+	webhooks, _ = s.webhookService.ListByFlow(s.ctx, "flow-1")
+	s.Require().Len(webhooks, 1)
+	s.False(webhooks[0].Enabled)
+}
+
+func (s *DispatcherTestSuite) TestStart_RemovedTriggersAreStopped() {
+	s.Require().NoError(s.dispatcher.Start(s.ctx, s.flow))
+
+	s.flow.Triggers = s.flow.Triggers[:1]
+	s.Require().NoError(s.dispatcher.Start(s.ctx, s.flow))
+
+	schedules, _ := s.scheduler.ListByFlow(s.ctx, "flow-1")
+	s.Empty(schedules)
+
+	webhooks, _ := s.webhookService.ListByFlow(s.ctx, "flow-1")
+	s.Require().Len(webhooks, 1)
+	s.False(webhooks[0].Enabled)
+}
+
+func (s *DispatcherTestSuite) TestStart_MissingListenerStartsNothing() {
+	s.flow.Triggers = append(s.flow.Triggers, flowmanager.FlowTrigger{
+		Id:    "orders",
+		Type:  flowmanager.TriggerTypeQueue,
+		Queue: &flowmanager.QueueTrigger{Stream: "orders"},
+	})
+
+	s.ErrorIs(s.dispatcher.Start(s.ctx, s.flow), ErrListenerMissing)
+
+	schedules, _ := s.scheduler.ListByFlow(s.ctx, "flow-1")
+	s.Empty(schedules)
+}
+
+func (s *DispatcherTestSuite) TestStart_FailedTriggerStopsTheOthers() {
+	s.flow.Triggers = append(s.flow.Triggers, flowmanager.FlowTrigger{
+		Id:       "broken",
+		Type:     flowmanager.TriggerTypeSchedule,
+		Schedule: &flowmanager.ScheduleTrigger{Cron: "every day"},
+	})
+
+	s.ErrorIs(s.dispatcher.Start(s.ctx, s.flow), scheduler.ErrInvalidSchedule)
+
+	schedules, _ := s.scheduler.ListByFlow(s.ctx, "flow-1")
+	s.Empty(schedules)
+
+	webhooks, _ := s.webhookService.ListByFlow(s.ctx, "flow-1")
+	s.Require().Len(webhooks, 1)
+	s.False(webhooks[0].Enabled)
+}
@@ -0,0 +1,445 @@
+package trigger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	queueReadBlock  = 5 * time.Second
+	queueReadCount  = 10
+	queueRetryDelay = time.Second
+	// queueClaimIdle is how long a message stays unacknowledged, because the
+	// flow failed or the replica stopped, before it is delivered again.
+	queueClaimIdle = time.Minute
+	// queueMaxDeliveries moves a message to the dead letter stream when the
+	// flow fails that many times.
+	queueMaxDeliveries = 5
+	// queueReloadInterval is how often the consumers started by other
+	// replicas are loaded.
+	queueReloadInterval = 30 * time.Second
+
+	// queueConsumersKey is the Redis hash with the consumers of the deployed
+	// flows, by flow and trigger id.
+	queueConsumersKey = "yrn:queue:consumers"
+	// deadLetterSuffix is appended to the stream name to get the stream of
+	// the messages that failed queueMaxDeliveries times.
+	deadLetterSuffix = ":dead"
+
+	// queueTenantPrefix starts the Redis keys of the streams and groups of a
+	// tenant, see TenantQueueKey.
+	queueTenantPrefix = "yrn:tenant:"
+)
+
+var _ Listener = (*QueueListener)(nil)
+
+type (
+	// QueueListener consumes Redis Streams with consumer groups, so the
+	// messages of a stream are shared by the replicas consuming it. The
+	// streams and groups of the triggers are in the namespace of the tenant of
+	// the flow (TenantQueueKey), so a flow never reads the messages of
+	// another tenant. A message
+	// is acknowledged when the flow succeeds; failed messages are delivered
+	// again after queueClaimIdle and moved to the dead letter stream after
+	// queueMaxDeliveries failures. The consumers are saved in Redis, so Run
+	// starts them on every replica, including after a restart.
+	QueueListener struct {
+		redisClient *redis.Client
+		flowRunner  FlowRunner
+		consumer    string
+
+		mu        sync.Mutex
+		consumers map[string]runningConsumer
+	}
+
+	// queueConsumer is the consumer of a queue trigger saved in
+	// queueConsumersKey.
+	queueConsumer struct {
+		FlowId string `json:"flow_id"`
+		Tenant string `json:"tenant"`
+		Stream string `json:"stream"`
+		Group  string `json:"group"`
+	}
+
+	runningConsumer struct {
+		consumer queueConsumer
+		stop     context.CancelFunc
+	}
+)
+
+func NewQueueListener(redisClient *redis.Client, flowRunner FlowRunner) *QueueListener {
+	return &QueueListener{
+		redisClient: redisClient,
+		flowRunner:  flowRunner,
+		consumer:    uuid.NewString(),
+		consumers:   make(map[string]runningConsumer),
+	}
+}
+
+// TenantQueueKey is the Redis key of the stream or consumer group name of
+// the queue triggers of tenant. The producers of a tenant write to
+// TenantQueueKey(tenant, stream). The tenant is escaped, so the keys of two
+// tenants never collide.
+func TenantQueueKey(tenant, name string) string {
+	return tenantQueuePrefix(tenant) + name
+}
+
+func tenantQueuePrefix(tenant string) string {
+	return queueTenantPrefix + url.QueryEscape(tenant) + ":"
+}
+
+func newQueueConsumer(flow *flowmanager.Flow, trigger flowmanager.FlowTrigger) queueConsumer {
+	group := trigger.Queue.Group
+	if group == "" {
+		group = "yrn:" + flow.Id + ":" + trigger.Id
+	}
+
+	return queueConsumer{
+		FlowId: flow.Id,
+		Tenant: flow.Tenant,
+		Stream: TenantQueueKey(flow.Tenant, trigger.Queue.Stream),
+		Group:  TenantQueueKey(flow.Tenant, group),
+	}
+}
+
+// scoped moves the consumers saved before the streams and groups were in
+// the namespace of the tenant into it.
+func (c queueConsumer) scoped() queueConsumer {
+	prefix := tenantQueuePrefix(c.Tenant)
+
+	for _, name := range []*string{&c.Stream, &c.Group} {
+		if !strings.HasPrefix(*name, prefix) {
+			*name = prefix + *name
+		}
+	}
+
+	return c
+}
+
+// stream is the name of the stream in the trigger, without the namespace of
+// the tenant.
+func (c queueConsumer) stream() string {
+	return strings.TrimPrefix(c.Stream, tenantQueuePrefix(c.Tenant))
+}
+
+func (l *QueueListener) Start(ctx *yctx.Context, flow *flowmanager.Flow, trigger flowmanager.FlowTrigger) error {
+	consumer := newQueueConsumer(flow, trigger)
+
+	if err := l.createGroup(ctx, consumer); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(consumer)
+	if err != nil {
+		return err
+	}
+
+	key := flow.Id + "/" + trigger.Id
+
+	if err = l.redisClient.HSet(ctx.Context(), queueConsumersKey, key, data).Err(); err != nil {
+		return fmt.Errorf("failed to save queue consumer: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.start(key, consumer)
+
+	return nil
+}
+
+func (l *QueueListener) Stop(ctx *yctx.Context, flow *flowmanager.Flow) error {
+	l.mu.Lock()
+	for key, running := range l.consumers {
+		if strings.HasPrefix(key, flow.Id+"/") {
+			running.stop()
+			delete(l.consumers, key)
+		}
+	}
+	l.mu.Unlock()
+
+	keys, err := l.redisClient.HKeys(ctx.Context(), queueConsumersKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to load queue consumers: %w", err)
+	}
+
+	var saved []string
+	for _, key := range keys {
+		if strings.HasPrefix(key, flow.Id+"/") {
+			saved = append(saved, key)
+		}
+	}
+
+	if len(saved) == 0 {
+		return nil
+	}
+
+	if err = l.redisClient.HDel(ctx.Context(), queueConsumersKey, saved...).Err(); err != nil {
+		return fmt.Errorf("failed to delete queue consumers: %w", err)
+	}
+
+	return nil
+}
+
+// Run starts the saved consumers and keeps the running ones in sync with
+// them until ctx is done, then stops them.
+func (l *QueueListener) Run(ctx *yctx.Context) {
+	ticker := time.NewTicker(queueReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := l.reload(ctx); err != nil {
+			slog.Error("failed to load queue consumers", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Context().Done():
+			l.stopAll()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reload starts the saved consumers that are not running and stops the
+// running ones that were deleted, by this or another replica.
+func (l *QueueListener) reload(ctx *yctx.Context) error {
+	saved, err := l.redisClient.HGetAll(ctx.Context(), queueConsumersKey).Result()
+	if err != nil {
+		return err
+	}
+
+	consumers := make(map[string]queueConsumer, len(saved))
+	for key, data := range saved {
+		var consumer queueConsumer
+		if err = json.Unmarshal([]byte(data), &consumer); err != nil {
+			slog.Warn("ignoring invalid queue consumer",
+				slog.String("key", key),
+				slog.Any("error", err))
+			continue
+		}
+
+		consumers[key] = consumer.scoped()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, running := range l.consumers {
+		if consumer, ok := consumers[key]; !ok || consumer != running.consumer {
+			running.stop()
+			delete(l.consumers, key)
+		}
+	}
+
+	for key, consumer := range consumers {
+		if _, ok := l.consumers[key]; ok {
+			continue
+		}
+
+		if err = l.createGroup(ctx, consumer); err != nil {
+			slog.Error("failed to start queue consumer",
+				slog.String("flow_id", consumer.FlowId),
+				slog.String("stream", consumer.Stream),
+				slog.Any("error", err))
+			continue
+		}
+
+		l.start(key, consumer)
+	}
+
+	return nil
+}
+
+func (l *QueueListener) createGroup(ctx *yctx.Context, consumer queueConsumer) error {
+	err := l.redisClient.XGroupCreateMkStream(ctx.Context(), consumer.Stream, consumer.Group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	return nil
+}
+
+// start replaces the running consumer of key. It must be called with mu
+// locked.
+func (l *QueueListener) start(key string, consumer queueConsumer) {
+	consumerCtx, cancel := context.WithCancel(context.Background())
+
+	if running, ok := l.consumers[key]; ok {
+		running.stop()
+	}
+
+	l.consumers[key] = runningConsumer{consumer: consumer, stop: cancel}
+
+	go l.consume(yctx.NewContext(consumerCtx).WithTenant(consumer.Tenant), consumer)
+}
+
+func (l *QueueListener) stopAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, running := range l.consumers {
+		running.stop()
+		delete(l.consumers, key)
+	}
+}
+
+func (l *QueueListener) consume(ctx *yctx.Context, consumer queueConsumer) {
+	var reclaimAt time.Time
+
+	for ctx.Context().Err() == nil {
+		if now := time.Now(); !now.Before(reclaimAt) {
+			l.reclaim(ctx, consumer)
+			reclaimAt = now.Add(queueClaimIdle / 2)
+		}
+
+		streams, err := l.redisClient.XReadGroup(ctx.Context(), &redis.XReadGroupArgs{
+			Group:    consumer.Group,
+			Consumer: l.consumer,
+			Streams:  []string{consumer.Stream, ">"},
+			Count:    queueReadCount,
+			Block:    queueReadBlock,
+		}).Result()
+		if errors.Is(err, redis.Nil) || ctx.Context().Err() != nil {
+			continue
+		}
+
+		if err != nil {
+			slog.Error("failed to read queue",
+				slog.String("flow_id", consumer.FlowId),
+				slog.String("stream", consumer.Stream),
+				slog.Any("error", err))
+
+			sleep(ctx, queueRetryDelay)
+			continue
+		}
+
+		for _, messages := range streams {
+			for _, message := range messages.Messages {
+				l.run(ctx, consumer, message, 1)
+			}
+		}
+	}
+}
+
+// reclaim delivers again the messages that were not acknowledged for
+// queueClaimIdle by any consumer of the group.
+func (l *QueueListener) reclaim(ctx *yctx.Context, consumer queueConsumer) {
+	pending, err := l.redisClient.XPendingExt(ctx.Context(), &redis.XPendingExtArgs{
+		Stream: consumer.Stream,
+		Group:  consumer.Group,
+		Idle:   queueClaimIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  queueReadCount,
+	}).Result()
+	if err != nil {
+		slog.Error("failed to read pending queue messages",
+			slog.String("flow_id", consumer.FlowId),
+			slog.String("stream", consumer.Stream),
+			slog.Any("error", err))
+		return
+	}
+
+	for _, entry := range pending {
+		// the message is not returned when another consumer claimed it first
+		messages, err := l.redisClient.XClaim(ctx.Context(), &redis.XClaimArgs{
+			Stream:   consumer.Stream,
+			Group:    consumer.Group,
+			Consumer: l.consumer,
+			MinIdle:  queueClaimIdle,
+			Messages: []string{entry.ID},
+		}).Result()
+		if err != nil {
+			slog.Error("failed to claim queue message",
+				slog.String("flow_id", consumer.FlowId),
+				slog.String("message_id", entry.ID),
+				slog.Any("error", err))
+			continue
+		}
+
+		for _, message := range messages {
+			l.run(ctx, consumer, message, entry.RetryCount+1)
+		}
+	}
+}
+
+// run acknowledges the message when the flow succeeds. Otherwise it stays
+// pending to be reclaimed, until the last delivery moves it to the dead
+// letter stream.
+func (l *QueueListener) run(ctx *yctx.Context, consumer queueConsumer, message redis.XMessage, deliveries int64) {
+	_, err := l.flowRunner.Do(ctx, consumer.FlowId, map[string]any{
+		"stream":     consumer.stream(),
+		"message_id": message.ID,
+		"values":     message.Values,
+	})
+	if err == nil {
+		l.ack(ctx, consumer, message.ID)
+		return
+	}
+
+	slog.Error("queue flow execution failed",
+		slog.String("flow_id", consumer.FlowId),
+		slog.String("message_id", message.ID),
+		slog.Int64("deliveries", deliveries),
+		slog.Any("error", err))
+
+	if deliveries >= queueMaxDeliveries {
+		l.deadLetter(ctx, consumer, message, err)
+	}
+}
+
+func (l *QueueListener) ack(ctx *yctx.Context, consumer queueConsumer, messageId string) {
+	if err := l.redisClient.XAck(ctx.Context(), consumer.Stream, consumer.Group, messageId).Err(); err != nil {
+		slog.Error("failed to acknowledge queue message",
+			slog.String("flow_id", consumer.FlowId),
+			slog.String("message_id", messageId),
+			slog.Any("error", err))
+	}
+}
+
+// deadLetter adds the message, with its id and the error of the flow, to
+// the dead letter stream and acknowledges it.
+func (l *QueueListener) deadLetter(ctx *yctx.Context, consumer queueConsumer, message redis.XMessage, cause error) {
+	values := make(map[string]any, len(message.Values)+2)
+	maps.Copy(values, message.Values)
+	values["_message_id"] = message.ID
+	values["_error"] = cause.Error()
+
+	_, err := l.redisClient.TxPipelined(ctx.Context(), func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx.Context(), &redis.XAddArgs{Stream: consumer.Stream + deadLetterSuffix, Values: values})
+		pipe.XAck(ctx.Context(), consumer.Stream, consumer.Group, message.ID)
+
+		return nil
+	})
+	if err != nil {
+		slog.Error("failed to move queue message to the dead letter stream",
+			slog.String("flow_id", consumer.FlowId),
+			slog.String("message_id", message.ID),
+			slog.Any("error", err))
+	}
+}
+
+// sleep waits for delay or until ctx is done.
+func sleep(ctx *yctx.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Context().Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,57 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/module/flowmanager"
+)
+
+func TestQueueListener(t *testing.T) {
+	suite.Run(t, new(QueueListenerTestSuite))
+}
+
+type QueueListenerTestSuite struct {
+	suite.Suite
+}
+
+func (s *QueueListenerTestSuite) trigger(stream, group string) flowmanager.FlowTrigger {
+	return flowmanager.FlowTrigger{
+		Id:    "orders",
+		Type:  flowmanager.TriggerTypeQueue,
+		Queue: &flowmanager.QueueTrigger{Stream: stream, Group: group},
+	}
+}
+
+func (s *QueueListenerTestSuite) TestNewQueueConsumer_ScopesStreamAndGroupByTenant() {
+	consumer := newQueueConsumer(&flowmanager.Flow{Id: "flow-1", Tenant: "tenant-a"}, s.trigger("orders", ""))
+
+	s.Equal("yrn:tenant:tenant-a:orders", consumer.Stream)
+	s.Equal("yrn:tenant:tenant-a:yrn:flow-1:orders", consumer.Group)
+	s.Equal("orders", consumer.stream())
+
+	// the same stream and group in another tenant are other Redis keys.
+	other := newQueueConsumer(&flowmanager.Flow{Id: "flow-2", Tenant: "tenant-b"}, s.trigger("orders", consumer.Group))
+	s.NotEqual(consumer.Stream, other.Stream)
+	s.NotEqual(consumer.Group, other.Group)
+
+	// a tenant can not name the keys of another one.
+	spoofed := newQueueConsumer(&flowmanager.Flow{Id: "flow-3", Tenant: "tenant-b"}, s.trigger(consumer.Stream, consumer.Group))
+	s.NotEqual(consumer.Stream, spoofed.Stream)
+	s.NotEqual(consumer.Group, spoofed.Group)
+
+	colliding := newQueueConsumer(&flowmanager.Flow{Id: "flow-4", Tenant: "tenant"}, s.trigger("tenant-a:orders", ""))
+	s.NotEqual(consumer.Stream, colliding.Stream)
+
+	colliding = newQueueConsumer(&flowmanager.Flow{Id: "flow-5", Tenant: "tenant-a:x"}, s.trigger("orders", ""))
+	s.NotEqual(TenantQueueKey("tenant-a", "x:orders"), colliding.Stream)
+}
+
+func (s *QueueListenerTestSuite) TestScoped_MovesSavedConsumersIntoTenant() {
+	legacy := queueConsumer{FlowId: "flow-1", Tenant: "tenant-a", Stream: "orders", Group: "yrn:flow-1:orders"}
+
+	scoped := legacy.scoped()
+	s.Equal("yrn:tenant:tenant-a:orders", scoped.Stream)
+	s.Equal("yrn:tenant:tenant-a:yrn:flow-1:orders", scoped.Group)
+	s.Equal(scoped, scoped.scoped())
+}
@@ -0,0 +1,53 @@
+package trigger
+
+import (
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/module/scheduler"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var _ Listener = (*ScheduleListener)(nil)
+
+// ScheduleListener creates a schedule for each schedule trigger.
+type ScheduleListener struct {
+	scheduler *scheduler.Scheduler
+}
+
+func NewScheduleListener(scheduler *scheduler.Scheduler) *ScheduleListener {
+	return &ScheduleListener{
+		scheduler: scheduler,
+	}
+}
+
+func (l *ScheduleListener) Start(ctx *yctx.Context, flow *flowmanager.Flow, trigger flowmanager.FlowTrigger) error {
+	return l.scheduler.Create(ctx, &scheduler.Schedule{
+		FlowId:    flow.Id,
+		Tenant:    flow.Tenant,
+		Cron:      trigger.Schedule.Cron,
+		Timezone:  trigger.Schedule.Timezone,
+		Input:     trigger.Schedule.Input,
+		Enabled:   true,
+		TriggerId: trigger.Id,
+	})
+}
+
+// Stop deletes the schedules created from triggers. Schedules created
+// through the schedules API are kept.
+func (l *ScheduleListener) Stop(ctx *yctx.Context, flow *flowmanager.Flow) error {
+	schedules, err := l.scheduler.ListByFlow(ctx, flow.Id)
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		if schedule.TriggerId == "" {
+			continue
+		}
+
+		if err = l.scheduler.Delete(ctx, schedule.Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
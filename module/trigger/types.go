@@ -0,0 +1,29 @@
+package trigger
+
+import (
+	"errors"
+
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var (
+	ErrListenerMissing = errors.New("no listener for trigger type")
+)
+
+type (
+	// Listener starts the flow when the events of one trigger type happen.
+	Listener interface {
+		// Start listens to the events of the trigger. It is called again for
+		// the same trigger when the flow is deployed again.
+		Start(ctx *yctx.Context, flow *flowmanager.Flow, trigger flowmanager.FlowTrigger) error
+		// Stop stops listening to all the triggers of the flow, including
+		// the ones that were removed from the flow since it was deployed.
+		Stop(ctx *yctx.Context, flow *flowmanager.Flow) error
+	}
+
+	// FlowRunner is implemented by flowmanager.FlowExecutor.
+	FlowRunner interface {
+		Do(ctx *yctx.Context, flowId string, eventRequestData any) (response any, err error)
+	}
+)
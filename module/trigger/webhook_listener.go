@@ -0,0 +1,74 @@
+package trigger
+
+import (
+	"github.com/yrn-go/yrn/module/flowmanager"
+	"github.com/yrn-go/yrn/module/webhook"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var _ Listener = (*WebhookListener)(nil)
+
+// WebhookListener enables a webhook for each webhook trigger. The webhook of
+// a trigger is disabled instead of deleted on Stop, so its URL does not
+// change when the flow is deployed again.
+type WebhookListener struct {
+	webhookService *webhook.WebhookService
+}
+
+func NewWebhookListener(webhookService *webhook.WebhookService) *WebhookListener {
+	return &WebhookListener{
+		webhookService: webhookService,
+	}
+}
+
+func (l *WebhookListener) Start(ctx *yctx.Context, flow *flowmanager.Flow, trigger flowmanager.FlowTrigger) error {
+	webhooks, err := l.webhookService.ListByFlow(ctx, flow.Id)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range webhooks {
+		if item.TriggerId != trigger.Id {
+			continue
+		}
+
+		item.Tenant = flow.Tenant
+		item.ResponseMode = trigger.Webhook.ResponseMode
+		item.Signature = trigger.Webhook.Signature
+		item.Enabled = true
+
+		return l.webhookService.Update(ctx, &item)
+	}
+
+	return l.webhookService.Create(ctx, &webhook.Webhook{
+		FlowId:       flow.Id,
+		Tenant:       flow.Tenant,
+		ResponseMode: trigger.Webhook.ResponseMode,
+		Signature:    trigger.Webhook.Signature,
+		Enabled:      true,
+		TriggerId:    trigger.Id,
+	})
+}
+
+// Stop disables the webhooks created from triggers. Webhooks created through
+// the webhooks API are kept.
+func (l *WebhookListener) Stop(ctx *yctx.Context, flow *flowmanager.Flow) error {
+	webhooks, err := l.webhookService.ListByFlow(ctx, flow.Id)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range webhooks {
+		if item.TriggerId == "" || !item.Enabled {
+			continue
+		}
+
+		item.Enabled = false
+
+		if err = l.webhookService.Update(ctx, &item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MaxBodySize limits the body of webhook requests.
+const MaxBodySize = 1 << 20
+
+var ErrBodyTooLarge = errors.New("webhook body too large")
+
+// NewRequest reads the HTTP request. JSON bodies are decoded, other bodies
+// are kept as text.
+func NewRequest(r *http.Request) (*Request, error) {
+	rawBody, err := io.ReadAll(io.LimitReader(r.Body, MaxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook body: %w", err)
+	}
+
+	if len(rawBody) > MaxBodySize {
+		return nil, ErrBodyTooLarge
+	}
+
+	request := &Request{
+		Method:  r.Method,
+		Headers: make(map[string]string, len(r.Header)),
+		Query:   make(map[string]string),
+		RawBody: rawBody,
+		header:  r.Header,
+	}
+
+	for name, values := range r.Header {
+		request.Headers[name] = strings.Join(values, ", ")
+	}
+
+	for name, values := range r.URL.Query() {
+		request.Query[name] = strings.Join(values, ",")
+	}
+
+	if len(rawBody) == 0 {
+		return request, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		if err = json.Unmarshal(rawBody, &request.Body); err != nil {
+			return nil, fmt.Errorf("%w: invalid json body: %v", ErrInvalidWebhook, err)
+		}
+
+		return request, nil
+	}
+
+	request.Body = string(rawBody)
+
+	return request, nil
+}
+
+// eventRequestData is what the flow receives as .data in the first plugin.
+// The signature header is left out, it is only used to verify the request.
+func (r *Request) eventRequestData(signature *Signature) map[string]any {
+	headers := r.Headers
+
+	if signature != nil {
+		headers = maps.Clone(r.Headers)
+		delete(headers, http.CanonicalHeaderKey(signature.header()))
+	}
+
+	return map[string]any{
+		"method":  r.Method,
+		"headers": headers,
+		"query":   r.Query,
+		"body":    r.Body,
+	}
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	headerGitHubSignature = "X-Hub-Signature-256"
+	headerStripeSignature = "Stripe-Signature"
+	defaultHMACHeader     = "X-Signature"
+
+	// stripeTolerance rejects replayed Stripe requests.
+	stripeTolerance = 5 * time.Minute
+)
+
+// Validate returns ErrInvalidWebhook when the scheme is unknown or the
+// secret name is missing.
+func (s *Signature) Validate() error {
+	switch s.Scheme {
+	case SignatureSchemeGitHub, SignatureSchemeStripe, SignatureSchemeHMACSHA256:
+	default:
+		return fmt.Errorf("%w: unknown signature scheme %q", ErrInvalidWebhook, s.Scheme)
+	}
+
+	if s.SecretName == "" {
+		return fmt.Errorf("%w: signature secret_name is required", ErrInvalidWebhook)
+	}
+
+	return nil
+}
+
+// verify checks the signature of the request headers and body with key.
+func (s *Signature) verify(key []byte, headers http.Header, body []byte, now time.Time) error {
+	switch s.Scheme {
+	case SignatureSchemeStripe:
+		return verifyStripe(key, headers.Get(s.header()), body, now)
+	default:
+		return verifyHex(key, body, strings.TrimPrefix(headers.Get(s.header()), "sha256="))
+	}
+}
+
+// header is the name of the header with the signature.
+func (s *Signature) header() string {
+	switch s.Scheme {
+	case SignatureSchemeGitHub:
+		return headerGitHubSignature
+	case SignatureSchemeStripe:
+		return headerStripeSignature
+	case SignatureSchemeHMACSHA256:
+		if s.Header != "" {
+			return s.Header
+		}
+	}
+
+	return defaultHMACHeader
+}
+
+func verifyStripe(key []byte, header string, body []byte, now time.Time) error {
+	var (
+		timestamp  string
+		signatures []string
+	)
+
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+
+		switch name {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if age := now.Sub(time.Unix(seconds, 0)); age > stripeTolerance || age < -stripeTolerance {
+		return fmt.Errorf("%w: timestamp outside the tolerance", ErrInvalidSignature)
+	}
+
+	signedPayload := append([]byte(timestamp+"."), body...)
+
+	for _, signature := range signatures {
+		if verifyHex(key, signedPayload, signature) == nil {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+func verifyHex(key, payload []byte, signature string) error {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(expected) == 0 {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+type (
+	ResponseMode    string
+	SignatureScheme string
+)
+
+const (
+	// ResponseModeAsync answers 202 right away and runs the flow in background.
+	// It is the default mode.
+	ResponseModeAsync ResponseMode = "async"
+	// ResponseModeSync waits for the flow and answers with its output.
+	ResponseModeSync ResponseMode = "sync"
+
+	// SignatureSchemeGitHub checks "X-Hub-Signature-256: sha256=<hmac of the body>".
+	SignatureSchemeGitHub SignatureScheme = "github"
+	// SignatureSchemeStripe checks "Stripe-Signature: t=<timestamp>,v1=<hmac of timestamp.body>".
+	SignatureSchemeStripe SignatureScheme = "stripe"
+	// SignatureSchemeHMACSHA256 checks the hex HMAC-SHA256 of the body in
+	// Signature.Header, with an optional "sha256=" prefix.
+	SignatureSchemeHMACSHA256 SignatureScheme = "hmac-sha256"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrInvalidWebhook   = errors.New("invalid webhook")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrSignatureUnavailable is returned for signed webhooks when the
+	// service has no secret resolver to read the signing key.
+	ErrSignatureUnavailable = errors.New("webhook signature verification unavailable")
+)
+
+type (
+	// Webhook exposes a flow on POST|GET|... /hooks/:id.
+	Webhook struct {
+		Id           string       `json:"id" bson:"_id"`
+		FlowId       string       `json:"flow_id" bson:"flow_id"`
+		Tenant       string       `json:"tenant" bson:"tenant"`
+		ResponseMode ResponseMode `json:"response_mode" bson:"response_mode"`
+		Signature    *Signature   `json:"signature,omitempty" bson:"signature,omitempty"`
+		Enabled      bool         `json:"enabled" bson:"enabled"`
+		// TriggerId is set when the webhook belongs to a trigger of the flow.
+		TriggerId string    `json:"trigger_id,omitempty" bson:"trigger_id,omitempty"`
+		CreatedAt time.Time `json:"created_at" bson:"created_at"`
+		UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+	}
+
+	// Signature configures the verification of signed requests. The signing
+	// key is read from the secret SecretName of the webhook tenant.
+	Signature struct {
+		Scheme     SignatureScheme `json:"scheme" bson:"scheme"`
+		SecretName string          `json:"secret_name" bson:"secret_name"`
+		Header     string          `json:"header,omitempty" bson:"header,omitempty"`
+	}
+
+	// Request is the inbound HTTP request, passed to the flow as eventRequestData.
+	Request struct {
+		Method  string
+		Headers map[string]string
+		Query   map[string]string
+		Body    any
+		RawBody []byte
+		header  http.Header
+	}
+
+	WebhookRepository interface {
+		// Save creates the webhook or replaces the one with the same id.
+		Save(ctx *yctx.Context, webhook *Webhook) (err error)
+		// GetById returns nil when the webhook does not exist.
+		GetById(ctx *yctx.Context, id string) (item *Webhook, err error)
+		GetByFlowId(ctx *yctx.Context, flowId string) (items []Webhook, err error)
+		// Delete returns ErrWebhookNotFound when the webhook does not exist.
+		Delete(ctx *yctx.Context, id string) (err error)
+	}
+
+	// FlowRunner is implemented by flowmanager.FlowExecutor.
+	FlowRunner interface {
+		Do(ctx *yctx.Context, flowId string, eventRequestData any) (response any, err error)
+	}
+
+	// SecretResolver is implemented by secretmanager.SecretService.
+	SecretResolver interface {
+		Resolve(ctx *yctx.Context, tenant, name string) (value string, err error)
+	}
+)
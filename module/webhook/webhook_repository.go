@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var _ WebhookRepository = (*InMemoryWebhookRepository)(nil)
+
+// InMemoryWebhookRepository implementa WebhookRepository usando memória
+type InMemoryWebhookRepository struct {
+	webhooks map[string]Webhook
+	mu       sync.RWMutex
+}
+
+// NewInMemoryWebhookRepository cria uma nova instância do repositório em memória
+func NewInMemoryWebhookRepository() *InMemoryWebhookRepository {
+	return &InMemoryWebhookRepository{
+		webhooks: make(map[string]Webhook),
+	}
+}
+
+func (r *InMemoryWebhookRepository) Save(ctx *yctx.Context, webhook *Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.webhooks[webhook.Id] = *webhook
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) GetById(ctx *yctx.Context, id string) (*Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, exists := r.webhooks[id]
+	if !exists {
+		return nil, nil
+	}
+
+	return &webhook, nil
+}
+
+func (r *InMemoryWebhookRepository) GetByFlowId(ctx *yctx.Context, flowId string) ([]Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]Webhook, 0)
+	for _, webhook := range r.webhooks {
+		if webhook.FlowId == flowId {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+
+	return webhooks, nil
+}
+
+func (r *InMemoryWebhookRepository) Delete(ctx *yctx.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.webhooks[id]; !exists {
+		return ErrWebhookNotFound
+	}
+
+	delete(r.webhooks, id)
+	return nil
+}
@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"golang.org/x/exp/slog"
+)
+
+type WebhookService struct {
+	webhookRepository WebhookRepository
+	flowRunner        FlowRunner
+	secretResolver    SecretResolver
+	now               func() time.Time
+}
+
+// NewWebhookService creates a WebhookService. secretResolver may be nil, in
+// which case webhooks with signature verification are rejected.
+func NewWebhookService(webhookRepository WebhookRepository, flowRunner FlowRunner, secretResolver SecretResolver) *WebhookService {
+	return &WebhookService{
+		webhookRepository: webhookRepository,
+		flowRunner:        flowRunner,
+		secretResolver:    secretResolver,
+		now:               time.Now,
+	}
+}
+
+// Create validates and saves a new webhook for the flow, in the tenant of the
+// context when it has one.
+func (s *WebhookService) Create(ctx *yctx.Context, webhook *Webhook) (err error) {
+	if err = webhook.validate(); err != nil {
+		return
+	}
+
+	// only the contexts that access every tenant choose the tenant.
+	if !ctx.HasAllTenants() {
+		webhook.Tenant = ctx.Tenant()
+	}
+
+	now := s.now().UTC()
+
+	webhook.Id = uuid.NewString()
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+
+	return s.webhookRepository.Save(ctx, webhook)
+}
+
+// Update validates and saves the changes of an existing webhook.
+func (s *WebhookService) Update(ctx *yctx.Context, webhook *Webhook) (err error) {
+	current, err := s.Get(ctx, webhook.Id)
+	if err != nil {
+		return
+	}
+
+	webhook.FlowId = current.FlowId
+	webhook.Tenant = current.Tenant
+
+	if err = webhook.validate(); err != nil {
+		return
+	}
+
+	webhook.CreatedAt = current.CreatedAt
+	webhook.UpdatedAt = s.now().UTC()
+
+	return s.webhookRepository.Save(ctx, webhook)
+}
+
+// Get returns ErrWebhookNotFound for the webhooks of other tenants.
+func (s *WebhookService) Get(ctx *yctx.Context, id string) (webhook *Webhook, err error) {
+	webhook, err = s.webhookRepository.GetById(ctx, id)
+	if err != nil {
+		return
+	}
+
+	if webhook == nil || !ctx.CanAccess(webhook.Tenant) {
+		return nil, ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+func (s *WebhookService) ListByFlow(ctx *yctx.Context, flowId string) (webhooks []Webhook, err error) {
+	items, err := s.webhookRepository.GetByFlowId(ctx, flowId)
+	if err != nil {
+		return
+	}
+
+	webhooks = make([]Webhook, 0, len(items))
+	for _, webhook := range items {
+		if ctx.CanAccess(webhook.Tenant) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	return webhooks, nil
+}
+
+func (s *WebhookService) Delete(ctx *yctx.Context, id string) (err error) {
+	if _, err = s.Get(ctx, id); err != nil {
+		return
+	}
+
+	return s.webhookRepository.Delete(ctx, id)
+}
+
+// Trigger verifies the request and runs the flow of the webhook. In async
+// mode the flow runs in background and the returned response is nil. The
+// webhooks are public, so the webhook is found in every tenant.
+func (s *WebhookService) Trigger(ctx *yctx.Context, id string, request *Request) (response any, mode ResponseMode, err error) {
+	webhook, err := s.Get(ctx.WithAllTenants(), id)
+	if err != nil {
+		return
+	}
+
+	if !webhook.Enabled {
+		return nil, "", ErrWebhookNotFound
+	}
+
+	// the signing secret is resolved and the flow runs in the tenant of the
+	// webhook, even for anonymous calls.
+	ctx = ctx.WithTenant(webhook.Tenant)
+
+	if err = s.verify(ctx, webhook, request); err != nil {
+		return
+	}
+
+	if webhook.ResponseMode == ResponseModeSync {
+		response, err = s.flowRunner.Do(ctx, webhook.FlowId, request.eventRequestData(webhook.Signature))
+		return response, ResponseModeSync, err
+	}
+
+	go func(ctx *yctx.Context) {
+		if _, err := s.flowRunner.Do(ctx, webhook.FlowId, request.eventRequestData(webhook.Signature)); err != nil {
+			slog.Error("webhook flow execution failed",
+				slog.String("webhook_id", webhook.Id),
+				slog.String("flow_id", webhook.FlowId),
+				slog.Any("error", err))
+		}
+	}(yctx.NewContext(context.WithoutCancel(ctx.Context())))
+
+	return nil, ResponseModeAsync, nil
+}
+
+func (s *WebhookService) verify(ctx *yctx.Context, webhook *Webhook, request *Request) error {
+	if webhook.Signature == nil {
+		return nil
+	}
+
+	if s.secretResolver == nil {
+		return fmt.Errorf("%w: webhook %s requires a signing secret but secrets are disabled", ErrSignatureUnavailable, webhook.Id)
+	}
+
+	key, err := s.secretResolver.Resolve(ctx, webhook.Tenant, webhook.Signature.SecretName)
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook signing secret: %w", err)
+	}
+
+	return webhook.Signature.verify([]byte(key), request.header, request.RawBody, s.now())
+}
+
+// Validate returns ErrInvalidWebhook when the mode is unknown. An empty mode
+// means ResponseModeAsync.
+func (m ResponseMode) Validate() error {
+	switch m {
+	case "", ResponseModeAsync, ResponseModeSync:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown response mode %q", ErrInvalidWebhook, m)
+	}
+}
+
+func (w *Webhook) validate() error {
+	if w.FlowId == "" {
+		return fmt.Errorf("%w: flow id is required", ErrInvalidWebhook)
+	}
+
+	if err := w.ResponseMode.Validate(); err != nil {
+		return err
+	}
+
+	if w.ResponseMode == "" {
+		w.ResponseMode = ResponseModeAsync
+	}
+
+	if w.Signature != nil {
+		return w.Signature.Validate()
+	}
+
+	return nil
+}
@@ -0,0 +1,282 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/module/secretmanager"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestWebhookService(t *testing.T) {
+	suite.Run(t, new(WebhookServiceTestSuite))
+}
+
+type flowRunnerStub struct {
+	events chan any
+	tenant string
+}
+
+func (f *flowRunnerStub) Do(ctx *yctx.Context, flowId string, eventRequestData any) (any, error) {
+	f.tenant = ctx.Tenant()
+	f.events <- eventRequestData
+	return map[string]any{"flow_id": flowId}, nil
+}
+
+type secretResolverStub map[string]string
+
+func (s secretResolverStub) Resolve(ctx *yctx.Context, tenant, name string) (string, error) {
+	value, ok := s[tenant+"/"+name]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+
+	return value, nil
+}
+
+type WebhookServiceTestSuite struct {
+	suite.Suite
+	ctx            *yctx.Context
+	flowRunner     *flowRunnerStub
+	webhookService *WebhookService
+	now            time.Time
+}
+
+func (s *WebhookServiceTestSuite) SetupTest() {
+	s.ctx = yctx.NewContext(context.Background())
+	s.flowRunner = &flowRunnerStub{events: make(chan any, 1)}
+	s.now = time.Unix(1700000000, 0)
+	s.webhookService = NewWebhookService(
+		NewInMemoryWebhookRepository(),
+		s.flowRunner,
+		secretResolverStub{"tenant-a/signing-key": "whsec"},
+	)
+	s.webhookService.now = func() time.Time { return s.now }
+}
+
+func (s *WebhookServiceTestSuite) create(webhook *Webhook) *Webhook {
+	webhook.FlowId = "flow-1"
+	webhook.Enabled = true
+	s.Require().NoError(s.webhookService.Create(s.ctx.WithTenant("tenant-a"), webhook))
+
+	return webhook
+}
+
+func (s *WebhookServiceTestSuite) newRequest(body string, headers map[string]string) *Request {
+	httpRequest := httptest.NewRequest(http.MethodPost, "/hooks/id?ref=main&tag=a&tag=b", strings.NewReader(body))
+	for name, value := range headers {
+		httpRequest.Header.Set(name, value)
+	}
+
+	request, err := NewRequest(httpRequest)
+	s.Require().NoError(err)
+
+	return request
+}
+
+func sign(key, payload string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(payload))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *WebhookServiceTestSuite) TestTrigger_SyncReturnsFlowOutput() {
+	webhook := s.create(&Webhook{ResponseMode: ResponseModeSync})
+
+	response, mode, err := s.webhookService.Trigger(s.ctx, webhook.Id, s.newRequest(`{"action": "opened"}`, map[string]string{
+		"Content-Type": "application/json",
+	}))
+	s.NoError(err)
+	s.Equal(ResponseModeSync, mode)
+	s.Equal(map[string]any{"flow_id": "flow-1"}, response)
+
+	event := (<-s.flowRunner.events).(map[string]any)
+	s.Equal(http.MethodPost, event["method"])
+	s.Equal(map[string]any{"action": "opened"}, event["body"])
+	s.Equal(map[string]string{"ref": "main", "tag": "a,b"}, event["query"])
+	s.Equal("application/json", event["headers"].(map[string]string)["Content-Type"])
+}
+
+func (s *WebhookServiceTestSuite) TestTrigger_AsyncRunsInBackground() {
+	webhook := s.create(&Webhook{})
+	s.Equal(ResponseModeAsync, webhook.ResponseMode)
+
+	response, mode, err := s.webhookService.Trigger(s.ctx, webhook.Id, s.newRequest("plain text", nil))
+	s.NoError(err)
+	s.Equal(ResponseModeAsync, mode)
+	s.Nil(response)
+
+	select {
+	case event := <-s.flowRunner.events:
+		s.Equal("plain text", event.(map[string]any)["body"])
+	case <-time.After(time.Second):
+		s.Fail("flow was not executed")
+	}
+}
+
+func (s *WebhookServiceTestSuite) TestTrigger_GitHubSignature() {
+	webhook := s.create(&Webhook{
+		ResponseMode: ResponseModeSync,
+		Signature:    &Signature{Scheme: SignatureSchemeGitHub, SecretName: "signing-key"},
+	})
+
+	body := `{"zen": "Keep it simple"}`
+
+	_, _, err := s.webhookService.Trigger(s.ctx, webhook.Id, s.newRequest(body, map[string]string{
+		"X-Hub-Signature-256": "sha256=" + sign("wrong", body),
+	}))
+	s.ErrorIs(err, ErrInvalidSignature)
+
+	_, _, err = s.webhookService.Trigger(s.ctx, webhook.Id, s.newRequest(body, nil))
+	s.ErrorIs(err, ErrInvalidSignature)
+
+	_, _, err = s.webhookService.Trigger(s.ctx, webhook.Id, s.newRequest(body, map[string]string{
+		"X-Hub-Signature-256": "sha256=" + sign("whsec", body),
+		"X-Github-Event":      "ping",
+	}))
+	s.NoError(err)
+
+	headers := (<-s.flowRunner.events).(map[string]any)["headers"].(map[string]string)
+	s.NotContains(headers, "X-Hub-Signature-256")
+	s.Equal("ping", headers["X-Github-Event"])
+}
+
+func (s *WebhookServiceTestSuite) TestTrigger_ResolvesSecretOfWebhookTenant() {
+	cipher, err := secretmanager.NewCipher(make([]byte, 32))
+	s.Require().NoError(err)
+
+	secretService := secretmanager.NewSecretService(secretmanager.NewInMemorySecretRepository(), cipher)
+	_, err = secretService.Put(s.ctx.WithTenant("tenant-a"), "tenant-a", "signing-key", "whsec-tenant-a")
+	s.Require().NoError(err)
+
+	s.webhookService = NewWebhookService(NewInMemoryWebhookRepository(), s.flowRunner, secretService)
+
+	webhook := s.create(&Webhook{
+		ResponseMode: ResponseModeSync,
+		Signature:    &Signature{Scheme: SignatureSchemeGitHub, SecretName: "signing-key"},
+	})
+
+	// the public endpoint has no tenant, the secret is read in the webhook tenant.
+	body := `{"zen": "Keep it simple"}`
+	_, _, err = s.webhookService.Trigger(s.ctx, webhook.Id, s.newRequest(body, map[string]string{
+		"X-Hub-Signature-256": "sha256=" + sign("whsec-tenant-a", body),
+	}))
+	s.Require().NoError(err)
+	<-s.flowRunner.events
+	s.Equal("tenant-a", s.flowRunner.tenant)
+}
+
+func (s *WebhookServiceTestSuite) TestTrigger_StripeSignature() {
+	webhook := s.create(&Webhook{
+		ResponseMode: ResponseModeSync,
+		Signature:    &Signature{Scheme: SignatureSchemeStripe, SecretName: "signing-key"},
+	})
+
+	var (
+		body      = `{"type": "charge.succeeded"}`
+		timestamp = fmt.Sprint(s.now.Unix())
+		header    = fmt.Sprintf("t=%s,v1=%s,v0=ignored", timestamp, sign("whsec", timestamp+"."+body))
+	)
+
+	_, _, err := s.webhookService.Trigger(s.ctx, webhook.Id, s.newRequest(body, map[string]string{"Stripe-Signature": header}))
+	s.NoError(err)
+
+	s.now = s.now.Add(10 * time.Minute)
+
+	_, _, err = s.webhookService.Trigger(s.ctx, webhook.Id, s.newRequest(body, map[string]string{"Stripe-Signature": header}))
+	s.ErrorIs(err, ErrInvalidSignature)
+}
+
+func (s *WebhookServiceTestSuite) TestTrigger_HMACSignatureWithCustomHeader() {
+	webhook := s.create(&Webhook{
+		ResponseMode: ResponseModeSync,
+		Signature:    &Signature{Scheme: SignatureSchemeHMACSHA256, SecretName: "signing-key", Header: "X-Acme-Signature"},
+	})
+
+	_, _, err := s.webhookService.Trigger(s.ctx, webhook.Id, s.newRequest("payload", map[string]string{
+		"X-Acme-Signature": sign("whsec", "payload"),
+	}))
+	s.NoError(err)
+	s.NotContains((<-s.flowRunner.events).(map[string]any)["headers"], "X-Acme-Signature")
+}
+
+func (s *WebhookServiceTestSuite) TestTrigger_SignatureWithoutSecretResolver() {
+	s.webhookService.secretResolver = nil
+
+	webhook := s.create(&Webhook{
+		ResponseMode: ResponseModeSync,
+		Signature:    &Signature{Scheme: SignatureSchemeGitHub, SecretName: "signing-key"},
+	})
+
+	_, _, err := s.webhookService.Trigger(s.ctx, webhook.Id, s.newRequest("payload", map[string]string{
+		"X-Hub-Signature-256": "sha256=" + sign("whsec", "payload"),
+	}))
+	s.ErrorIs(err, ErrSignatureUnavailable)
+}
+
+func (s *WebhookServiceTestSuite) TestTrigger_DisabledOrMissingWebhook() {
+	webhook := &Webhook{FlowId: "flow-1"}
+	s.NoError(s.webhookService.Create(s.ctx, webhook))
+
+	_, _, err := s.webhookService.Trigger(s.ctx, webhook.Id, s.newRequest("", nil))
+	s.ErrorIs(err, ErrWebhookNotFound)
+
+	_, _, err = s.webhookService.Trigger(s.ctx, "missing", s.newRequest("", nil))
+	s.ErrorIs(err, ErrWebhookNotFound)
+}
+
+func (s *WebhookServiceTestSuite) TestCreate_RejectsInvalidWebhooks() {
+	for _, webhook := range []*Webhook{
+		{},
+		{FlowId: "flow-1", ResponseMode: "later"},
+		{FlowId: "flow-1", Signature: &Signature{Scheme: "md5", SecretName: "key"}},
+		{FlowId: "flow-1", Signature: &Signature{Scheme: SignatureSchemeGitHub}},
+	} {
+		s.ErrorIs(s.webhookService.Create(s.ctx, webhook), ErrInvalidWebhook)
+	}
+}
+
+func (s *WebhookServiceTestSuite) TestNewRequest_Errors() {
+	_, err := NewRequest(httptest.NewRequest(http.MethodPost, "/hooks/id", strings.NewReader(strings.Repeat("a", MaxBodySize+1))))
+	s.ErrorIs(err, ErrBodyTooLarge)
+
+	httpRequest := httptest.NewRequest(http.MethodPost, "/hooks/id", strings.NewReader("{"))
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	_, err = NewRequest(httpRequest)
+	s.ErrorIs(err, ErrInvalidWebhook)
+}
+
+func (s *WebhookServiceTestSuite) TestContextTenant_ScopesWebhooks() {
+	ctxA, ctxB := s.ctx.WithTenant("tenant-a"), s.ctx.WithTenant("tenant-b")
+
+	webhook := &Webhook{FlowId: "flow-1", ResponseMode: ResponseModeSync, Enabled: true}
+	s.NoError(s.webhookService.Create(ctxA, webhook))
+	s.Equal("tenant-a", webhook.Tenant)
+
+	_, err := s.webhookService.Get(ctxB, webhook.Id)
+	s.ErrorIs(err, ErrWebhookNotFound)
+
+	webhooks, err := s.webhookService.ListByFlow(ctxB, "flow-1")
+	s.NoError(err)
+	s.Empty(webhooks)
+
+	s.ErrorIs(s.webhookService.Delete(ctxB, webhook.Id), ErrWebhookNotFound)
+
+	// the public endpoint has no tenant, the flow runs in the webhook tenant.
+	_, _, err = s.webhookService.Trigger(s.ctx, webhook.Id, s.newRequest(`{}`, nil))
+	s.NoError(err)
+	<-s.flowRunner.events
+	s.Equal("tenant-a", s.flowRunner.tenant)
+}
@@ -0,0 +1,86 @@
+package plugincore
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const maxCacheEntries = 1024
+
+var (
+	schemaCache   = newContentCache[*gojsonschema.Schema](maxCacheEntries)
+	templateCache = newContentCache[compiledTemplate](maxCacheEntries)
+)
+
+// contentCache stores compiled values keyed by the sha256 of their source.
+// When full, an arbitrary entry is evicted to make room for the new one.
+type contentCache[V any] struct {
+	mu         sync.RWMutex
+	items      map[[sha256.Size]byte]V
+	maxEntries int
+}
+
+func newContentCache[V any](maxEntries int) *contentCache[V] {
+	return &contentCache[V]{
+		items:      make(map[[sha256.Size]byte]V),
+		maxEntries: maxEntries,
+	}
+}
+
+func (c *contentCache[V]) getOrCompile(key [sha256.Size]byte, compile func() (V, error)) (value V, err error) {
+	var ok bool
+
+	c.mu.RLock()
+	value, ok = c.items[key]
+	c.mu.RUnlock()
+
+	if ok {
+		return value, nil
+	}
+
+	value, err = compile()
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.items) >= c.maxEntries {
+		for evicted := range c.items {
+			delete(c.items, evicted)
+			break
+		}
+	}
+
+	c.items[key] = value
+
+	return value, nil
+}
+
+func (c *contentCache[V]) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[[sha256.Size]byte]V)
+}
+
+// compileSchema returns the compiled JSON schema, parsing it only the first
+// time a schema with the same content is seen.
+func compileSchema(schema []byte) (*gojsonschema.Schema, error) {
+	return schemaCache.getOrCompile(sha256.Sum256(schema), func() (*gojsonschema.Schema, error) {
+		return gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schema))
+	})
+}
+
+// cachedTemplate returns the compiled schema input template, parsing it only
+// the first time the same content is seen with the same mode.
+func cachedTemplate(mode TemplateMode, schemaInputs string) (compiledTemplate, error) {
+	key := sha256.Sum256([]byte(string(mode) + "\x00" + schemaInputs))
+
+	return templateCache.getOrCompile(key, func() (compiledTemplate, error) {
+		return compileTemplate(mode, schemaInputs)
+	})
+}
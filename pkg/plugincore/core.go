@@ -1,24 +1,20 @@
 package plugincore
 
 import (
-	"bytes"
 	"encoding/json"
-	"fmt"
 	"github.com/xeipuuv/gojsonschema"
-	"log/slog"
-	"text/template"
+	"github.com/yrn-go/yrn/pkg/yctx"
 )
 
-func ValidateAndGetRequestBody[T any](schema []byte, schemaInputs string, previousPluginResponse any, responseSharedForAll map[string]any) (requestBody *T, err error) {
+func ValidateAndGetRequestBody[T any](ctx *yctx.Context, schema []byte, schemaInputs string, previousPluginResponse any, responseSharedForAll map[string]any) (requestBody *T, err error) {
 	var (
-		tmpl           *template.Template
-		templateResult = &bytes.Buffer{}
+		tmpl           compiledTemplate
+		templateResult []byte
+		secrets        = newSecretScope(ctx)
 		requestData    T
 	)
 
-	tmpl, err = template.
-		New("plugin_slug").
-		Parse(schemaInputs)
+	tmpl, err = cachedTemplate(TemplateModeFromContext(ctx), schemaInputs)
 	if err != nil {
 		return
 	}
@@ -28,15 +24,17 @@ func ValidateAndGetRequestBody[T any](schema []byte, schemaInputs string, previo
 		"sharedForAll": responseSharedForAll,
 	}
 
-	if err = tmpl.Execute(templateResult, templateData); err != nil {
+	templateResult, err = tmpl.render(templateData, secrets.funcs())
+	secrets.report()
+	if err != nil {
 		return
 	}
 
-	if err = validate(schema, templateResult.Bytes()); err != nil {
-		return
+	if err = validate(schema, templateResult); err != nil {
+		return nil, secrets.hide(err)
 	}
 
-	if err = json.Unmarshal(templateResult.Bytes(), &requestData); err != nil {
+	if err = json.Unmarshal(templateResult, &requestData); err != nil {
 		return
 	}
 
@@ -45,26 +43,22 @@ func ValidateAndGetRequestBody[T any](schema []byte, schemaInputs string, previo
 
 func validate(schema []byte, schemaInputs []byte) (err error) {
 	var (
-		schemaLoader       = gojsonschema.NewBytesLoader(schema)
-		schemaInputsLoader = gojsonschema.NewBytesLoader(schemaInputs)
-		result             *gojsonschema.Result
+		compiled *gojsonschema.Schema
+		result   *gojsonschema.Result
 	)
 
-	result, err = gojsonschema.Validate(schemaLoader, schemaInputsLoader)
+	compiled, err = compileSchema(schema)
 	if err != nil {
 		return
 	}
 
-	if !result.Valid() {
-		var errs []any
-
-		for _, desc := range result.Errors() {
-			errs = append(errs, slog.Any(desc.Field(), desc.Description()))
-		}
-
-		slog.Error("error validate", errs...)
+	result, err = compiled.Validate(gojsonschema.NewBytesLoader(schemaInputs))
+	if err != nil {
+		return
+	}
 
-		return fmt.Errorf("validation failed: %v", errs)
+	if !result.Valid() {
+		return newValidationError(result)
 	}
 
 	return
@@ -0,0 +1,71 @@
+package plugincore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+var (
+	benchmarkSchema = []byte(`{
+		"type": "object",
+		"properties": {
+			"request": {
+				"type": "object",
+				"properties": {
+					"method": {"type": "string", "enum": ["GET", "POST"]},
+					"url": {"type": "string", "format": "uri"},
+					"headers": {"type": "object", "additionalProperties": {"type": "string"}},
+					"body": {"type": ["string", "object", "array", "null"]}
+				},
+				"required": ["method", "url"]
+			}
+		},
+		"required": ["request"]
+	}`)
+	benchmarkSchemaInputs = `{
+		"request": {
+			"method": "POST",
+			"url": "https://api.example.com/users",
+			"headers": {"Authorization": "Bearer {{ .data.token }}"},
+			"body": {"name": "{{ upper .data.name }}", "email": "{{ .sharedForAll.profile.email }}"}
+		}
+	}`
+	benchmarkData   = map[string]any{"token": "token", "name": "john"}
+	benchmarkShared = map[string]any{"profile": map[string]any{"email": "john@yrn.com"}}
+)
+
+func benchmarkValidateAndGetRequestBody(b *testing.B, mode TemplateMode, cached bool) {
+	ctx := WithTemplateMode(yctx.NewContext(context.Background()), mode)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if !cached {
+			schemaCache.reset()
+			templateCache.reset()
+		}
+
+		if _, err := ValidateAndGetRequestBody[map[string]any](ctx, benchmarkSchema, benchmarkSchemaInputs, benchmarkData, benchmarkShared); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateAndGetRequestBody_TextCached(b *testing.B) {
+	benchmarkValidateAndGetRequestBody(b, TemplateModeText, true)
+}
+
+func BenchmarkValidateAndGetRequestBody_TextUncached(b *testing.B) {
+	benchmarkValidateAndGetRequestBody(b, TemplateModeText, false)
+}
+
+func BenchmarkValidateAndGetRequestBody_JSONCached(b *testing.B) {
+	benchmarkValidateAndGetRequestBody(b, TemplateModeJSON, true)
+}
+
+func BenchmarkValidateAndGetRequestBody_JSONUncached(b *testing.B) {
+	benchmarkValidateAndGetRequestBody(b, TemplateModeJSON, false)
+}
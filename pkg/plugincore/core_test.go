@@ -0,0 +1,352 @@
+package plugincore
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+func TestCore(t *testing.T) {
+	suite.Run(t, new(CoreTestSuite))
+}
+
+type CoreTestSuite struct {
+	suite.Suite
+	ctx *yctx.Context
+}
+
+func (suite *CoreTestSuite) SetupTest() {
+	suite.ctx = yctx.NewContext(context.Background())
+}
+
+var testSchema = []byte(`{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"payload": {"type": "string"},
+		"total": {"type": "number"}
+	},
+	"required": ["name"]
+}`)
+
+type testRequest struct {
+	Name    string  `json:"name"`
+	Payload string  `json:"payload"`
+	Total   float64 `json:"total"`
+}
+
+func (suite *CoreTestSuite) TestValidateAndGetRequestBody_WithFunctions() {
+	var (
+		previousPluginResponse = map[string]any{
+			"user":  map[string]any{"name": "john"},
+			"price": 10.5,
+			"qty":   2,
+		}
+		schemaInputs = `{
+			"name": "{{ upper .data.user.name }}",
+			"payload": "{{ b64enc (toJson .data.user) }}",
+			"total": {{ mul .data.price .data.qty }}
+		}`
+	)
+
+	request, err := ValidateAndGetRequestBody[testRequest](suite.ctx, testSchema, schemaInputs, previousPluginResponse, nil)
+	suite.NoError(err)
+	suite.Equal("JOHN", request.Name)
+	suite.Equal("eyJuYW1lIjoiam9obiJ9", request.Payload)
+	suite.Equal(21.0, request.Total)
+}
+
+func (suite *CoreTestSuite) TestValidateAndGetRequestBody_MissingKeyIsError() {
+	_, err := ValidateAndGetRequestBody[testRequest](suite.ctx, testSchema, `{"name": "{{ .data.missing }}"}`, map[string]any{}, nil)
+	suite.ErrorContains(err, "missing")
+}
+
+func (suite *CoreTestSuite) TestValidateAndGetRequestBody_DefaultAndRequired() {
+	request, err := ValidateAndGetRequestBody[testRequest](
+		suite.ctx,
+		testSchema,
+		`{"name": "{{ default "anonymous" (jsonPath .data "user.name") }}"}`,
+		map[string]any{},
+		nil,
+	)
+	suite.NoError(err)
+	suite.Equal("anonymous", request.Name)
+
+	_, err = ValidateAndGetRequestBody[testRequest](
+		suite.ctx,
+		testSchema,
+		`{"name": "{{ required "user name is required" (jsonPath .data "user.name") }}"}`,
+		map[string]any{},
+		nil,
+	)
+	suite.ErrorContains(err, "user name is required")
+}
+
+func (suite *CoreTestSuite) TestValidateAndGetRequestBody_TextModeBreaksOnQuotes() {
+	_, err := ValidateAndGetRequestBody[testRequest](
+		suite.ctx,
+		testSchema,
+		`{"name": "{{ .data.name }}"}`,
+		map[string]any{"name": "line\nbreak"},
+		nil,
+	)
+	suite.Error(err)
+}
+
+func (suite *CoreTestSuite) TestValidateAndGetRequestBody_JSONModeEscapesData() {
+	var (
+		ctx    = WithTemplateMode(suite.ctx, TemplateModeJSON)
+		schema = []byte(`{"type": "object", "properties": {"name": {"type": "string"}}, "additionalProperties": false}`)
+	)
+
+	request, err := ValidateAndGetRequestBody[map[string]any](
+		ctx,
+		schema,
+		`{"name": "Hello {{ .data.name }}"}`,
+		map[string]any{"name": "john\", \"admin\": true, \"x\": \"\nbreak"},
+		nil,
+	)
+	suite.NoError(err)
+	suite.Equal(map[string]any{"name": "Hello john\", \"admin\": true, \"x\": \"\nbreak"}, *request)
+}
+
+func (suite *CoreTestSuite) TestValidateAndGetRequestBody_JSONModeTypedSubstitution() {
+	var (
+		ctx          = WithTemplateMode(suite.ctx, TemplateModeJSON)
+		schema       = []byte(`{"type": "object"}`)
+		schemaInputs = `{
+			"items": "{{ .data.items }}",
+			"count": "{{ .data.count }}",
+			"label": "{{ .data.count }} items",
+			"user": "{{ .sharedForAll.auth.user }}",
+			"fixed": 10,
+			"{{ .data.key }}": ["{{ upper .data.key }}", true]
+		}`
+		previousPluginResponse = map[string]any{
+			"items": []any{"a", "b"},
+			"count": 2,
+			"key":   "dynamic",
+		}
+		responseSharedForAll = map[string]any{
+			"auth": map[string]any{"user": map[string]any{"id": "u1"}},
+		}
+	)
+
+	request, err := ValidateAndGetRequestBody[map[string]any](ctx, schema, schemaInputs, previousPluginResponse, responseSharedForAll)
+	suite.NoError(err)
+	suite.Equal([]any{"a", "b"}, (*request)["items"])
+	suite.Equal(float64(2), (*request)["count"])
+	suite.Equal("2 items", (*request)["label"])
+	suite.Equal(map[string]any{"id": "u1"}, (*request)["user"])
+	suite.Equal(float64(10), (*request)["fixed"])
+	suite.Equal([]any{"DYNAMIC", true}, (*request)["dynamic"])
+}
+
+func (suite *CoreTestSuite) TestValidateAndGetRequestBody_JSONModeRequiresJSON() {
+	_, err := ValidateAndGetRequestBody[map[string]any](
+		WithTemplateMode(suite.ctx, TemplateModeJSON),
+		[]byte(`{"type": "object"}`),
+		`{"count": {{ .data.count }}}`,
+		map[string]any{"count": 1},
+		nil,
+	)
+	suite.Error(err)
+
+	_, err = ValidateAndGetRequestBody[map[string]any](
+		WithTemplateMode(suite.ctx, "xml"),
+		[]byte(`{"type": "object"}`),
+		`{}`,
+		nil,
+		nil,
+	)
+	suite.ErrorContains(err, "unknown template mode")
+}
+
+func (suite *CoreTestSuite) TestValidateAndGetRequestBody_ValidationError() {
+	_, err := ValidateAndGetRequestBody[testRequest](suite.ctx, testSchema, `{"total": "ten"}`, nil, nil)
+
+	var validationErr *ValidationError
+	suite.ErrorAs(err, &validationErr)
+	suite.ElementsMatch([]FieldError{
+		{Field: "name", Rule: "required", Message: "name is required"},
+		{Field: "total", Rule: "invalid_type", Message: "Invalid type. Expected: number, given: string"},
+	}, validationErr.Errors)
+}
+
+func (suite *CoreTestSuite) TestValidateAndGetRequestBody_ResolvesSecrets() {
+	var (
+		resolved []string
+		resolver = func(ctx *yctx.Context, name string) (string, error) {
+			resolved = append(resolved, name)
+			return `p"ss` + "\n", nil
+		}
+	)
+
+	for mode, schemaInputs := range map[TemplateMode]string{
+		TemplateModeText: `{"name": "{{ secret "db-password" }}", "payload": "key={{ secret "db-password" }}"}`,
+		TemplateModeJSON: `{"name": "{{ secret \"db-password\" }}", "payload": "key={{ secret \"db-password\" }}"}`,
+	} {
+		resolved = nil
+		ctx := WithSecretResolver(WithTemplateMode(suite.ctx, mode), resolver)
+
+		request, err := ValidateAndGetRequestBody[testRequest](ctx, testSchema, schemaInputs, nil, nil)
+		suite.NoError(err, mode)
+		suite.Equal(`p"ss`+"\n", request.Name, mode)
+		suite.Equal(`key=p"ss`+"\n", request.Payload, mode)
+		suite.Equal([]string{"db-password"}, resolved, mode)
+	}
+}
+
+func (suite *CoreTestSuite) TestValidateAndGetRequestBody_SecretErrors() {
+	_, err := ValidateAndGetRequestBody[testRequest](suite.ctx, testSchema, `{"name": "{{ secret "token" }}"}`, nil, nil)
+	suite.ErrorIs(err, ErrSecretResolverMissing)
+
+	ctx := WithSecretResolver(suite.ctx, func(ctx *yctx.Context, name string) (string, error) {
+		return "", errors.New("not found")
+	})
+	_, err = ValidateAndGetRequestBody[testRequest](ctx, testSchema, `{"name": "{{ secret "token" }}"}`, nil, nil)
+	suite.ErrorContains(err, `failed to resolve secret "token"`)
+
+	_, err = ValidateAndGetRequestBody[testRequest](ctx, testSchema, `{"name": "{{ secret "bad name" }}"}`, nil, nil)
+	suite.ErrorContains(err, "invalid secret name")
+}
+
+func (suite *CoreTestSuite) TestValidateAndGetRequestBody_SecretsInPipelines() {
+	var (
+		sensitive []string
+		ctx       = WithSensitiveValuesHandler(
+			WithSecretResolver(suite.ctx, func(ctx *yctx.Context, name string) (string, error) {
+				return map[string]string{"user": "admin", "password": `p"ss`}[name], nil
+			}),
+			func(values ...string) { sensitive = append(sensitive, values...) },
+		)
+	)
+
+	for mode, schemaInputs := range map[TemplateMode]string{
+		TemplateModeText: `{"name": "{{ printf "%s:%s" (secret "user") (secret "password") | b64enc }}", "payload": "{{ if true }}{{ secret "password" }}{{ end }}"}`,
+		TemplateModeJSON: `{"name": "{{ printf \"%s:%s\" (secret \"user\") (secret \"password\") | b64enc }}", "payload": "{{ secret \"password\" | upper | lower }}"}`,
+	} {
+		sensitive = nil
+
+		request, err := ValidateAndGetRequestBody[testRequest](WithTemplateMode(ctx, mode), testSchema, schemaInputs, nil, nil)
+		suite.NoError(err, mode)
+		suite.Equal(base64.StdEncoding.EncodeToString([]byte(`admin:p"ss`)), request.Name, mode)
+		suite.Equal(`p"ss`, request.Payload, mode)
+		suite.Contains(sensitive, `admin:p"ss`, mode)
+		suite.Contains(sensitive, request.Name, mode)
+	}
+}
+
+func (suite *CoreTestSuite) TestValidateAndGetRequestBody_DataIsNotASecret() {
+	ctx := WithSecretResolver(suite.ctx, func(ctx *yctx.Context, name string) (string, error) {
+		return "leaked", nil
+	})
+
+	request, err := ValidateAndGetRequestBody[testRequest](
+		ctx,
+		testSchema,
+		`{"name": "{{ .data.name }}"}`,
+		map[string]any{"name": "{{ secret `token` }}"},
+		nil,
+	)
+	suite.NoError(err)
+	suite.Equal("{{ secret `token` }}", request.Name)
+}
+
+func (suite *CoreTestSuite) TestHideSecrets() {
+	err := hideSecrets(&ValidationError{Errors: []FieldError{
+		{Field: "name", Rule: "enum", Message: "super-secret is not allowed"},
+	}}, []secretValue{{name: "token", value: "super-secret"}})
+
+	suite.EqualError(err, "validation failed: name: [secret token] is not allowed")
+}
+
+func (suite *CoreTestSuite) TestCache_ReusedByContent() {
+	first, err := cachedTemplate(TemplateModeText, `{"name": "{{ .data.name }}"}`)
+	suite.NoError(err)
+
+	second, err := cachedTemplate(TemplateModeText, `{"name": "{{ .data.name }}"}`)
+	suite.NoError(err)
+
+	jsonMode, err := cachedTemplate(TemplateModeJSON, `{"name": "{{ .data.name }}"}`)
+	suite.NoError(err)
+
+	suite.Same(first, second)
+	suite.NotEqual(first, jsonMode)
+
+	firstSchema, err := compileSchema(testSchema)
+	suite.NoError(err)
+
+	secondSchema, err := compileSchema(append([]byte(nil), testSchema...))
+	suite.NoError(err)
+
+	suite.Same(firstSchema, secondSchema)
+
+	cache := newContentCache[int](2)
+	for i := 0; i < 5; i++ {
+		_, _ = cache.getOrCompile([32]byte{byte(i)}, func() (int, error) { return i, nil })
+	}
+
+	suite.Len(cache.items, 2)
+}
+
+func (suite *CoreTestSuite) TestSchemaHasPath() {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"files": {"type": "array", "items": {"type": "object", "properties": {"id": {"type": "string"}}}},
+			"meta": {"type": "object"},
+			"labels": {"type": "object", "properties": {}, "additionalProperties": {"type": "string"}},
+			"closed": {"type": "object", "properties": {"id": {"type": "string"}}, "additionalProperties": false}
+		}
+	}`)
+
+	for path, expected := range map[string]bool{
+		"files":         true,
+		"files.0.id":    true,
+		"files.0.name":  true,
+		"files.id":      false,
+		"meta.anything": true,
+		"labels.env":    true,
+		"closed.id":     true,
+		"closed.name":   false,
+		"missing":       true,
+	} {
+		hasPath, err := SchemaHasPath(schema, strings.Split(path, "."))
+		suite.NoError(err)
+		suite.Equal(expected, hasPath, path)
+	}
+
+	suite.Error(ValidateOutput(schema, map[string]any{"files": "not an array"}))
+	suite.NoError(ValidateOutput(schema, map[string]any{"files": []any{}}))
+	suite.NoError(ValidateOutput(schema, map[string]any{"files": []any{map[string]any{"id": "1", "extra": true}}, "extra": 1}))
+	suite.Error(ValidateOutput(schema, map[string]any{"closed": map[string]any{"name": "extra"}}))
+}
+
+func (suite *CoreTestSuite) TestFuncMap() {
+	funcs := FuncMap()
+
+	formatted, err := funcs["formatTime"].(func(string, any) (string, error))("2006-01-02", "2025-03-04T10:00:00Z")
+	suite.NoError(err)
+	suite.Equal("2025-03-04", formatted)
+
+	decoded, err := funcs["b64dec"].(func(string) (string, error))("eXJu")
+	suite.NoError(err)
+	suite.Equal("yrn", decoded)
+
+	_, err = funcs["div"].(func(any, any) (float64, error))(1, 0)
+	suite.Error(err)
+
+	id, err := funcs["uuid"].(func() (string, error))()
+	suite.NoError(err)
+	suite.Regexp(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+
+	value, ok := LookupPath(map[string]any{"items": []any{map[string]any{"id": "a"}}}, "$.items.0.id")
+	suite.True(ok)
+	suite.Equal("a", value)
+}
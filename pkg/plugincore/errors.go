@@ -0,0 +1,73 @@
+package plugincore
+
+import (
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+type (
+	// FieldError describes one rule of the plugin schema that the rendered
+	// schema input does not satisfy.
+	FieldError struct {
+		Field   string `json:"field"`
+		Rule    string `json:"rule"`
+		Message string `json:"message"`
+	}
+
+	// ValidationError is returned when the rendered schema input does not
+	// match the plugin schema.
+	ValidationError struct {
+		Errors []FieldError `json:"errors"`
+	}
+)
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+
+	for _, fieldError := range e.Errors {
+		if fieldError.Field == "" {
+			messages = append(messages, fieldError.Message)
+			continue
+		}
+
+		messages = append(messages, fieldError.Field+": "+fieldError.Message)
+	}
+
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+func newValidationError(result *gojsonschema.Result) *ValidationError {
+	validationError := &ValidationError{
+		Errors: make([]FieldError, 0, len(result.Errors())),
+	}
+
+	for _, desc := range result.Errors() {
+		validationError.Errors = append(validationError.Errors, FieldError{
+			Field:   fieldPath(desc),
+			Rule:    desc.Type(),
+			Message: desc.Description(),
+		})
+	}
+
+	return validationError
+}
+
+// fieldPath returns the dot separated path of the failing field, pointing to
+// the missing property itself for "required" errors.
+func fieldPath(desc gojsonschema.ResultError) string {
+	path := desc.Field()
+	if path == gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+		path = ""
+	}
+
+	if property, ok := desc.Details()["property"].(string); ok && desc.Type() == "required" {
+		if path == "" {
+			return property
+		}
+
+		return path + "." + property
+	}
+
+	return path
+}
@@ -0,0 +1,233 @@
+package plugincore
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// FuncMap returns the functions available to schema input templates. It is
+// deliberately curated: there is no access to the environment, files or the
+// network.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"toJson":     toJSON,
+		"default":    defaultValue,
+		"required":   required,
+		"b64enc":     b64enc,
+		"b64dec":     b64dec,
+		"now":        time.Now,
+		"formatTime": formatTime,
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"trim":       strings.TrimSpace,
+		"replace":    replace,
+		"split":      strings.Split,
+		"join":       join,
+		"contains":   strings.Contains,
+		"hasPrefix":  strings.HasPrefix,
+		"hasSuffix":  strings.HasSuffix,
+		"jsonPath":   jsonPath,
+		"uuid":       newUUID,
+		"secret":     secret,
+		"add":        add,
+		"sub":        sub,
+		"mul":        mul,
+		"div":        div,
+		"mod":        mod,
+	}
+}
+
+func toJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// defaultValue returns defaultVal when value is nil or the zero value of its
+// type. Combine it with jsonPath for optional fields, since missing keys
+// accessed directly are an error: {{ default "n/a" (jsonPath .data "a.b") }}.
+func defaultValue(defaultVal any, value any) any {
+	if isEmpty(value) {
+		return defaultVal
+	}
+
+	return value
+}
+
+func required(message string, value any) (any, error) {
+	if isEmpty(value) {
+		return nil, errors.New(message)
+	}
+
+	return value, nil
+}
+
+func b64enc(value string) string {
+	return base64.StdEncoding.EncodeToString([]byte(value))
+}
+
+func b64dec(value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// formatTime formats a time.Time, an RFC 3339 string or a unix timestamp in
+// seconds using a Go layout.
+func formatTime(layout string, value any) (string, error) {
+	switch t := value.(type) {
+	case time.Time:
+		return t.Format(layout), nil
+	case string:
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return "", err
+		}
+
+		return parsed.Format(layout), nil
+	default:
+		seconds, err := toFloat(value)
+		if err != nil {
+			return "", fmt.Errorf("formatTime: unsupported value %v", value)
+		}
+
+		return time.Unix(int64(seconds), 0).UTC().Format(layout), nil
+	}
+}
+
+func replace(old, new, value string) string {
+	return strings.ReplaceAll(value, old, new)
+}
+
+func join(separator string, values any) (string, error) {
+	switch items := values.(type) {
+	case []string:
+		return strings.Join(items, separator), nil
+	case []any:
+		parts := make([]string, 0, len(items))
+		for _, item := range items {
+			parts = append(parts, fmt.Sprint(item))
+		}
+
+		return strings.Join(parts, separator), nil
+	default:
+		return "", fmt.Errorf("join: unsupported value %v", values)
+	}
+}
+
+func jsonPath(data any, path string) any {
+	value, _ := LookupPath(data, path)
+	return value
+}
+
+func newUUID() (string, error) {
+	var id [16]byte
+
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]), nil
+}
+
+func add(a, b any) (float64, error) {
+	return arithmetic(a, b, func(x, y float64) (float64, error) { return x + y, nil })
+}
+
+func sub(a, b any) (float64, error) {
+	return arithmetic(a, b, func(x, y float64) (float64, error) { return x - y, nil })
+}
+
+func mul(a, b any) (float64, error) {
+	return arithmetic(a, b, func(x, y float64) (float64, error) { return x * y, nil })
+}
+
+func div(a, b any) (float64, error) {
+	return arithmetic(a, b, func(x, y float64) (float64, error) {
+		if y == 0 {
+			return 0, errors.New("div: division by zero")
+		}
+
+		return x / y, nil
+	})
+}
+
+func mod(a, b any) (float64, error) {
+	return arithmetic(a, b, func(x, y float64) (float64, error) {
+		if int64(y) == 0 {
+			return 0, errors.New("mod: division by zero")
+		}
+
+		return float64(int64(x) % int64(y)), nil
+	})
+}
+
+func arithmetic(a, b any, operation func(x, y float64) (float64, error)) (float64, error) {
+	x, err := toFloat(a)
+	if err != nil {
+		return 0, err
+	}
+
+	y, err := toFloat(b)
+	if err != nil {
+		return 0, err
+	}
+
+	return operation(x, y)
+}
+
+func toFloat(value any) (float64, error) {
+	switch number := value.(type) {
+	case string:
+		return strconv.ParseFloat(number, 64)
+	case json.Number:
+		return number.Float64()
+	}
+
+	reflected := reflect.ValueOf(value)
+
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(reflected.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(reflected.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return reflected.Float(), nil
+	default:
+		return 0, fmt.Errorf("%v is not a number", value)
+	}
+}
+
+func isEmpty(value any) bool {
+	if value == nil {
+		return true
+	}
+
+	reflected := reflect.ValueOf(value)
+
+	switch reflected.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return reflected.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return reflected.IsNil()
+	default:
+		return reflected.IsZero()
+	}
+}
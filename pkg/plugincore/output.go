@@ -0,0 +1,79 @@
+package plugincore
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// ValidateOutput validates a plugin output against the plugin output JSON
+// schema, returning a *ValidationError when it does not match.
+func ValidateOutput(schema []byte, output any) error {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+
+	return validate(schema, data)
+}
+
+// SchemaHasPath reports whether the JSON schema accepts the field at path.
+// Like JSON Schema itself, objects are open: only objects with
+// additionalProperties false reject the fields they do not declare.
+// Schemas using combinators and references accept any path.
+func SchemaHasPath(schema []byte, path []string) (bool, error) {
+	var document map[string]any
+
+	if err := json.Unmarshal(schema, &document); err != nil {
+		return false, err
+	}
+
+	return schemaHasPath(document, path), nil
+}
+
+func schemaHasPath(schema map[string]any, path []string) bool {
+	if len(path) == 0 {
+		return true
+	}
+
+	if _, ok := schema["$ref"]; ok {
+		return true
+	}
+
+	for _, combinator := range []string{"anyOf", "oneOf", "allOf"} {
+		if branches, ok := schema[combinator].([]any); ok {
+			for _, branch := range branches {
+				if branchSchema, ok := branch.(map[string]any); ok && schemaHasPath(branchSchema, path) {
+					return true
+				}
+			}
+
+			return false
+		}
+	}
+
+	if items, ok := schema["items"].(map[string]any); ok {
+		if _, err := strconv.Atoi(path[0]); err == nil {
+			return schemaHasPath(items, path[1:])
+		}
+
+		return false
+	}
+
+	properties, ok := schema["properties"].(map[string]any)
+	if !ok {
+		return true
+	}
+
+	if property, ok := properties[path[0]].(map[string]any); ok {
+		return schemaHasPath(property, path[1:])
+	}
+
+	switch additional := schema["additionalProperties"].(type) {
+	case bool:
+		return additional
+	case map[string]any:
+		return schemaHasPath(additional, path[1:])
+	default:
+		return true
+	}
+}
@@ -0,0 +1,37 @@
+package plugincore
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LookupPath returns the value found at a dot separated path such as
+// "data.items", "$.meta.next_cursor" or "items.0.id".
+func LookupPath(data any, path string) (value any, ok bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return data, true
+	}
+
+	value = data
+
+	for _, key := range strings.Split(path, ".") {
+		switch current := value.(type) {
+		case map[string]any:
+			if value, ok = current[key]; !ok {
+				return nil, false
+			}
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil, false
+			}
+
+			value = current[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
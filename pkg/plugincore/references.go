@@ -0,0 +1,96 @@
+package plugincore
+
+import (
+	"strings"
+	"text/template/parse"
+)
+
+const (
+	ReferenceRootData         = "data"
+	ReferenceRootSharedForAll = "sharedForAll"
+)
+
+// TemplateReference is a field of the template data used by a schema input,
+// such as .data.user.email (Root "data", Path ["user", "email"]).
+type TemplateReference struct {
+	Expression string
+	Root       string
+	Path       []string
+}
+
+// TemplateReferences parses the schema input and returns the template data
+// fields it references. Fields used inside with and range blocks, where the
+// dot no longer points to the template data, are not reported.
+func TemplateReferences(mode TemplateMode, schemaInputs string) (references []TemplateReference, err error) {
+	var tmpl compiledTemplate
+
+	tmpl, err = cachedTemplate(mode, schemaInputs)
+	if err != nil {
+		return
+	}
+
+	for _, tree := range tmpl.trees() {
+		collectReferences(tree.Root, true, &references)
+	}
+
+	return references, nil
+}
+
+func collectReferences(node parse.Node, dotIsRoot bool, references *[]TemplateReference) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			collectReferences(child, dotIsRoot, references)
+		}
+	case *parse.ActionNode:
+		collectReferences(n.Pipe, dotIsRoot, references)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+
+		for _, command := range n.Cmds {
+			collectReferences(command, dotIsRoot, references)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectReferences(arg, dotIsRoot, references)
+		}
+	case *parse.ChainNode:
+		collectReferences(n.Node, dotIsRoot, references)
+	case *parse.FieldNode:
+		if dotIsRoot {
+			*references = append(*references, newTemplateReference(n.Ident))
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			*references = append(*references, newTemplateReference(n.Ident[1:]))
+		}
+	case *parse.IfNode:
+		collectReferences(n.Pipe, dotIsRoot, references)
+		collectReferences(n.List, dotIsRoot, references)
+		collectReferences(n.ElseList, dotIsRoot, references)
+	case *parse.WithNode:
+		collectReferences(n.Pipe, dotIsRoot, references)
+		collectReferences(n.List, false, references)
+		collectReferences(n.ElseList, dotIsRoot, references)
+	case *parse.RangeNode:
+		collectReferences(n.Pipe, dotIsRoot, references)
+		collectReferences(n.List, false, references)
+		collectReferences(n.ElseList, dotIsRoot, references)
+	case *parse.TemplateNode:
+		collectReferences(n.Pipe, dotIsRoot, references)
+	}
+}
+
+func newTemplateReference(ident []string) TemplateReference {
+	return TemplateReference{
+		Expression: "." + strings.Join(ident, "."),
+		Root:       ident[0],
+		Path:       ident[1:],
+	}
+}
@@ -0,0 +1,312 @@
+package plugincore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+// SecretResolver returns the value of a secret referenced by {{ secret "name" }}.
+type SecretResolver func(ctx *yctx.Context, name string) (string, error)
+
+// SensitiveValuesHandler receives the secret values resolved while rendering
+// a schema input, and the values derived from them by template functions,
+// so they can be hidden from statuses and logs.
+type SensitiveValuesHandler func(values ...string)
+
+type (
+	secretResolverKey         struct{}
+	sensitiveValuesHandlerKey struct{}
+
+	secretValue struct {
+		name  string
+		value string
+	}
+
+	// secretScope resolves the secrets of one render and tracks the values
+	// that template functions derive from them.
+	secretScope struct {
+		ctx      *yctx.Context
+		resolver SecretResolver
+		mu       sync.Mutex
+		values   []secretValue
+	}
+)
+
+// escapeSecretFunc is appended, in text mode, to the actions that output a
+// secret, so the value is escaped for the JSON string it is written in.
+const escapeSecretFunc = "_escapeSecret"
+
+var (
+	ErrSecretResolverMissing = errors.New("no secret resolver in context")
+
+	// SecretNamePattern is the syntax of secret names, shared by the secret
+	// store and {{ secret "name" }}.
+	SecretNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+
+	// builtinFuncs are the text/template builtins that return strings. They
+	// are replaced while rendering to track the values derived from secrets.
+	builtinFuncs = template.FuncMap{
+		"print":    fmt.Sprint,
+		"printf":   fmt.Sprintf,
+		"println":  fmt.Sprintln,
+		"urlquery": template.URLQueryEscaper,
+		"html":     template.HTMLEscaper,
+		"js":       template.JSEscaper,
+	}
+)
+
+// WithSecretResolver returns a context that makes ValidateAndGetRequestBody
+// resolve {{ secret "name" }} references with resolver.
+func WithSecretResolver(ctx *yctx.Context, resolver SecretResolver) *yctx.Context {
+	return yctx.NewContext(context.WithValue(ctx.Context(), secretResolverKey{}, resolver))
+}
+
+// WithSensitiveValuesHandler returns a context that makes
+// ValidateAndGetRequestBody report the secret values it renders to handler.
+func WithSensitiveValuesHandler(ctx *yctx.Context, handler SensitiveValuesHandler) *yctx.Context {
+	return yctx.NewContext(context.WithValue(ctx.Context(), sensitiveValuesHandlerKey{}, handler))
+}
+
+func secretResolverFromContext(ctx *yctx.Context) SecretResolver {
+	resolver, _ := ctx.Context().Value(secretResolverKey{}).(SecretResolver)
+
+	return resolver
+}
+
+// ValidSecretName reports whether name can be used as a secret name.
+func ValidSecretName(name string) bool {
+	return SecretNamePattern.MatchString(name)
+}
+
+// secret is the function available while parsing; it is replaced by the
+// one of secretScope when the context has a resolver.
+func secret(name string) (string, error) {
+	if !ValidSecretName(name) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+
+	return "", ErrSecretResolverMissing
+}
+
+func escapeSecret(value string) string {
+	escaped, _ := json.Marshal(value)
+
+	return string(escaped[1 : len(escaped)-1])
+}
+
+func newSecretScope(ctx *yctx.Context) *secretScope {
+	return &secretScope{
+		ctx:      ctx,
+		resolver: secretResolverFromContext(ctx),
+	}
+}
+
+// funcs returns the functions that replace the ones of FuncMap while
+// rendering: secret resolves the value with the resolver of the context, and
+// every function returning a string derived from a secret has its result
+// tracked as well. It is nil without a resolver.
+func (s *secretScope) funcs() template.FuncMap {
+	if s.resolver == nil {
+		return nil
+	}
+
+	funcs := make(template.FuncMap)
+
+	for name, function := range FuncMap() {
+		funcs[name] = s.track(function)
+	}
+
+	for name, function := range builtinFuncs {
+		funcs[name] = s.track(function)
+	}
+
+	funcs["secret"] = s.resolve
+	funcs[escapeSecretFunc] = s.track(escapeSecret)
+
+	return funcs
+}
+
+func (s *secretScope) resolve(name string) (string, error) {
+	if !ValidSecretName(name) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, resolved := range s.values {
+		if resolved.name == name {
+			return resolved.value, nil
+		}
+	}
+
+	value, err := s.resolver(s.ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %q: %w", name, err)
+	}
+
+	s.values = append(s.values, secretValue{name: name, value: value})
+
+	return value, nil
+}
+
+// track wraps function so its string results are tracked when one of its
+// arguments contains a secret value.
+func (s *secretScope) track(function any) any {
+	value := reflect.ValueOf(function)
+
+	return reflect.MakeFunc(value.Type(), func(args []reflect.Value) []reflect.Value {
+		var results []reflect.Value
+
+		if value.Type().IsVariadic() {
+			results = value.CallSlice(args)
+		} else {
+			results = value.Call(args)
+		}
+
+		if name, ok := s.derivedFrom(args); ok {
+			for _, result := range results {
+				if result.Kind() == reflect.String {
+					s.add(name, result.String())
+				}
+			}
+		}
+
+		return results
+	}).Interface()
+}
+
+func (s *secretScope) derivedFrom(args []reflect.Value) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.values) == 0 {
+		return "", false
+	}
+
+	for _, arg := range args {
+		if !arg.IsValid() {
+			continue
+		}
+
+		text := fmt.Sprint(arg.Interface())
+
+		for _, secret := range s.values {
+			if secret.value != "" && strings.Contains(text, secret.value) {
+				return secret.name, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func (s *secretScope) add(name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, secret := range s.values {
+		if secret.value == value {
+			return
+		}
+	}
+
+	s.values = append(s.values, secretValue{name: name, value: value})
+}
+
+// report sends the tracked values to the SensitiveValuesHandler of the
+// context.
+func (s *secretScope) report() {
+	handler, _ := s.ctx.Context().Value(sensitiveValuesHandlerKey{}).(SensitiveValuesHandler)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if handler == nil || len(s.values) == 0 {
+		return
+	}
+
+	values := make([]string, 0, len(s.values))
+	for _, secret := range s.values {
+		values = append(values, secret.value)
+	}
+
+	handler(values...)
+}
+
+// hide replaces the tracked values found in validation messages.
+func (s *secretScope) hide(err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return hideSecrets(err, s.values)
+}
+
+func hideSecrets(err error, values []secretValue) error {
+	var validationErr *ValidationError
+	if len(values) == 0 || !errors.As(err, &validationErr) {
+		return err
+	}
+
+	for i, fieldError := range validationErr.Errors {
+		for _, secret := range values {
+			if secret.value != "" {
+				fieldError.Message = strings.ReplaceAll(fieldError.Message, secret.value, fmt.Sprintf("[secret %s]", secret.name))
+			}
+		}
+
+		validationErr.Errors[i] = fieldError
+	}
+
+	return err
+}
+
+// escapeSecretActions appends escapeSecretFunc to the actions of a text
+// template whose last command is a call to secret. Secrets piped to other
+// functions reach them unescaped.
+func escapeSecretActions(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			escapeSecretActions(tree, child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 || len(n.Pipe.Cmds) == 0 {
+			return
+		}
+
+		last := n.Pipe.Cmds[len(n.Pipe.Cmds)-1]
+		if identifier, ok := last.Args[0].(*parse.IdentifierNode); !ok || identifier.Ident != "secret" {
+			return
+		}
+
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(escapeSecretFunc).SetTree(tree).SetPos(n.Pos)},
+		})
+	case *parse.IfNode:
+		escapeSecretActions(tree, n.List)
+		escapeSecretActions(tree, n.ElseList)
+	case *parse.RangeNode:
+		escapeSecretActions(tree, n.List)
+		escapeSecretActions(tree, n.ElseList)
+	case *parse.WithNode:
+		escapeSecretActions(tree, n.List)
+		escapeSecretActions(tree, n.ElseList)
+	}
+}
@@ -0,0 +1,124 @@
+package plugincore
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ValidateStatic validates the parts of a schema input that do not depend on
+// template data against the plugin schema. Templated values are left out and
+// errors about them are ignored. A schema input that only becomes valid JSON
+// after rendering can't be checked and returns no errors.
+func ValidateStatic(schema []byte, schemaInputs string) (fieldErrors []FieldError, err error) {
+	var (
+		document  any
+		templated = make(map[string]bool)
+		data      []byte
+		compiled  *gojsonschema.Schema
+		result    *gojsonschema.Result
+	)
+
+	if json.Unmarshal([]byte(schemaInputs), &document) != nil {
+		return nil, nil
+	}
+
+	document = stripTemplates(document, "", templated)
+	if templated[""] {
+		return nil, nil
+	}
+
+	data, err = json.Marshal(document)
+	if err != nil {
+		return
+	}
+
+	compiled, err = compileSchema(schema)
+	if err != nil {
+		return
+	}
+
+	result, err = compiled.Validate(gojsonschema.NewBytesLoader(data))
+	if err != nil || result.Valid() {
+		return
+	}
+
+	for _, fieldError := range newValidationError(result).Errors {
+		if !isTemplatedPath(fieldError.Field, templated) {
+			fieldErrors = append(fieldErrors, fieldError)
+		}
+	}
+
+	return fieldErrors, nil
+}
+
+// stripTemplates removes templated string leaves from the document and
+// records their paths. Objects with templated keys are recorded as a whole.
+func stripTemplates(value any, path string, templated map[string]bool) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		object := make(map[string]any, len(typed))
+
+		for key, item := range typed {
+			if strings.Contains(key, "{{") {
+				templated[path] = true
+				continue
+			}
+
+			childPath := joinPath(path, key)
+			if isTemplatedString(item) {
+				templated[childPath] = true
+				continue
+			}
+
+			object[key] = stripTemplates(item, childPath, templated)
+		}
+
+		return object
+	case []any:
+		array := make([]any, len(typed))
+
+		for index, item := range typed {
+			childPath := joinPath(path, strconv.Itoa(index))
+			if isTemplatedString(item) {
+				templated[childPath] = true
+				continue
+			}
+
+			array[index] = stripTemplates(item, childPath, templated)
+		}
+
+		return array
+	default:
+		if isTemplatedString(value) {
+			templated[path] = true
+		}
+
+		return value
+	}
+}
+
+func isTemplatedString(value any) bool {
+	text, ok := value.(string)
+	return ok && strings.Contains(text, "{{")
+}
+
+func isTemplatedPath(field string, templated map[string]bool) bool {
+	for path := range templated {
+		if path == "" || field == path || strings.HasPrefix(field, path+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
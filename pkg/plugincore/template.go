@@ -0,0 +1,342 @@
+package plugincore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/yrn-go/yrn/pkg/yctx"
+)
+
+type TemplateMode string
+
+const (
+	// TemplateModeText renders the whole schema input as text and parses the
+	// result as JSON. Values are not escaped.
+	TemplateModeText TemplateMode = "text"
+	// TemplateModeJSON parses the schema input as JSON and renders templates
+	// only inside string leaves, so data is always escaped correctly. A leaf
+	// that is exactly one expression, like "{{ .data.items }}", is replaced by
+	// the typed value of the expression.
+	TemplateModeJSON TemplateMode = "json"
+
+	templateName = "plugin_slug"
+)
+
+type (
+	templateModeKey struct{}
+
+	compiledTemplate interface {
+		render(data map[string]any, funcs template.FuncMap) ([]byte, error)
+		trees() []*parse.Tree
+	}
+
+	textTemplate struct {
+		tmpl *template.Template
+	}
+
+	jsonTemplate struct {
+		root jsonNode
+	}
+
+	jsonNode interface {
+		render(data map[string]any, funcs template.FuncMap) (any, error)
+		trees() []*parse.Tree
+	}
+
+	jsonLiteral struct {
+		value any
+	}
+
+	jsonString struct {
+		tmpl *template.Template
+	}
+
+	jsonExpression struct {
+		tmpl *template.Template
+	}
+
+	jsonField struct {
+		key   jsonNode
+		value jsonNode
+	}
+
+	jsonObject struct {
+		fields []jsonField
+	}
+
+	jsonArray struct {
+		items []jsonNode
+	}
+)
+
+// WithTemplateMode returns a context that makes ValidateAndGetRequestBody
+// render schema inputs with the given mode.
+func WithTemplateMode(ctx *yctx.Context, mode TemplateMode) *yctx.Context {
+	return yctx.NewContext(context.WithValue(ctx.Context(), templateModeKey{}, mode))
+}
+
+// TemplateModeFromContext returns the mode set by WithTemplateMode, or
+// TemplateModeText when none was set.
+func TemplateModeFromContext(ctx *yctx.Context) TemplateMode {
+	if mode, ok := ctx.Context().Value(templateModeKey{}).(TemplateMode); ok && mode != "" {
+		return mode
+	}
+
+	return TemplateModeText
+}
+
+func compileTemplate(mode TemplateMode, schemaInputs string) (compiledTemplate, error) {
+	switch mode {
+	case TemplateModeText, "":
+		tmpl, err := newTemplate(schemaInputs)
+		if err != nil {
+			return nil, err
+		}
+
+		escapeSecretActions(tmpl.Tree, tmpl.Tree.Root)
+
+		return &textTemplate{tmpl: tmpl}, nil
+	case TemplateModeJSON:
+		var document any
+
+		decoder := json.NewDecoder(strings.NewReader(schemaInputs))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&document); err != nil {
+			return nil, fmt.Errorf("schema input is not valid json: %w", err)
+		}
+
+		root, err := compileJSONNode(document)
+		if err != nil {
+			return nil, err
+		}
+
+		return &jsonTemplate{root: root}, nil
+	default:
+		return nil, fmt.Errorf("unknown template mode %q", mode)
+	}
+}
+
+func newTemplate(text string) (*template.Template, error) {
+	return template.
+		New(templateName).
+		Funcs(FuncMap()).
+		Funcs(template.FuncMap{escapeSecretFunc: escapeSecret}).
+		Option("missingkey=error").
+		Parse(text)
+}
+
+func compileJSONNode(value any) (jsonNode, error) {
+	switch typed := value.(type) {
+	case map[string]any:
+		object := &jsonObject{}
+
+		for key, item := range typed {
+			keyNode, err := compileJSONString(key, false)
+			if err != nil {
+				return nil, err
+			}
+
+			valueNode, err := compileJSONNode(item)
+			if err != nil {
+				return nil, err
+			}
+
+			object.fields = append(object.fields, jsonField{key: keyNode, value: valueNode})
+		}
+
+		return object, nil
+	case []any:
+		array := &jsonArray{items: make([]jsonNode, 0, len(typed))}
+
+		for _, item := range typed {
+			node, err := compileJSONNode(item)
+			if err != nil {
+				return nil, err
+			}
+
+			array.items = append(array.items, node)
+		}
+
+		return array, nil
+	case string:
+		return compileJSONString(typed, true)
+	default:
+		return &jsonLiteral{value: value}, nil
+	}
+}
+
+// compileJSONString compiles a string leaf. When typed is true and the leaf
+// is exactly one action without variable declarations, the action pipeline is
+// wrapped in toJson so the rendered value keeps its type.
+func compileJSONString(value string, typed bool) (jsonNode, error) {
+	if !strings.Contains(value, "{{") {
+		return &jsonLiteral{value: value}, nil
+	}
+
+	tmpl, err := newTemplate(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if typed {
+		if nodes := tmpl.Tree.Root.Nodes; len(nodes) == 1 {
+			if action, ok := nodes[0].(*parse.ActionNode); ok && len(action.Pipe.Decl) == 0 {
+				expression, err := newTemplate(fmt.Sprintf("{{ toJson (%s) }}", action.Pipe))
+				if err != nil {
+					return nil, err
+				}
+
+				return &jsonExpression{tmpl: expression}, nil
+			}
+		}
+	}
+
+	return &jsonString{tmpl: tmpl}, nil
+}
+
+// execute runs tmpl with funcs replacing its functions. The compiled
+// template is shared by the cache, so it is cloned first.
+func execute(tmpl *template.Template, writer io.Writer, data map[string]any, funcs template.FuncMap) error {
+	if funcs != nil {
+		clone, err := tmpl.Clone()
+		if err != nil {
+			return err
+		}
+
+		tmpl = clone.Funcs(funcs)
+	}
+
+	return tmpl.Execute(writer, data)
+}
+
+func (t *textTemplate) render(data map[string]any, funcs template.FuncMap) ([]byte, error) {
+	result := &bytes.Buffer{}
+
+	if err := execute(t.tmpl, result, data, funcs); err != nil {
+		return nil, err
+	}
+
+	return result.Bytes(), nil
+}
+
+func (t *jsonTemplate) render(data map[string]any, funcs template.FuncMap) ([]byte, error) {
+	document, err := t.root.render(data, funcs)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(document)
+}
+
+func (n *jsonLiteral) render(map[string]any, template.FuncMap) (any, error) {
+	return n.value, nil
+}
+
+func (n *jsonString) render(data map[string]any, funcs template.FuncMap) (any, error) {
+	result := &strings.Builder{}
+
+	if err := execute(n.tmpl, result, data, funcs); err != nil {
+		return nil, err
+	}
+
+	return result.String(), nil
+}
+
+func (n *jsonExpression) render(data map[string]any, funcs template.FuncMap) (any, error) {
+	var (
+		result = &bytes.Buffer{}
+		value  any
+	)
+
+	if err := execute(n.tmpl, result, data, funcs); err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(result)
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func (n *jsonObject) render(data map[string]any, funcs template.FuncMap) (any, error) {
+	object := make(map[string]any, len(n.fields))
+
+	for _, field := range n.fields {
+		key, err := field.key.render(data, funcs)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := field.value.render(data, funcs)
+		if err != nil {
+			return nil, err
+		}
+
+		object[fmt.Sprint(key)] = value
+	}
+
+	return object, nil
+}
+
+func (n *jsonArray) render(data map[string]any, funcs template.FuncMap) (any, error) {
+	array := make([]any, 0, len(n.items))
+
+	for _, item := range n.items {
+		value, err := item.render(data, funcs)
+		if err != nil {
+			return nil, err
+		}
+
+		array = append(array, value)
+	}
+
+	return array, nil
+}
+
+func (t *textTemplate) trees() []*parse.Tree {
+	return []*parse.Tree{t.tmpl.Tree}
+}
+
+func (t *jsonTemplate) trees() []*parse.Tree {
+	return t.root.trees()
+}
+
+func (n *jsonLiteral) trees() []*parse.Tree {
+	return nil
+}
+
+func (n *jsonString) trees() []*parse.Tree {
+	return []*parse.Tree{n.tmpl.Tree}
+}
+
+func (n *jsonExpression) trees() []*parse.Tree {
+	return []*parse.Tree{n.tmpl.Tree}
+}
+
+func (n *jsonObject) trees() (trees []*parse.Tree) {
+	for _, field := range n.fields {
+		trees = append(trees, field.key.trees()...)
+		trees = append(trees, field.value.trees()...)
+	}
+
+	return
+}
+
+func (n *jsonArray) trees() (trees []*parse.Tree) {
+	for _, item := range n.items {
+		trees = append(trees, item.trees()...)
+	}
+
+	return
+}
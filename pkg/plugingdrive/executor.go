@@ -20,10 +20,15 @@ const (
 )
 
 var (
-	_ flowmanager.PluginExecutor = (*Executor)(nil)
+	_ flowmanager.PluginExecutor             = (*Executor)(nil)
+	_ flowmanager.PluginSchemaProvider       = (*Executor)(nil)
+	_ flowmanager.PluginOutputSchemaProvider = (*Executor)(nil)
 
 	//go:embed schema.json
 	Schema []byte
+
+	//go:embed schema_output.json
+	SchemaOutput []byte
 )
 
 type DriveSchema struct {
@@ -45,6 +50,7 @@ func (e *Executor) Do(ctx *yctx.Context, schemaInputs string, previousPluginResp
 
 	// Valida e carrega o schema
 	requestData, err = plugincore.ValidateAndGetRequestBody[DriveSchema](
+		ctx,
 		Schema,
 		schemaInputs,
 		previousPluginResponse,
@@ -108,3 +114,11 @@ func (e *Executor) Do(ctx *yctx.Context, schemaInputs string, previousPluginResp
 	output = result
 	return
 }
+
+func (e *Executor) InputSchema() []byte {
+	return Schema
+}
+
+func (e *Executor) OutputSchema() []byte {
+	return SchemaOutput
+}
@@ -18,9 +18,14 @@ const (
 )
 
 var (
-	_ flowmanager.PluginExecutor = (*Executor)(nil)
+	_ flowmanager.PluginExecutor             = (*Executor)(nil)
+	_ flowmanager.PluginSchemaProvider       = (*Executor)(nil)
+	_ flowmanager.PluginOutputSchemaProvider = (*Executor)(nil)
 	//go:embed schema.json
 	Schema []byte
+
+	//go:embed schema_output.json
+	SchemaOutput []byte
 )
 
 type (
@@ -40,7 +45,7 @@ func NewExecutor() *Executor {
 
 func (e *Executor) Do(ctx *yctx.Context, schemaInputs string, previousPluginResponse any, responseSharedForAll map[string]any) (output any, err error) {
 	var schema *AuthSchema
-	schema, err = plugincore.ValidateAndGetRequestBody[AuthSchema](Schema, schemaInputs, previousPluginResponse, responseSharedForAll)
+	schema, err = plugincore.ValidateAndGetRequestBody[AuthSchema](ctx, Schema, schemaInputs, previousPluginResponse, responseSharedForAll)
 	if err != nil {
 		return
 	}
@@ -78,3 +83,11 @@ func (e *Executor) Do(ctx *yctx.Context, schemaInputs string, previousPluginResp
 	err = json.Unmarshal(body, &output)
 	return
 }
+
+func (e *Executor) InputSchema() []byte {
+	return Schema
+}
+
+func (e *Executor) OutputSchema() []byte {
+	return SchemaOutput
+}
@@ -0,0 +1,185 @@
+package pluginhttp
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// maxClients bounds the clients kept by an executor, since flows choose
+	// the TLS and proxy settings that key them.
+	maxClients = 64
+	// clientIdleTimeout evicts the clients not used for a while.
+	clientIdleTimeout = 10 * time.Minute
+)
+
+type cachedClient struct {
+	key      string
+	client   *http.Client
+	lastUsed time.Time
+}
+
+// client returns the http.Client for the TLS and proxy configuration of the
+// request, reusing the one created by a previous call with the same settings.
+// The least recently used clients are evicted beyond maxClients.
+func (e *Executor) client(tlsConfig *TLSConfig, proxy string) (client *http.Client, err error) {
+	var (
+		key       string
+		transport http.RoundTripper
+	)
+
+	key, err = clientKey(tlsConfig, proxy)
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	e.evictIdleClients(now)
+
+	if element, ok := e.clients[key]; ok {
+		cached := element.Value.(*cachedClient)
+		cached.lastUsed = now
+		e.lru.MoveToFront(element)
+
+		return cached.client, nil
+	}
+
+	transport, err = newTransport(tlsConfig, proxy, e.policy)
+	if err != nil {
+		return
+	}
+
+	client = &http.Client{Transport: transport}
+
+	e.clients[key] = e.lru.PushFront(&cachedClient{key: key, client: client, lastUsed: now})
+
+	for e.lru.Len() > maxClients {
+		e.evictClient(e.lru.Back())
+	}
+
+	return client, nil
+}
+
+func (e *Executor) evictIdleClients(now time.Time) {
+	for element := e.lru.Back(); element != nil; element = e.lru.Back() {
+		if now.Sub(element.Value.(*cachedClient).lastUsed) < clientIdleTimeout {
+			return
+		}
+
+		e.evictClient(element)
+	}
+}
+
+// evictClient removes the client from the cache and closes its idle
+// connections; requests in progress keep their connections.
+func (e *Executor) evictClient(element *list.Element) {
+	cached := e.lru.Remove(element).(*cachedClient)
+	delete(e.clients, cached.key)
+
+	cached.client.CloseIdleConnections()
+}
+
+func clientKey(tlsConfig *TLSConfig, proxy string) (string, error) {
+	data, err := json.Marshal(struct {
+		TLS   *TLSConfig `json:"tls"`
+		Proxy string     `json:"proxy"`
+	}{tlsConfig, proxy})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func newTransport(tlsConfig *TLSConfig, proxy string, policy *EgressPolicy) (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = policy.dialer().DialContext
+	// the proxies of the environment would bypass the address rules.
+	transport.Proxy = nil
+
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+
+		if err = policy.CheckProxy(proxyURL); err != nil {
+			return nil, err
+		}
+
+		transport.Proxy = http.ProxyURL(proxyURL)
+		transport.DialContext = proxyDialer(proxyURL, policy)
+	}
+
+	if tlsConfig != nil {
+		config, err := newTLSConfig(tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = config
+	}
+
+	return &policyTransport{policy: policy, transport: transport, proxied: proxy != ""}, nil
+}
+
+// proxyDialer dials the allowed proxy without the address rules, which are
+// checked against the target of each request instead.
+func proxyDialer(proxyURL *url.URL, policy *EgressPolicy) func(ctx context.Context, network, address string) (net.Conn, error) {
+	var (
+		proxyDialer  = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		policyDialer = policy.dialer()
+		proxyAddress = net.JoinHostPort(proxyURL.Hostname(), urlPort(proxyURL))
+	)
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if strings.EqualFold(address, proxyAddress) {
+			return proxyDialer.DialContext(ctx, network, address)
+		}
+
+		return policyDialer.DialContext(ctx, network, address)
+	}
+}
+
+func newTLSConfig(tlsConfig *TLSConfig) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         tlsConfig.ServerName,
+		InsecureSkipVerify: tlsConfig.InsecureSkipVerify, //nolint:gosec // opt-in for development
+	}
+
+	if tlsConfig.CACert != "" {
+		config.RootCAs = x509.NewCertPool()
+		if ok := config.RootCAs.AppendCertsFromPEM([]byte(tlsConfig.CACert)); !ok {
+			return nil, errors.New("tls: no valid certificate found in caCert")
+		}
+	}
+
+	if tlsConfig.ClientCert != "" || tlsConfig.ClientKey != "" {
+		certificate, err := tls.X509KeyPair([]byte(tlsConfig.ClientCert), []byte(tlsConfig.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("tls: invalid client certificate: %w", err)
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,366 @@
+package pluginhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	EnvEgressAllowedHosts   = "HTTP_EGRESS_ALLOWED_HOSTS"
+	EnvEgressExemptCIDRs    = "HTTP_EGRESS_EXEMPT_CIDRS"
+	EnvEgressDeniedCIDRs    = "HTTP_EGRESS_DENIED_CIDRS"
+	EnvEgressAllowPrivate   = "HTTP_EGRESS_ALLOW_PRIVATE"
+	EnvEgressAllowedSchemes = "HTTP_EGRESS_ALLOWED_SCHEMES"
+	EnvEgressAllowedPorts   = "HTTP_EGRESS_ALLOWED_PORTS"
+	EnvEgressAllowedProxies = "HTTP_EGRESS_ALLOWED_PROXIES"
+)
+
+var (
+	// ErrEgressDenied is the error class of every request blocked by the
+	// egress policy. Use errors.Is to detect it and errors.As with
+	// *EgressError to get the details.
+	ErrEgressDenied = errors.New("egress denied")
+
+	// DefaultDeniedCIDRs blocks link-local addresses, where the cloud
+	// metadata endpoints live, and the unspecified network.
+	DefaultDeniedCIDRs = []string{
+		"0.0.0.0/8",
+		"169.254.0.0/16",
+		"100.100.100.200/32",
+		"::/128",
+		"fe80::/10",
+		"fd00:ec2::254/128",
+	}
+
+	// PrivateCIDRs are the loopback and private ranges, denied unless
+	// HTTP_EGRESS_ALLOW_PRIVATE is true.
+	PrivateCIDRs = []string{
+		"127.0.0.0/8",
+		"10.0.0.0/8",
+		"172.16.0.0/12",
+		"192.168.0.0/16",
+		"100.64.0.0/10",
+		"::1/128",
+		"fc00::/7",
+	}
+
+	defaultAllowedSchemes = []string{"http", "https"}
+)
+
+type (
+	// EgressPolicy restricts the destinations the HTTP plugin can reach.
+	// Host, scheme and port rules are checked on every request, including
+	// redirects; address rules are checked at dial time against the
+	// resolved IP, or before the request for requests sent through a proxy.
+	EgressPolicy struct {
+		AllowedHosts []string // exact host names or "*.domain"; empty allows any host
+		// ExemptCIDRs are exceptions to DeniedCIDRs, not an allowlist:
+		// addresses outside DeniedCIDRs are allowed anyway.
+		ExemptCIDRs    []*net.IPNet
+		DeniedCIDRs    []*net.IPNet
+		AllowedSchemes []string
+		AllowedPorts   []int    // empty allows any port
+		AllowedProxies []string // proxy URLs a flow can use; empty denies every proxy
+	}
+
+	EgressError struct {
+		Target string
+		Reason string
+	}
+
+	policyTransport struct {
+		policy    *EgressPolicy
+		transport http.RoundTripper
+		proxied   bool
+	}
+)
+
+func (e *EgressError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrEgressDenied, e.Target, e.Reason)
+}
+
+func (e *EgressError) Unwrap() error {
+	return ErrEgressDenied
+}
+
+// DefaultEgressPolicy allows http and https to any host except the
+// addresses in DefaultDeniedCIDRs and PrivateCIDRs, without proxies.
+func DefaultEgressPolicy() *EgressPolicy {
+	policy := &EgressPolicy{
+		AllowedSchemes: defaultAllowedSchemes,
+	}
+
+	policy.DeniedCIDRs, _ = parseCIDRs(slices.Concat(DefaultDeniedCIDRs, PrivateCIDRs))
+
+	return policy
+}
+
+// EgressPolicyFromEnv builds the policy from the HTTP_EGRESS_* environment
+// variables, falling back to DefaultEgressPolicy for the ones not set.
+func EgressPolicyFromEnv() (policy *EgressPolicy, err error) {
+	policy = DefaultEgressPolicy()
+
+	if value := os.Getenv(EnvEgressAllowedHosts); value != "" {
+		policy.AllowedHosts = splitList(value)
+	}
+
+	if value := os.Getenv(EnvEgressExemptCIDRs); value != "" {
+		if policy.ExemptCIDRs, err = parseCIDRs(splitList(value)); err != nil {
+			return nil, err
+		}
+	}
+
+	deniedCIDRs := DefaultDeniedCIDRs
+	if value := os.Getenv(EnvEgressDeniedCIDRs); value != "" {
+		deniedCIDRs = splitList(value)
+	}
+
+	if allowPrivate, _ := strconv.ParseBool(os.Getenv(EnvEgressAllowPrivate)); !allowPrivate {
+		deniedCIDRs = slices.Concat(deniedCIDRs, PrivateCIDRs)
+	}
+
+	if policy.DeniedCIDRs, err = parseCIDRs(deniedCIDRs); err != nil {
+		return nil, err
+	}
+
+	if value := os.Getenv(EnvEgressAllowedProxies); value != "" {
+		policy.AllowedProxies = splitList(value)
+	}
+
+	if value := os.Getenv(EnvEgressAllowedSchemes); value != "" {
+		policy.AllowedSchemes = splitList(value)
+	}
+
+	if value := os.Getenv(EnvEgressAllowedPorts); value != "" {
+		for _, item := range splitList(value) {
+			port, err := strconv.Atoi(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", EnvEgressAllowedPorts, err)
+			}
+
+			policy.AllowedPorts = append(policy.AllowedPorts, port)
+		}
+	}
+
+	return policy, nil
+}
+
+// CheckRequest validates the scheme, host and port of an outgoing request.
+func (p *EgressPolicy) CheckRequest(req *http.Request) error {
+	target := req.URL.Redacted()
+
+	if !containsFold(p.AllowedSchemes, req.URL.Scheme) {
+		return &EgressError{Target: target, Reason: "scheme " + req.URL.Scheme + " is not allowed"}
+	}
+
+	if !p.hostAllowed(req.URL.Hostname()) {
+		return &EgressError{Target: target, Reason: "host " + req.URL.Hostname() + " is not allowed"}
+	}
+
+	port := urlPort(req.URL)
+	if !p.portAllowed(port) {
+		return &EgressError{Target: target, Reason: "port " + port + " is not allowed"}
+	}
+
+	return nil
+}
+
+// CheckAddress validates a resolved ip:port about to be dialed.
+func (p *EgressPolicy) CheckAddress(address string) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return &EgressError{Target: address, Reason: err.Error()}
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return &EgressError{Target: address, Reason: "address is not an ip"}
+	}
+
+	if !p.portAllowed(port) {
+		return &EgressError{Target: address, Reason: "port " + port + " is not allowed"}
+	}
+
+	if containsIP(p.ExemptCIDRs, ip) {
+		return nil
+	}
+
+	if containsIP(p.DeniedCIDRs, ip) {
+		return &EgressError{Target: address, Reason: "address " + ip.String() + " is in a denied range"}
+	}
+
+	return nil
+}
+
+// CheckProxy validates a proxy requested by a flow. The proxy must be one of
+// AllowedProxies; since it is chosen by the operator, its address is not
+// checked against the address rules.
+func (p *EgressPolicy) CheckProxy(proxy *url.URL) error {
+	for _, allowed := range p.AllowedProxies {
+		allowedURL, err := url.Parse(allowed)
+		if err == nil && strings.EqualFold(allowedURL.Scheme, proxy.Scheme) && strings.EqualFold(allowedURL.Host, proxy.Host) {
+			return nil
+		}
+	}
+
+	return &EgressError{Target: proxy.Redacted(), Reason: "proxy is not allowed"}
+}
+
+// checkTarget resolves the host of a request sent through a proxy and
+// validates its addresses, since the dialer only sees the proxy address.
+func (p *EgressPolicy) checkTarget(ctx context.Context, target *url.URL) error {
+	port := urlPort(target)
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", target.Hostname())
+	if err != nil {
+		return &EgressError{Target: target.Redacted(), Reason: "host cannot be resolved: " + err.Error()}
+	}
+
+	for _, ip := range ips {
+		if err = p.CheckAddress(net.JoinHostPort(ip.String(), port)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *EgressPolicy) hostAllowed(host string) bool {
+	if len(p.AllowedHosts) == 0 {
+		return true
+	}
+
+	host = strings.ToLower(host)
+
+	for _, allowed := range p.AllowedHosts {
+		allowed = strings.ToLower(allowed)
+
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+
+			continue
+		}
+
+		if host == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *EgressPolicy) portAllowed(port string) bool {
+	if len(p.AllowedPorts) == 0 {
+		return true
+	}
+
+	for _, allowed := range p.AllowedPorts {
+		if strconv.Itoa(allowed) == port {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dialer returns a dialer that enforces the policy on the resolved address,
+// so DNS answers pointing to denied ranges are blocked as well.
+func (p *EgressPolicy) dialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return p.CheckAddress(address)
+		},
+	}
+}
+
+func (t *policyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.policy.CheckRequest(req); err != nil {
+		return nil, err
+	}
+
+	if t.proxied {
+		if err := t.policy.checkTarget(req.Context(), req.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	return t.transport.RoundTrip(req)
+}
+
+func (t *policyTransport) CloseIdleConnections() {
+	if closer, ok := t.transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// urlPort returns the port of the URL or the default port of its scheme.
+func urlPort(target *url.URL) string {
+	if port := target.Port(); port != "" {
+		return port
+	}
+
+	if strings.EqualFold(target.Scheme, "https") {
+		return "443"
+	}
+
+	return "80"
+}
+
+func parseCIDRs(values []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(values))
+
+	for _, value := range values {
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %q: %w", value, err)
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
+
+func splitList(value string) (items []string) {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return
+}
+
+func containsFold(values []string, value string) bool {
+	for _, item := range values {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,13 +2,17 @@ package pluginhttp
 
 import (
 	"bytes"
+	"container/list"
 	_ "embed"
 	"encoding/json"
+	"fmt"
 	"github.com/yrn-go/yrn/module/flowmanager"
 	"github.com/yrn-go/yrn/pkg/plugincore"
 	"github.com/yrn-go/yrn/pkg/yctx"
 	"io"
 	"net/http"
+	"net/url"
+	"sync"
 )
 
 const (
@@ -16,29 +20,49 @@ const (
 )
 
 var (
-	_ flowmanager.PluginExecutor = (*Executor)(nil)
+	_ flowmanager.PluginExecutor       = (*Executor)(nil)
+	_ flowmanager.PluginSchemaProvider = (*Executor)(nil)
 	//go:embed schema.json
 	Schema []byte
 )
 
 type (
-	Executor struct{}
+	Executor struct {
+		policy  *EgressPolicy
+		mu      sync.Mutex
+		clients map[string]*list.Element
+		lru     *list.List
+	}
 )
 
-func NewExecutor() *Executor {
-	return &Executor{}
+// NewExecutor creates the executor with the egress policy configured by the
+// HTTP_EGRESS_* environment variables.
+func NewExecutor() (*Executor, error) {
+	policy, err := EgressPolicyFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("invalid http egress policy: %w", err)
+	}
+
+	return NewExecutorWithEgressPolicy(policy), nil
+}
+
+func NewExecutorWithEgressPolicy(policy *EgressPolicy) *Executor {
+	return &Executor{
+		policy:  policy,
+		clients: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
 }
 
 func (e *Executor) Do(ctx *yctx.Context, schemaInputs string, previousPluginResponse any, responseSharedForAll map[string]any) (output any, err error) {
 	var (
-		requestData  *HTTPSchema
-		requestBody  []byte
-		resp         *http.Response
-		req          *http.Request
-		responseBody []byte
+		requestData *HTTPSchema
+		requestURL  string
+		client      *http.Client
 	)
 
 	requestData, err = plugincore.ValidateAndGetRequestBody[HTTPSchema](
+		ctx,
 		Schema,
 		schemaInputs,
 		previousPluginResponse,
@@ -48,26 +72,51 @@ func (e *Executor) Do(ctx *yctx.Context, schemaInputs string, previousPluginResp
 		return
 	}
 
-	requestBody, err = json.Marshal(requestData.Request.Body)
+	client, err = e.client(requestData.TLS, requestData.Proxy)
+	if err != nil {
+		return
+	}
+
+	if requestData.Pagination != nil {
+		return e.paginate(ctx, client, &requestData.Request, requestData.Pagination)
+	}
+
+	requestURL, err = buildURL(requestData.Request.URL, requestData.Request.QueryParams, nil)
+	if err != nil {
+		return
+	}
+
+	output, _, err = e.send(ctx, client, &requestData.Request, requestURL)
+	return
+}
+
+func (e *Executor) send(ctx *yctx.Context, client *http.Client, request *HTTPRequest, requestURL string) (output any, header http.Header, err error) {
+	var (
+		requestBody  []byte
+		resp         *http.Response
+		req          *http.Request
+		responseBody []byte
+	)
+
+	requestBody, err = json.Marshal(request.Body)
 	if err != nil {
 		return
 	}
 
 	req, err = http.NewRequestWithContext(
 		ctx.Context(),
-		requestData.Request.Method,
-		requestData.Request.URL,
+		request.Method,
+		requestURL,
 		bytes.NewBuffer(requestBody),
 	)
 	if err != nil {
 		return
 	}
 
-	for key, value := range requestData.Request.Headers {
+	for key, value := range request.Headers {
 		req.Header.Set(key, value)
 	}
 
-	client := &http.Client{}
 	resp, err = client.Do(req)
 	if err != nil {
 		return
@@ -83,5 +132,34 @@ func (e *Executor) Do(ctx *yctx.Context, schemaInputs string, previousPluginResp
 	}
 
 	err = json.Unmarshal(responseBody, &output)
-	return
+	return output, resp.Header, err
+}
+
+func buildURL(rawURL string, queryParams map[string]string, extra url.Values) (string, error) {
+	if len(queryParams) == 0 && len(extra) == 0 {
+		return rawURL, nil
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := parsed.Query()
+
+	for key, value := range queryParams {
+		query.Set(key, value)
+	}
+
+	for key, values := range extra {
+		query[key] = values
+	}
+
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String(), nil
+}
+
+func (e *Executor) InputSchema() []byte {
+	return Schema
 }
@@ -4,12 +4,15 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"encoding/pem"
 	"github.com/stretchr/testify/suite"
 	"github.com/yrn-go/yrn/pkg/yctx"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 )
 
 func TestExecutor(t *testing.T) {
@@ -26,6 +29,14 @@ func (suite *ExecutorTestSuite) TearDownTest() {}
 
 func (suite *ExecutorTestSuite) TearDownSuite() {}
 
+// newExecutor allows the loopback addresses of the test servers.
+func (suite *ExecutorTestSuite) newExecutor() *Executor {
+	policy := DefaultEgressPolicy()
+	policy.ExemptCIDRs, _ = parseCIDRs([]string{"127.0.0.0/8", "::1/128"})
+
+	return NewExecutorWithEgressPolicy(policy)
+}
+
 func (suite *ExecutorTestSuite) TestDo_WithSuccess() {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
@@ -94,7 +105,7 @@ func (suite *ExecutorTestSuite) TestDo_WithSuccess() {
 
 	ctx := yctx.NewContext(context.Background())
 
-	executor := NewExecutor()
+	executor := suite.newExecutor()
 
 	response, err := executor.Do(ctx, string(body), previousPluginResponse, responseSharedForAll)
 	suite.NoError(err)
@@ -113,3 +124,430 @@ func (suite *ExecutorTestSuite) TestDo_WithSuccess() {
 	suite.True(telegramTextMessageStringOk)
 	suite.Equal(telegramTextMessage, telegramTextMessageString)
 }
+
+func (suite *ExecutorTestSuite) TestDo_WithPagePagination() {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages := map[string][]any{
+			"1": {"a", "b"},
+			"2": {"c", "d"},
+			"3": {"e"},
+		}
+
+		suite.Equal("2", r.URL.Query().Get("per_page"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{"items": pages[r.URL.Query().Get("page")]},
+		})
+	}))
+
+	defer mockServer.Close()
+
+	schemaBody, _ := json.Marshal(HTTPSchema{
+		Request: HTTPRequest{
+			Method: http.MethodGet,
+			URL:    mockServer.URL + "/items",
+		},
+		Pagination: &PaginationConfig{
+			Mode:      PaginationModePage,
+			ItemsPath: "data.items",
+			SizeParam: "per_page",
+			Size:      2,
+		},
+	})
+
+	response, err := suite.newExecutor().Do(yctx.NewContext(context.Background()), string(schemaBody), nil, nil)
+	suite.NoError(err)
+	suite.Equal([]any{"a", "b", "c", "d", "e"}, response)
+}
+
+func (suite *ExecutorTestSuite) TestDo_WithCursorPagination() {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+
+		switch r.URL.Query().Get("after") {
+		case "":
+			body = map[string]any{"items": []any{1, 2}, "meta": map[string]any{"next": "c1"}}
+		case "c1":
+			body = map[string]any{"items": []any{3}, "meta": map[string]any{"next": nil}}
+		default:
+			suite.Fail("unexpected cursor")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+
+	defer mockServer.Close()
+
+	schemaBody, _ := json.Marshal(HTTPSchema{
+		Request: HTTPRequest{
+			Method: http.MethodGet,
+			URL:    mockServer.URL + "/items",
+		},
+		Pagination: &PaginationConfig{
+			Mode:        PaginationModeCursor,
+			ItemsPath:   "items",
+			CursorParam: "after",
+			CursorPath:  "$.meta.next",
+		},
+	})
+
+	response, err := suite.newExecutor().Do(yctx.NewContext(context.Background()), string(schemaBody), nil, nil)
+	suite.NoError(err)
+	suite.Equal([]any{float64(1), float64(2), float64(3)}, response)
+}
+
+func (suite *ExecutorTestSuite) TestDo_WithLinkPaginationAndMaxPages() {
+	var requests int
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		w.Header().Set("Link", `</items?page=`+strconv.Itoa(requests+1)+`>; rel="next", </items?page=99>; rel="last"`)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]any{r.URL.Query().Get("page")})
+	}))
+
+	defer mockServer.Close()
+
+	schemaBody, _ := json.Marshal(HTTPSchema{
+		Request: HTTPRequest{
+			Method:      http.MethodGet,
+			URL:         mockServer.URL + "/items",
+			QueryParams: map[string]string{"page": "1"},
+		},
+		Pagination: &PaginationConfig{
+			Mode:     PaginationModeLink,
+			MaxPages: 3,
+		},
+	})
+
+	_, err := suite.newExecutor().Do(yctx.NewContext(context.Background()), string(schemaBody), nil, nil)
+	suite.ErrorIs(err, ErrMaxPagesReached)
+	suite.Equal(3, requests)
+}
+
+func (suite *ExecutorTestSuite) TestDo_WithPagePaginationAndExactlyMaxPages() {
+	var (
+		requests int
+		pages    = 3
+	)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		items := []any{}
+		if page <= pages {
+			items = append(items, page)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(items)
+	}))
+
+	defer mockServer.Close()
+
+	schemaBody, _ := json.Marshal(HTTPSchema{
+		Request: HTTPRequest{Method: http.MethodGet, URL: mockServer.URL + "/items"},
+		Pagination: &PaginationConfig{
+			Mode:     PaginationModePage,
+			MaxPages: 3,
+		},
+	})
+
+	response, err := suite.newExecutor().Do(yctx.NewContext(context.Background()), string(schemaBody), nil, nil)
+	suite.NoError(err)
+	suite.Equal([]any{float64(1), float64(2), float64(3)}, response)
+	suite.Equal(4, requests)
+
+	// with one more page, the empty page is not found within maxPages.
+	pages, requests = 4, 0
+
+	_, err = suite.newExecutor().Do(yctx.NewContext(context.Background()), string(schemaBody), nil, nil)
+	suite.ErrorIs(err, ErrMaxPagesReached)
+	suite.Equal(4, requests)
+}
+
+func (suite *ExecutorTestSuite) TestDo_WithLinkPaginationToOtherHost() {
+	otherServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.Empty(r.Header.Get("Authorization"))
+		suite.Empty(r.Header.Get("X-Api-Key"))
+		suite.Equal("application/json", r.Header.Get("Accept"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]any{"b"})
+	}))
+
+	defer otherServer.Close()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.Equal("Bearer token", r.Header.Get("Authorization"))
+
+		w.Header().Set("Link", `<`+otherServer.URL+`/items?page=2>; rel="next"`)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]any{"a"})
+	}))
+
+	defer mockServer.Close()
+
+	schemaBody, _ := json.Marshal(HTTPSchema{
+		Request: HTTPRequest{
+			Method: http.MethodGet,
+			URL:    mockServer.URL + "/items",
+			Headers: map[string]string{
+				"Authorization": "Bearer token",
+				"X-Api-Key":     "key",
+				"Accept":        "application/json",
+			},
+		},
+		Pagination: &PaginationConfig{Mode: PaginationModeLink},
+	})
+
+	response, err := suite.newExecutor().Do(yctx.NewContext(context.Background()), string(schemaBody), nil, nil)
+	suite.NoError(err)
+	suite.Equal([]any{"a", "b"}, response)
+}
+
+func (suite *ExecutorTestSuite) TestDo_WithInvalidPaginationMode() {
+	schemaBody := `{"request": {"method": "GET", "url": "http://localhost/items"}, "pagination": {"mode": "unknown"}}`
+
+	_, err := suite.newExecutor().Do(yctx.NewContext(context.Background()), schemaBody, nil, nil)
+	suite.Error(err)
+}
+
+func (suite *ExecutorTestSuite) TestDo_WithTLSCACert() {
+	mockServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"message": "success"})
+	}))
+
+	defer mockServer.Close()
+
+	var (
+		ctx      = yctx.NewContext(context.Background())
+		executor = suite.newExecutor()
+		caCert   = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: mockServer.Certificate().Raw})
+		request  = HTTPRequest{Method: http.MethodGet, URL: mockServer.URL + "/data"}
+	)
+
+	withoutCA, _ := json.Marshal(HTTPSchema{Request: request})
+	_, err := executor.Do(ctx, string(withoutCA), nil, nil)
+	suite.Error(err)
+
+	withCA, _ := json.Marshal(HTTPSchema{Request: request, TLS: &TLSConfig{CACert: string(caCert)}})
+	response, err := executor.Do(ctx, string(withCA), nil, nil)
+	suite.NoError(err)
+	suite.Equal(map[string]any{"message": "success"}, response)
+
+	insecure, _ := json.Marshal(HTTPSchema{Request: request, TLS: &TLSConfig{InsecureSkipVerify: true}})
+	_, err = executor.Do(ctx, string(insecure), nil, nil)
+	suite.NoError(err)
+}
+
+func (suite *ExecutorTestSuite) TestDo_WithProxy() {
+	var proxiedHost string
+
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.Host
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"proxied": true})
+	}))
+
+	defer proxyServer.Close()
+
+	schemaBody, _ := json.Marshal(HTTPSchema{
+		Request: HTTPRequest{Method: http.MethodGet, URL: "http://localhost/data"},
+		Proxy:   proxyServer.URL,
+	})
+
+	_, err := suite.newExecutor().Do(yctx.NewContext(context.Background()), string(schemaBody), nil, nil)
+	suite.ErrorIs(err, ErrEgressDenied)
+	suite.Empty(proxiedHost)
+
+	executor := suite.newExecutor()
+	executor.policy.AllowedProxies = []string{proxyServer.URL}
+
+	response, err := executor.Do(yctx.NewContext(context.Background()), string(schemaBody), nil, nil)
+	suite.NoError(err)
+	suite.Equal(map[string]any{"proxied": true}, response)
+	suite.Equal("localhost", proxiedHost)
+}
+
+func (suite *ExecutorTestSuite) TestDo_EgressDeniedThroughProxy() {
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.Fail("request should not reach the proxy")
+	}))
+
+	defer proxyServer.Close()
+
+	policy := DefaultEgressPolicy()
+	policy.AllowedProxies = []string{proxyServer.URL}
+
+	for _, target := range []string{"http://169.254.169.254/latest/meta-data", "http://127.0.0.1:8080/admin"} {
+		schemaBody, _ := json.Marshal(HTTPSchema{
+			Request: HTTPRequest{Method: http.MethodGet, URL: target},
+			Proxy:   proxyServer.URL,
+		})
+
+		_, err := NewExecutorWithEgressPolicy(policy).Do(yctx.NewContext(context.Background()), string(schemaBody), nil, nil)
+		suite.ErrorIs(err, ErrEgressDenied, target)
+	}
+}
+
+func (suite *ExecutorTestSuite) TestClient_IgnoresEnvironmentProxy() {
+	suite.T().Setenv("HTTP_PROXY", "http://proxy.internal:3128")
+	suite.T().Setenv("HTTPS_PROXY", "http://proxy.internal:3128")
+
+	client, err := suite.newExecutor().client(nil, "")
+	suite.Require().NoError(err)
+
+	transport := client.Transport.(*policyTransport).transport.(*http.Transport)
+	suite.Nil(transport.Proxy)
+}
+
+func (suite *ExecutorTestSuite) TestClient_ReusedByConfiguration() {
+	executor := suite.newExecutor()
+
+	first, err := executor.client(&TLSConfig{ServerName: "a.internal"}, "")
+	suite.NoError(err)
+
+	second, err := executor.client(&TLSConfig{ServerName: "a.internal"}, "")
+	suite.NoError(err)
+
+	third, err := executor.client(&TLSConfig{ServerName: "b.internal"}, "")
+	suite.NoError(err)
+
+	suite.Same(first, second)
+	suite.NotSame(first, third)
+
+	_, err = executor.client(&TLSConfig{CACert: "invalid"}, "")
+	suite.Error(err)
+}
+
+func (suite *ExecutorTestSuite) TestClient_EvictsLeastRecentlyUsed() {
+	executor := suite.newExecutor()
+
+	first, err := executor.client(&TLSConfig{ServerName: "0.internal"}, "")
+	suite.NoError(err)
+
+	for i := 1; i <= maxClients; i++ {
+		_, err = executor.client(&TLSConfig{ServerName: strconv.Itoa(i) + ".internal"}, "")
+		suite.NoError(err)
+	}
+
+	suite.Equal(maxClients, executor.lru.Len())
+	suite.Len(executor.clients, maxClients)
+
+	again, err := executor.client(&TLSConfig{ServerName: "0.internal"}, "")
+	suite.NoError(err)
+	suite.NotSame(first, again)
+
+	for element := executor.lru.Front().Next(); element != nil; element = element.Next() {
+		element.Value.(*cachedClient).lastUsed = time.Now().Add(-clientIdleTimeout)
+	}
+
+	recent, err := executor.client(&TLSConfig{ServerName: "0.internal"}, "")
+	suite.NoError(err)
+	suite.Same(again, recent)
+	suite.Equal(1, executor.lru.Len())
+	suite.Len(executor.clients, 1)
+}
+
+func (suite *ExecutorTestSuite) TestDo_EgressDeniedForMetadataEndpoint() {
+	schemaBody, _ := json.Marshal(HTTPSchema{
+		Request: HTTPRequest{Method: http.MethodGet, URL: "http://169.254.169.254/latest/meta-data"},
+	})
+
+	_, err := NewExecutorWithEgressPolicy(DefaultEgressPolicy()).Do(yctx.NewContext(context.Background()), string(schemaBody), nil, nil)
+	suite.ErrorIs(err, ErrEgressDenied)
+
+	var egressErr *EgressError
+	suite.ErrorAs(err, &egressErr)
+}
+
+func (suite *ExecutorTestSuite) TestDo_EgressDeniedForPrivateRanges() {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.Fail("request should not reach the server")
+	}))
+
+	defer mockServer.Close()
+
+	policy, err := EgressPolicyFromEnv()
+	suite.NoError(err)
+
+	schemaBody, _ := json.Marshal(HTTPSchema{
+		Request: HTTPRequest{Method: http.MethodGet, URL: mockServer.URL + "/data"},
+	})
+
+	_, err = NewExecutorWithEgressPolicy(policy).Do(yctx.NewContext(context.Background()), string(schemaBody), nil, nil)
+	suite.ErrorIs(err, ErrEgressDenied)
+
+	suite.T().Setenv(EnvEgressExemptCIDRs, "127.0.0.1/32")
+
+	policy, err = EgressPolicyFromEnv()
+	suite.NoError(err)
+	suite.NoError(policy.CheckAddress("127.0.0.1:8080"))
+	suite.ErrorIs(policy.CheckAddress("10.0.0.1:8080"), ErrEgressDenied)
+
+	suite.T().Setenv(EnvEgressAllowPrivate, "true")
+
+	policy, err = EgressPolicyFromEnv()
+	suite.NoError(err)
+	suite.NoError(policy.CheckAddress("10.0.0.1:8080"))
+	suite.ErrorIs(policy.CheckAddress("169.254.169.254:80"), ErrEgressDenied)
+}
+
+func (suite *ExecutorTestSuite) TestDo_EgressDeniedAfterRedirect() {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "http://localhost:"+r.URL.Port()+"/data", http.StatusFound)
+		default:
+			suite.Fail("redirect target should not be reached")
+		}
+	}))
+
+	defer mockServer.Close()
+
+	executor := suite.newExecutor()
+	executor.policy.AllowedHosts = []string{"127.0.0.1"}
+
+	schemaBody, _ := json.Marshal(HTTPSchema{
+		Request: HTTPRequest{Method: http.MethodGet, URL: mockServer.URL + "/redirect"},
+	})
+
+	_, err := executor.Do(yctx.NewContext(context.Background()), string(schemaBody), nil, nil)
+	suite.ErrorIs(err, ErrEgressDenied)
+
+	var egressErr *EgressError
+	suite.Require().ErrorAs(err, &egressErr)
+	suite.Contains(egressErr.Reason, "host localhost")
+}
+
+func (suite *ExecutorTestSuite) TestEgressPolicy_CheckRequest() {
+	policy := DefaultEgressPolicy()
+	policy.AllowedHosts = []string{"api.example.com", "*.internal"}
+	policy.AllowedPorts = []int{443}
+
+	for rawURL, allowed := range map[string]bool{
+		"https://api.example.com/v1":     true,
+		"https://billing.internal/v1":    true,
+		"https://internal/v1":            false,
+		"http://api.example.com/v1":      false,
+		"https://api.example.com:8443/":  false,
+		"ftp://api.example.com/resource": false,
+		"https://other.example.com/v1":   false,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, rawURL, nil)
+
+		if allowed {
+			suite.NoError(policy.CheckRequest(req), rawURL)
+		} else {
+			suite.ErrorIs(policy.CheckRequest(req), ErrEgressDenied, rawURL)
+		}
+	}
+}
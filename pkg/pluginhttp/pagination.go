@@ -0,0 +1,258 @@
+package pluginhttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/yrn-go/yrn/pkg/plugincore"
+	"github.com/yrn-go/yrn/pkg/yctx"
+	"github.com/yrn-go/yrn/pkg/yredact"
+)
+
+const (
+	defaultMaxPages    = 100
+	defaultStartPage   = 1
+	defaultPageParam   = "page"
+	defaultOffsetParam = "offset"
+	defaultCursorParam = "cursor"
+	defaultSizeParam   = "limit"
+)
+
+// ErrMaxPagesReached is returned when there are still pages to fetch after
+// maxPages, instead of returning the items of the first pages only. In page
+// and offset mode a page after maxPages is fetched to check it.
+var ErrMaxPagesReached = errors.New("pagination: max pages reached")
+
+// credentialHeaders matches the headers that are not sent to a page hosted
+// by another host than the one of the request.
+var credentialHeaders = yredact.NewRedactor(append([]string{"auth"}, yredact.DefaultFieldPatterns...))
+
+// paginate walks every page of the request according to the pagination mode
+// and returns the items of all pages concatenated in a single array.
+func (e *Executor) paginate(ctx *yctx.Context, client *http.Client, request *HTTPRequest, pagination *PaginationConfig) (output any, err error) {
+	var (
+		items    = make([]any, 0)
+		maxPages = pagination.MaxPages
+		page     = defaultStartPage
+		offset   int
+		cursor   string
+		nextURL  string
+	)
+
+	if err = validatePagination(pagination); err != nil {
+		return
+	}
+
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	if pagination.StartPage != nil {
+		page = *pagination.StartPage
+	}
+
+	for fetched := 0; ; fetched++ {
+		// in page and offset mode the end is only found on the next page, so
+		// one more page is fetched after maxPages: a source with exactly
+		// maxPages pages returns its items instead of ErrMaxPagesReached.
+		probe := fetched == maxPages
+		if probe && pagination.Mode != PaginationModePage && pagination.Mode != PaginationModeOffset {
+			return nil, fmt.Errorf("%w: %d", ErrMaxPagesReached, maxPages)
+		}
+
+		var (
+			query      = url.Values{}
+			requestURL = nextURL
+			body       any
+			header     http.Header
+			pageItems  []any
+		)
+
+		if pagination.Size > 0 && pagination.Mode != PaginationModeLink {
+			query.Set(orDefault(pagination.SizeParam, defaultSizeParam), strconv.Itoa(pagination.Size))
+		}
+
+		switch pagination.Mode {
+		case PaginationModePage:
+			query.Set(orDefault(pagination.PageParam, defaultPageParam), strconv.Itoa(page))
+		case PaginationModeOffset:
+			query.Set(orDefault(pagination.OffsetParam, defaultOffsetParam), strconv.Itoa(offset))
+		case PaginationModeCursor:
+			if cursor != "" {
+				query.Set(orDefault(pagination.CursorParam, defaultCursorParam), cursor)
+			}
+		}
+
+		if requestURL == "" {
+			requestURL, err = buildURL(request.URL, request.QueryParams, query)
+			if err != nil {
+				return
+			}
+		}
+
+		pageRequest := request
+		if !sameHost(request.URL, requestURL) {
+			pageRequest = withoutCredentials(request)
+		}
+
+		body, header, err = e.send(ctx, client, pageRequest, requestURL)
+		if err != nil {
+			return
+		}
+
+		pageItems, err = extractItems(body, pagination.ItemsPath)
+		if err != nil {
+			return
+		}
+
+		if probe {
+			if len(pageItems) == 0 {
+				return items, nil
+			}
+
+			return nil, fmt.Errorf("%w: %d", ErrMaxPagesReached, maxPages)
+		}
+
+		items = append(items, pageItems...)
+
+		switch pagination.Mode {
+		case PaginationModePage, PaginationModeOffset:
+			if len(pageItems) == 0 || (pagination.Size > 0 && len(pageItems) < pagination.Size) {
+				return items, nil
+			}
+
+			page++
+			offset += len(pageItems)
+		case PaginationModeCursor:
+			value, _ := plugincore.LookupPath(body, pagination.CursorPath)
+			if value == nil || len(pageItems) == 0 {
+				return items, nil
+			}
+
+			cursor = fmt.Sprint(value)
+			if cursor == "" {
+				return items, nil
+			}
+		case PaginationModeLink:
+			nextURL, err = nextLink(header, requestURL)
+			if err != nil || nextURL == "" {
+				return items, err
+			}
+		}
+	}
+}
+
+// sameHost reports whether both URLs point to the same host and port.
+func sameHost(rawURL, otherURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	other, err := url.Parse(otherURL)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(parsed.Host, other.Host)
+}
+
+// withoutCredentials returns a copy of the request without the
+// authorization, cookie and other credential headers.
+func withoutCredentials(request *HTTPRequest) *HTTPRequest {
+	copied := *request
+	copied.Headers = make(map[string]string, len(request.Headers))
+
+	for name, value := range request.Headers {
+		if !credentialHeaders.IsSensitiveField(name) {
+			copied.Headers[name] = value
+		}
+	}
+
+	return &copied
+}
+
+func validatePagination(pagination *PaginationConfig) error {
+	switch pagination.Mode {
+	case PaginationModePage, PaginationModeOffset, PaginationModeLink:
+		return nil
+	case PaginationModeCursor:
+		if pagination.CursorPath == "" {
+			return fmt.Errorf("pagination: cursorPath is required for mode %q", pagination.Mode)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("pagination: invalid mode %q", pagination.Mode)
+	}
+}
+
+// extractItems returns the array found at itemsPath, or the body itself when
+// no path is configured.
+func extractItems(body any, itemsPath string) ([]any, error) {
+	value, ok := plugincore.LookupPath(body, itemsPath)
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("pagination: value at %q is not an array", itemsPath)
+	}
+
+	return items, nil
+}
+
+// nextLink returns the absolute URL of the rel="next" entry of the Link
+// header, resolved against the URL of the current request.
+func nextLink(header http.Header, currentURL string) (string, error) {
+	for _, link := range header.Values("Link") {
+		for _, entry := range strings.Split(link, ",") {
+			parts := strings.Split(entry, ";")
+			target := strings.TrimSpace(parts[0])
+
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+
+			for _, param := range parts[1:] {
+				name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+				if !found || !strings.EqualFold(name, "rel") {
+					continue
+				}
+
+				for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+					if !strings.EqualFold(rel, "next") {
+						continue
+					}
+
+					base, err := url.Parse(currentURL)
+					if err != nil {
+						return "", err
+					}
+
+					next, err := base.Parse(strings.Trim(target, "<>"))
+					if err != nil {
+						return "", err
+					}
+
+					return next.String(), nil
+				}
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func orDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
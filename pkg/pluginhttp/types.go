@@ -1,8 +1,18 @@
 package pluginhttp
 
+const (
+	PaginationModePage   = "page"
+	PaginationModeOffset = "offset"
+	PaginationModeCursor = "cursor"
+	PaginationModeLink   = "link"
+)
+
 type HTTPSchema struct {
-	Request HTTPRequest  `json:"request"`
-	Retry   *RetryConfig `json:"retry,omitempty"`
+	Request    HTTPRequest       `json:"request"`
+	Retry      *RetryConfig      `json:"retry,omitempty"`
+	Pagination *PaginationConfig `json:"pagination,omitempty"`
+	TLS        *TLSConfig        `json:"tls,omitempty"`
+	Proxy      string            `json:"proxy,omitempty"`
 }
 
 type HTTPRequest struct {
@@ -18,3 +28,24 @@ type RetryConfig struct {
 	MaxAttempts int `json:"maxAttempts"`
 	Delay       int `json:"delay"` // milliseconds
 }
+
+type PaginationConfig struct {
+	Mode        string `json:"mode"`                  // page, offset, cursor, link
+	ItemsPath   string `json:"itemsPath,omitempty"`   // path to the items array in the response body
+	PageParam   string `json:"pageParam,omitempty"`   // page mode
+	StartPage   *int   `json:"startPage,omitempty"`   // page mode, defaults to 1
+	OffsetParam string `json:"offsetParam,omitempty"` // offset mode
+	SizeParam   string `json:"sizeParam,omitempty"`   // page and offset modes
+	Size        int    `json:"size,omitempty"`        // page and offset modes
+	CursorParam string `json:"cursorParam,omitempty"` // cursor mode
+	CursorPath  string `json:"cursorPath,omitempty"`  // cursor mode, path to the next cursor in the response body
+	MaxPages    int    `json:"maxPages,omitempty"`
+}
+
+type TLSConfig struct {
+	CACert             string `json:"caCert,omitempty"`     // PEM encoded CA bundle
+	ClientCert         string `json:"clientCert,omitempty"` // PEM encoded client certificate
+	ClientKey          string `json:"clientKey,omitempty"`  // PEM encoded client private key
+	ServerName         string `json:"serverName,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"` // development only
+}
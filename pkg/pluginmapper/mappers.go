@@ -5,8 +5,13 @@ import (
 	"github.com/yrn-go/yrn/pkg/pluginhttp"
 )
 
-var (
-	mappers = map[string]flowmanager.PluginExecutor{
-		pluginhttp.SlugHttp: pluginhttp.NewExecutor(),
+func newMappers() (map[string]flowmanager.PluginExecutor, error) {
+	httpExecutor, err := pluginhttp.NewExecutor()
+	if err != nil {
+		return nil, err
 	}
-)
+
+	return map[string]flowmanager.PluginExecutor{
+		pluginhttp.SlugHttp: httpExecutor,
+	}, nil
+}
@@ -10,16 +10,23 @@ var (
 	_ flowmanager.PluginManager = (*PluginManagerLocal)(nil)
 )
 
-type PluginManagerLocal struct{}
+type PluginManagerLocal struct {
+	mappers map[string]flowmanager.PluginExecutor
+}
+
+func NewPluginManagerLocal() (*PluginManagerLocal, error) {
+	mappers, err := newMappers()
+	if err != nil {
+		return nil, err
+	}
 
-func NewPluginManagerLocal() *PluginManagerLocal {
-	return &PluginManagerLocal{}
+	return &PluginManagerLocal{mappers: mappers}, nil
 }
 
 func (p *PluginManagerLocal) GetBySlug(ctx *yctx.Context, slug string) (plugin flowmanager.PluginExecutor, err error) {
 	var ok bool
 
-	if plugin, ok = mappers[slug]; ok {
+	if plugin, ok = p.mappers[slug]; ok {
 		return plugin, nil
 	}
 
@@ -1,15 +1,222 @@
 package yctx
 
-import "context"
+import (
+	"context"
+	"maps"
+	"sync"
 
-type Context struct {
-	ctx context.Context
-}
+	"golang.org/x/exp/slog"
+)
+
+type (
+	Context struct {
+		ctx context.Context
+	}
+
+	// Caller is the authenticated identity of a request. Roles are checked
+	// by the API before the request reaches the services.
+	Caller struct {
+		Id     string   `json:"id"`
+		Tenant string   `json:"tenant"`
+		Roles  []string `json:"roles,omitempty"`
+	}
+
+	// Values is a key-value store shared by every context derived from the
+	// one created by WithValues. It is safe for concurrent use, and a nil
+	// Values discards what is set.
+	Values struct {
+		mu     sync.RWMutex
+		values map[string]any
+	}
+
+	tenantKey     struct{}
+	allTenantsKey struct{}
+	callerKey     struct{}
+	flowKey       struct{}
+	executionKey  struct{}
+	pluginKey     struct{}
+	attemptKey    struct{}
+	loggerKey     struct{}
+	valuesKey     struct{}
+)
 
 func (c *Context) Context() context.Context {
 	return c.ctx
 }
 
+// AllTenants is the tenant of the callers that access every tenant.
+const AllTenants = "*"
+
+// Tenant is empty when the context is not scoped to a tenant.
+func (c *Context) Tenant() string {
+	return value[string](c, tenantKey{})
+}
+
+// WithTenant returns a context scoped to tenant, which drops the access to
+// every tenant given by WithAllTenants. It is kept in the wrapped
+// context.Context, so contexts derived from Context() keep the tenant.
+func (c *Context) WithTenant(tenant string) *Context {
+	return c.with(tenantKey{}, tenant).with(allTenantsKey{}, false)
+}
+
+// HasAllTenants reports whether the context was created by WithAllTenants.
+func (c *Context) HasAllTenants() bool {
+	return value[bool](c, allTenantsKey{})
+}
+
+// WithAllTenants returns a context that accesses the resources of every
+// tenant, for the system and the admins whose tenant is AllTenants.
+func (c *Context) WithAllTenants() *Context {
+	return c.with(tenantKey{}, "").with(allTenantsKey{}, true)
+}
+
+// CanAccess reports whether the resources of tenant are visible in the
+// context. Contexts without tenant only see the resources without tenant,
+// unless they were created by WithAllTenants.
+func (c *Context) CanAccess(tenant string) bool {
+	return c.HasAllTenants() || c.Tenant() == tenant
+}
+
+// Caller is nil when the request was not authenticated.
+func (c *Context) Caller() *Caller {
+	return value[*Caller](c, callerKey{})
+}
+
+// WithCaller returns a context with the caller, scoped to its tenant. The
+// callers whose tenant is AllTenants access every tenant.
+func (c *Context) WithCaller(caller *Caller) *Context {
+	if caller.Tenant == AllTenants {
+		return c.with(callerKey{}, caller).WithAllTenants()
+	}
+
+	return c.with(callerKey{}, caller).WithTenant(caller.Tenant)
+}
+
+// FlowId is empty outside the execution of a flow.
+func (c *Context) FlowId() string {
+	return value[string](c, flowKey{})
+}
+
+// ExecutionId identifies one execution of the flow, shared by all its
+// plugins.
+func (c *Context) ExecutionId() string {
+	return value[string](c, executionKey{})
+}
+
+// WithExecution returns the context of the execution executionId of flowId.
+func (c *Context) WithExecution(flowId, executionId string) *Context {
+	return c.with(flowKey{}, flowId).with(executionKey{}, executionId)
+}
+
+// PluginId is empty outside the execution of a plugin.
+func (c *Context) PluginId() string {
+	return value[string](c, pluginKey{})
+}
+
+// Attempt counts the events delivered to a plugin in one execution of the
+// flow, starting at 1, and is 0 outside of them.
+func (c *Context) Attempt() int {
+	return value[int](c, attemptKey{})
+}
+
+// WithPlugin returns the context of the attempt of the plugin pluginId.
+func (c *Context) WithPlugin(pluginId string, attempt int) *Context {
+	return c.with(pluginKey{}, pluginId).with(attemptKey{}, attempt)
+}
+
+// Logger returns the logger of the context, slog.Default when none was set
+// with WithLogger, with the tenant, flow, execution, plugin and attempt of
+// the context as attributes.
+func (c *Context) Logger() *slog.Logger {
+	logger := value[*slog.Logger](c, loggerKey{})
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	var attrs []any
+	for _, attr := range []slog.Attr{
+		slog.String("tenant", c.Tenant()),
+		slog.String("flow_id", c.FlowId()),
+		slog.String("execution_id", c.ExecutionId()),
+		slog.String("plugin_id", c.PluginId()),
+	} {
+		if attr.Value.String() != "" {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	if attempt := c.Attempt(); attempt > 0 {
+		attrs = append(attrs, slog.Int("attempt", attempt))
+	}
+
+	return logger.With(attrs...)
+}
+
+// WithLogger returns a context that logs with logger.
+func (c *Context) WithLogger(logger *slog.Logger) *Context {
+	return c.with(loggerKey{}, logger)
+}
+
+// Values returns the store of the context, nil when it has none.
+func (c *Context) Values() *Values {
+	return value[*Values](c, valuesKey{})
+}
+
+// WithValues returns a context with a new empty store.
+func (c *Context) WithValues() *Context {
+	return c.with(valuesKey{}, &Values{values: make(map[string]any)})
+}
+
+func (v *Values) Set(key string, value any) {
+	if v == nil {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.values[key] = value
+}
+
+func (v *Values) Get(key string) (value any, ok bool) {
+	if v == nil {
+		return nil, false
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	value, ok = v.values[key]
+
+	return
+}
+
+// All returns a copy of the values, nil when there are none.
+func (v *Values) All() map[string]any {
+	if v == nil {
+		return nil
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if len(v.values) == 0 {
+		return nil
+	}
+
+	return maps.Clone(v.values)
+}
+
+func (c *Context) with(key, value any) *Context {
+	return NewContext(context.WithValue(c.ctx, key, value))
+}
+
+func value[T any](c *Context, key any) T {
+	value, _ := c.ctx.Value(key).(T)
+
+	return value
+}
+
 func NewContext(ctx context.Context) *Context {
 	return &Context{
 		ctx: ctx,
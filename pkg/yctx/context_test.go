@@ -0,0 +1,146 @@
+package yctx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/exp/slog"
+)
+
+func TestContext(t *testing.T) {
+	suite.Run(t, new(ContextTestSuite))
+}
+
+type ContextTestSuite struct {
+	suite.Suite
+	ctx *Context
+}
+
+func (s *ContextTestSuite) SetupTest() {
+	s.ctx = NewContext(context.Background())
+}
+
+func (s *ContextTestSuite) TestWithTenant_SurvivesNewContext() {
+	ctx := s.ctx.WithTenant("tenant-a")
+	s.Empty(s.ctx.Tenant())
+
+	wrapped := NewContext(context.WithoutCancel(ctx.Context()))
+	s.Equal("tenant-a", wrapped.Tenant())
+	s.True(wrapped.CanAccess("tenant-a"))
+	s.False(wrapped.CanAccess("tenant-b"))
+}
+
+func (s *ContextTestSuite) TestCanAccess_WithoutTenant() {
+	s.False(s.ctx.CanAccess("tenant-a"))
+	s.True(s.ctx.CanAccess(""))
+}
+
+func (s *ContextTestSuite) TestWithAllTenants() {
+	ctx := s.ctx.WithAllTenants()
+	s.True(ctx.HasAllTenants())
+	s.True(ctx.CanAccess("tenant-a"))
+	s.True(ctx.CanAccess(""))
+
+	// scoping to a tenant drops the access to the others.
+	scoped := NewContext(ctx.Context()).WithTenant("tenant-a")
+	s.False(scoped.HasAllTenants())
+	s.False(scoped.CanAccess("tenant-b"))
+}
+
+func (s *ContextTestSuite) TestWithCaller_ScopesToCallerTenant() {
+	s.Nil(s.ctx.Caller())
+
+	caller := &Caller{Id: "alice", Tenant: "tenant-a"}
+	ctx := s.ctx.WithCaller(caller)
+	s.Equal(caller, ctx.Caller())
+	s.Equal("tenant-a", ctx.Tenant())
+	s.False(ctx.HasAllTenants())
+
+	admin := s.ctx.WithCaller(&Caller{Id: "root", Tenant: AllTenants, Roles: []string{"admin"}})
+	s.Empty(admin.Tenant())
+	s.True(admin.CanAccess("tenant-b"))
+}
+
+func (s *ContextTestSuite) TestExecutionMetadata() {
+	s.Empty(s.ctx.FlowId())
+	s.Empty(s.ctx.ExecutionId())
+	s.Empty(s.ctx.PluginId())
+	s.Zero(s.ctx.Attempt())
+
+	ctx := s.ctx.WithExecution("flow-1", "execution-1").WithPlugin("http", 2)
+
+	wrapped := NewContext(ctx.Context())
+	s.Equal("flow-1", wrapped.FlowId())
+	s.Equal("execution-1", wrapped.ExecutionId())
+	s.Equal("http", wrapped.PluginId())
+	s.Equal(2, wrapped.Attempt())
+}
+
+func (s *ContextTestSuite) TestLogger_IsScopedToTheContext() {
+	var output bytes.Buffer
+
+	ctx := s.ctx.
+		WithLogger(slog.New(slog.NewJSONHandler(&output, nil))).
+		WithTenant("tenant-a").
+		WithExecution("flow-1", "execution-1").
+		WithPlugin("http", 1)
+
+	ctx.Logger().Info("request sent", slog.Int("status", 200))
+
+	var record map[string]any
+	s.Require().NoError(json.Unmarshal(output.Bytes(), &record))
+	s.Equal("request sent", record["msg"])
+	s.Equal("tenant-a", record["tenant"])
+	s.Equal("flow-1", record["flow_id"])
+	s.Equal("execution-1", record["execution_id"])
+	s.Equal("http", record["plugin_id"])
+	s.Equal(float64(1), record["attempt"])
+	s.Equal(float64(200), record["status"])
+}
+
+func (s *ContextTestSuite) TestLogger_OmitsMissingMetadata() {
+	var output bytes.Buffer
+
+	s.ctx.WithLogger(slog.New(slog.NewJSONHandler(&output, nil))).Logger().Info("started")
+
+	var record map[string]any
+	s.Require().NoError(json.Unmarshal(output.Bytes(), &record))
+	s.NotContains(record, "tenant")
+	s.NotContains(record, "plugin_id")
+	s.NotContains(record, "attempt")
+	s.NotNil(s.ctx.Logger())
+}
+
+func (s *ContextTestSuite) TestValues_AreSharedByDerivedContexts() {
+	s.Nil(s.ctx.Values())
+	s.ctx.Values().Set("ignored", true)
+	s.Nil(s.ctx.Values().All())
+
+	ctx := s.ctx.WithValues()
+	s.Nil(ctx.Values().All())
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"status", "retries"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			NewContext(ctx.WithTenant("tenant-a").Context()).Values().Set(key, 1)
+		}()
+	}
+	wg.Wait()
+
+	value, ok := ctx.Values().Get("status")
+	s.True(ok)
+	s.Equal(1, value)
+
+	all := ctx.Values().All()
+	s.Equal(map[string]any{"status": 1, "retries": 1}, all)
+
+	all["status"] = 2
+	value, _ = ctx.Values().Get("status")
+	s.Equal(1, value)
+}
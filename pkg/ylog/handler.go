@@ -0,0 +1,79 @@
+package ylog
+
+import (
+	"context"
+
+	"github.com/yrn-go/yrn/pkg/yredact"
+	"golang.org/x/exp/slog"
+)
+
+// RedactingHandler hides sensitive attributes before passing records to the
+// wrapped handler.
+type RedactingHandler struct {
+	handler  slog.Handler
+	redactor *yredact.Redactor
+}
+
+func NewRedactingHandler(handler slog.Handler, redactor *yredact.Redactor) *RedactingHandler {
+	return &RedactingHandler{
+		handler:  handler,
+		redactor: redactor,
+	}
+}
+
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *RedactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, h.redactor.RedactString(record.Message), record.PC)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(attr))
+		return true
+	})
+
+	return h.handler.Handle(ctx, redacted)
+}
+
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		redacted = append(redacted, h.redactAttr(attr))
+	}
+
+	return NewRedactingHandler(h.handler.WithAttrs(redacted), h.redactor)
+}
+
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return NewRedactingHandler(h.handler.WithGroup(name), h.redactor)
+}
+
+func (h *RedactingHandler) redactAttr(attr slog.Attr) slog.Attr {
+	if h.redactor.IsSensitiveField(attr.Key) {
+		return slog.String(attr.Key, yredact.Placeholder)
+	}
+
+	value := attr.Value.Resolve()
+
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, h.redactor.RedactString(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		attrs := make([]any, 0, len(group))
+		for _, item := range group {
+			attrs = append(attrs, h.redactAttr(item))
+		}
+
+		return slog.Group(attr.Key, attrs...)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return slog.String(attr.Key, h.redactor.RedactString(err.Error()))
+		}
+
+		return slog.Any(attr.Key, h.redactor.Redact(value.Any()))
+	default:
+		return slog.Attr{Key: attr.Key, Value: value}
+	}
+}
@@ -0,0 +1,61 @@
+package ylog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/yrn-go/yrn/pkg/yredact"
+	"golang.org/x/exp/slog"
+)
+
+func TestRedactingHandler(t *testing.T) {
+	suite.Run(t, new(RedactingHandlerTestSuite))
+}
+
+type RedactingHandlerTestSuite struct {
+	suite.Suite
+	output *bytes.Buffer
+	logger *slog.Logger
+}
+
+func (s *RedactingHandlerTestSuite) SetupTest() {
+	redactor := yredact.NewRedactor(yredact.DefaultFieldPatterns)
+	redactor.AddValues("s3cr3t-value")
+
+	s.output = &bytes.Buffer{}
+	s.logger = slog.New(NewRedactingHandler(slog.NewJSONHandler(s.output, nil), redactor))
+}
+
+func (s *RedactingHandlerTestSuite) record() (record map[string]any) {
+	s.Require().NoError(json.Unmarshal(s.output.Bytes(), &record))
+	return
+}
+
+func (s *RedactingHandlerTestSuite) TestHandle_RedactsAttrs() {
+	s.logger.Info("calling s3cr3t-value",
+		slog.String("password", "123456"),
+		slog.String("url", "https://api.example.com/?key=s3cr3t-value"),
+		slog.Any("error", errors.New("unauthorized: s3cr3t-value")),
+		slog.Any("headers", map[string]any{"Authorization": "Bearer x", "Accept": "*/*"}),
+		slog.Group("request", slog.String("token", "abc"), slog.Int("attempt", 1)),
+	)
+
+	record := s.record()
+	s.Equal("calling "+yredact.Placeholder, record["msg"])
+	s.Equal(yredact.Placeholder, record["password"])
+	s.Equal("https://api.example.com/?key="+yredact.Placeholder, record["url"])
+	s.Equal("unauthorized: "+yredact.Placeholder, record["error"])
+	s.Equal(map[string]any{"Authorization": yredact.Placeholder, "Accept": "*/*"}, record["headers"])
+	s.Equal(map[string]any{"token": yredact.Placeholder, "attempt": float64(1)}, record["request"])
+}
+
+func (s *RedactingHandlerTestSuite) TestWithAttrs_RedactsAttrs() {
+	s.logger.With(slog.String("api_key", "abc")).WithGroup("plugin").Info("done", slog.String("id", "http"))
+
+	record := s.record()
+	s.Equal(yredact.Placeholder, record["api_key"])
+	s.Equal(map[string]any{"id": "http"}, record["plugin"])
+}
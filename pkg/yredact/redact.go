@@ -0,0 +1,271 @@
+package yredact
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// Placeholder replaces redacted values.
+	Placeholder = "[REDACTED]"
+
+	// EnvRedactFields adds comma separated field name patterns to DefaultFieldPatterns.
+	EnvRedactFields = "REDACT_FIELDS"
+
+	// minValueLength avoids redacting every occurrence of very short values.
+	minValueLength = 4
+)
+
+// DefaultFieldPatterns are matched, ignoring case, '-' and '_', against the
+// field names of redacted values. A field is sensitive when its name
+// contains one of the patterns.
+var DefaultFieldPatterns = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"authorization",
+	"apikey",
+	"credential",
+	"cookie",
+	"privatekey",
+}
+
+// Redactor hides sensitive data: fields whose names match a pattern, fields
+// at given paths and known values, like resolved secrets, wherever they
+// appear inside strings.
+type Redactor struct {
+	fieldPatterns []string
+
+	mu     sync.RWMutex
+	values []string
+}
+
+// redactedError is returned by RedactError.
+type redactedError struct {
+	err     error
+	message string
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+func NewRedactor(fieldPatterns []string) *Redactor {
+	normalized := make([]string, 0, len(fieldPatterns))
+	for _, pattern := range fieldPatterns {
+		if pattern = normalizeField(pattern); pattern != "" {
+			normalized = append(normalized, pattern)
+		}
+	}
+
+	return &Redactor{
+		fieldPatterns: normalized,
+	}
+}
+
+// NewRedactorFromEnv uses DefaultFieldPatterns plus the patterns in REDACT_FIELDS.
+func NewRedactorFromEnv() *Redactor {
+	patterns := append([]string(nil), DefaultFieldPatterns...)
+
+	for _, pattern := range strings.Split(os.Getenv(EnvRedactFields), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	return NewRedactor(patterns)
+}
+
+// WithValues returns a copy of the redactor that also hides values.
+func (r *Redactor) WithValues(values ...string) *Redactor {
+	redactor := &Redactor{
+		fieldPatterns: r.fieldPatterns,
+		values:        r.knownValues(),
+	}
+	redactor.AddValues(values...)
+
+	return redactor
+}
+
+// AddValues registers values that are hidden wherever they appear.
+func (r *Redactor) AddValues(values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, value := range values {
+		if len(value) < minValueLength || containsString(r.values, value) {
+			continue
+		}
+
+		r.values = append(r.values, value)
+	}
+
+	// longer values first, so a value containing another is fully hidden
+	sort.Slice(r.values, func(i, j int) bool {
+		return len(r.values[i]) > len(r.values[j])
+	})
+}
+
+func (r *Redactor) knownValues() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string(nil), r.values...)
+}
+
+// IsSensitiveField reports whether the field name matches one of the patterns.
+func (r *Redactor) IsSensitiveField(name string) bool {
+	name = normalizeField(name)
+
+	for _, pattern := range r.fieldPatterns {
+		if strings.Contains(name, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactString hides the known values found in value.
+func (r *Redactor) RedactString(value string) string {
+	for _, known := range r.knownValues() {
+		value = strings.ReplaceAll(value, known, Placeholder)
+	}
+
+	return value
+}
+
+// RedactError returns err with the known values hidden from its message. The
+// returned error unwraps to err, so errors.Is and errors.As still match it.
+func (r *Redactor) RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	message := r.RedactString(err.Error())
+	if message == err.Error() {
+		return err
+	}
+
+	return &redactedError{err: err, message: message}
+}
+
+// Redact returns a redacted copy of value. Values that are not JSON like
+// (maps, slices, strings, numbers...) are converted through JSON first.
+// paths are dot separated field paths, where "*" matches any array item or
+// object field, as returned by SensitivePaths.
+func (r *Redactor) Redact(value any, paths ...string) any {
+	if value == nil {
+		return nil
+	}
+
+	splitPaths := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		if path != "" {
+			splitPaths = append(splitPaths, strings.Split(path, "."))
+		}
+	}
+
+	return r.redact(toJSONValue(value), splitPaths, r.knownValues())
+}
+
+func (r *Redactor) redact(value any, paths [][]string, values []string) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(typed))
+
+		for key, item := range typed {
+			childPaths, matched := advancePaths(paths, key)
+			if matched || r.IsSensitiveField(key) {
+				result[key] = Placeholder
+				continue
+			}
+
+			result[key] = r.redact(item, childPaths, values)
+		}
+
+		return result
+	case []any:
+		result := make([]any, len(typed))
+
+		for i, item := range typed {
+			childPaths, matched := advancePaths(paths, "")
+			if matched {
+				result[i] = Placeholder
+				continue
+			}
+
+			result[i] = r.redact(item, childPaths, values)
+		}
+
+		return result
+	case string:
+		for _, known := range values {
+			typed = strings.ReplaceAll(typed, known, Placeholder)
+		}
+
+		return typed
+	default:
+		return value
+	}
+}
+
+// advancePaths returns the paths that continue below key and whether a path
+// ends at key. An empty key is an array item and only matches "*".
+func advancePaths(paths [][]string, key string) (childPaths [][]string, matched bool) {
+	for _, path := range paths {
+		if path[0] != "*" && (key == "" || path[0] != key) {
+			continue
+		}
+
+		if len(path) == 1 {
+			matched = true
+			continue
+		}
+
+		childPaths = append(childPaths, path[1:])
+	}
+
+	return
+}
+
+func toJSONValue(value any) any {
+	switch value.(type) {
+	case map[string]any, []any, string, bool, float64, json.Number:
+		return value
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Placeholder
+	}
+
+	var result any
+	if err = json.Unmarshal(data, &result); err != nil {
+		return Placeholder
+	}
+
+	return result
+}
+
+func normalizeField(name string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,143 @@
+package yredact
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRedact(t *testing.T) {
+	suite.Run(t, new(RedactTestSuite))
+}
+
+type RedactTestSuite struct {
+	suite.Suite
+	redactor *Redactor
+}
+
+func (s *RedactTestSuite) SetupTest() {
+	s.redactor = NewRedactor(DefaultFieldPatterns)
+}
+
+func (s *RedactTestSuite) TestRedact_FieldPatterns() {
+	redacted := s.redactor.Redact(map[string]any{
+		"user": "john",
+		"headers": map[string]any{
+			"Authorization": "Bearer abc",
+			"Content-Type":  "application/json",
+		},
+		"Access-Token":  "abc",
+		"client_secret": "xyz",
+	})
+
+	s.Equal(map[string]any{
+		"user": "john",
+		"headers": map[string]any{
+			"Authorization": Placeholder,
+			"Content-Type":  "application/json",
+		},
+		"Access-Token":  Placeholder,
+		"client_secret": Placeholder,
+	}, redacted)
+}
+
+func (s *RedactTestSuite) TestRedact_Paths() {
+	redacted := s.redactor.Redact(map[string]any{
+		"items": []any{
+			map[string]any{"id": "1", "content": "a"},
+			map[string]any{"id": "2", "content": "b"},
+		},
+		"meta": map[string]any{"signature": "sig", "page": float64(1)},
+	}, "items.*.content", "meta.signature")
+
+	s.Equal(map[string]any{
+		"items": []any{
+			map[string]any{"id": "1", "content": Placeholder},
+			map[string]any{"id": "2", "content": Placeholder},
+		},
+		"meta": map[string]any{"signature": Placeholder, "page": float64(1)},
+	}, redacted)
+}
+
+func (s *RedactTestSuite) TestRedact_KnownValues() {
+	s.redactor.AddValues("s3cr3t-value", "abc")
+
+	redacted := s.redactor.Redact(map[string]any{
+		"url":  "https://api.example.com/?key=s3cr3t-value",
+		"list": []any{"s3cr3t-value", "abc"},
+	})
+
+	s.Equal(map[string]any{
+		"url":  "https://api.example.com/?key=" + Placeholder,
+		"list": []any{Placeholder, "abc"},
+	}, redacted)
+	s.Equal("failed with "+Placeholder, s.redactor.RedactString("failed with s3cr3t-value"))
+}
+
+func (s *RedactTestSuite) TestRedactError() {
+	s.redactor.AddValues("s3cr3t-value")
+
+	original := errors.New("request to https://api.example.com/?key=s3cr3t-value failed")
+	redacted := s.redactor.RedactError(fmt.Errorf("plugin failed: %w", original))
+
+	s.EqualError(redacted, "plugin failed: request to https://api.example.com/?key="+Placeholder+" failed")
+	s.ErrorIs(redacted, original)
+	s.Nil(s.redactor.RedactError(nil))
+
+	plain := errors.New("nothing to hide")
+	s.Same(plain, s.redactor.RedactError(plain))
+}
+
+func (s *RedactTestSuite) TestRedact_DoesNotChangeOriginal() {
+	type output struct {
+		Token string `json:"token"`
+		Name  string `json:"name"`
+	}
+
+	original := map[string]any{"password": "123456"}
+
+	s.Equal(map[string]any{"password": Placeholder}, s.redactor.Redact(original))
+	s.Equal("123456", original["password"])
+	s.Equal(map[string]any{"token": Placeholder, "name": "a"}, s.redactor.Redact(output{Token: "t", Name: "a"}))
+	s.Nil(s.redactor.Redact(nil))
+}
+
+func (s *RedactTestSuite) TestWithValues() {
+	child := s.redactor.WithValues("child-value")
+
+	s.Equal(Placeholder, child.RedactString("child-value"))
+	s.Equal("child-value", s.redactor.RedactString("child-value"))
+}
+
+func (s *RedactTestSuite) TestNewRedactorFromEnv() {
+	s.T().Setenv(EnvRedactFields, "ssn, card_number")
+
+	redactor := NewRedactorFromEnv()
+	s.True(redactor.IsSensitiveField("SSN"))
+	s.True(redactor.IsSensitiveField("cardNumber"))
+	s.True(redactor.IsSensitiveField("password"))
+	s.False(redactor.IsSensitiveField("name"))
+}
+
+func (s *RedactTestSuite) TestSensitivePaths() {
+	paths, err := SensitivePaths([]byte(`{
+		"type": "object",
+		"properties": {
+			"access_token": {"type": "string", "sensitive": true},
+			"files": {
+				"type": "array",
+				"items": {"type": "object", "properties": {"content": {"type": "string", "sensitive": true}}}
+			},
+			"labels": {"type": "object", "additionalProperties": {"type": "string", "sensitive": true}},
+			"name": {"type": "string"}
+		}
+	}`))
+
+	s.NoError(err)
+	s.Equal([]string{"access_token", "files.*.content", "labels.*"}, paths)
+
+	_, err = SensitivePaths([]byte(`{`))
+	s.Error(err)
+}
@@ -0,0 +1,81 @@
+package yredact
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// KeywordSensitive marks a property of a plugin JSON schema as sensitive:
+//
+//	"access_token": {"type": "string", "sensitive": true}
+const KeywordSensitive = "sensitive"
+
+// SensitivePaths returns the paths of the schema properties marked with
+// "sensitive": true, in the format accepted by Redactor.Redact.
+func SensitivePaths(schema []byte) ([]string, error) {
+	if len(schema) == 0 {
+		return nil, nil
+	}
+
+	var document any
+	if err := json.Unmarshal(schema, &document); err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool)
+	collectSensitivePaths(document, "", found)
+
+	paths := make([]string, 0, len(found))
+	for path := range found {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+func collectSensitivePaths(node any, path string, found map[string]bool) {
+	schema, ok := node.(map[string]any)
+	if !ok {
+		return
+	}
+
+	if sensitive, _ := schema[KeywordSensitive].(bool); sensitive && path != "" {
+		found[path] = true
+		return
+	}
+
+	if properties, ok := schema["properties"].(map[string]any); ok {
+		for name, property := range properties {
+			collectSensitivePaths(property, joinPath(path, name), found)
+		}
+	}
+
+	switch items := schema["items"].(type) {
+	case map[string]any:
+		collectSensitivePaths(items, joinPath(path, "*"), found)
+	case []any:
+		for _, item := range items {
+			collectSensitivePaths(item, joinPath(path, "*"), found)
+		}
+	}
+
+	collectSensitivePaths(schema["additionalProperties"], joinPath(path, "*"), found)
+
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		if schemas, ok := schema[keyword].([]any); ok {
+			for _, item := range schemas {
+				collectSensitivePaths(item, path, found)
+			}
+		}
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}